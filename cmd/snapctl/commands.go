@@ -47,9 +47,31 @@ var (
 						flTaskSchedStopTime,
 						flTaskName,
 						flTaskSchedDuration,
+						flTaskSchedSplay,
+						flTaskSchedAligned,
+						flTaskSchedAlignOffset,
+						flTaskSchedDays,
+						flTaskSchedDailyStart,
+						flTaskSchedDailyStop,
+						flTaskSchedTimezone,
+						flTaskSchedTriggeredBy,
+						flTaskSchedSuccessOnly,
+						flTaskSchedEvents,
+						flTaskSchedEventFilter,
+						flTaskSchedMinInterval,
 						flTaskSchedNoStart,
 						flTaskDeadline,
 						flTaskMaxFailures,
+						flTaskCollectPolicy,
+						flTaskPriority,
+						flTaskBackoff,
+						flTaskMaxBackoff,
+						flTaskDisableAfter,
+						flTaskCatchup,
+						flTaskMaxCatchupRuns,
+						flTaskOverlap,
+						flTaskMaxConcurrentRuns,
+						flTaskLatencySLO,
 					},
 				},
 				{
@@ -93,6 +115,51 @@ var (
 					Usage:  "enable <task_id>",
 					Action: enableTask,
 				},
+				{
+					Name:   "history",
+					Usage:  "history <task_id>",
+					Action: taskHistory,
+				},
+				{
+					Name:   "latency",
+					Usage:  "latency <task_id>",
+					Action: taskLatency,
+				},
+				{
+					Name:   "fire",
+					Usage:  "fire <task_id>",
+					Action: fireTask,
+					Flags: []cli.Flag{
+						flTaskFireForce,
+					},
+				},
+				{
+					Name:   "update",
+					Usage:  "update <task_id>",
+					Action: updateTask,
+					Flags: []cli.Flag{
+						flTaskManifest,
+						flWorkfowManifest,
+						flTaskSchedInterval,
+						flTaskSchedStartDate,
+						flTaskSchedStartTime,
+						flTaskSchedStopDate,
+						flTaskSchedStopTime,
+						flTaskSchedDuration,
+						flTaskSchedSplay,
+						flTaskSchedAligned,
+						flTaskSchedAlignOffset,
+						flTaskSchedDays,
+						flTaskSchedDailyStart,
+						flTaskSchedDailyStop,
+						flTaskSchedTimezone,
+						flTaskSchedTriggeredBy,
+						flTaskSchedSuccessOnly,
+						flTaskSchedEvents,
+						flTaskSchedEventFilter,
+						flTaskSchedMinInterval,
+					},
+				},
 			},
 		},
 		{
@@ -114,6 +114,58 @@ var (
 		Name:  "duration, d",
 		Usage: "The amount of time to run the task [appends to start or creates a start time before a stop]",
 	}
+	flTaskSchedSplay = cli.StringFlag{
+		Name:  "splay",
+		Usage: "Delay each fire of a simple or windowed schedule by a random duration up to this value, to spread the load of many nodes [ex: 500ms, 5s]",
+	}
+	flTaskSchedAligned = cli.BoolFlag{
+		Name:  "aligned",
+		Usage: "Fire a simple or windowed schedule at multiples of the interval since the epoch, rather than relative to the task start",
+	}
+	flTaskSchedAlignOffset = cli.StringFlag{
+		Name:  "align-offset",
+		Usage: "Shift the fires of an aligned schedule by this duration [ex: 5s]",
+	}
+	flTaskSchedDays = cli.StringFlag{
+		Name:  "days",
+		Usage: "Comma separated days of the week a windowed schedule recurs on [ex: mon,wed,fri or weekdays or weekends]",
+	}
+	flTaskSchedDailyStart = cli.StringFlag{
+		Name:  "daily-start",
+		Usage: "Time of day the window of a recurring windowed schedule opens at [ex: 08:00]",
+	}
+	flTaskSchedDailyStop = cli.StringFlag{
+		Name:  "daily-stop",
+		Usage: "Time of day the window of a recurring windowed schedule closes at [ex: 18:00]",
+	}
+	flTaskSchedTimezone = cli.StringFlag{
+		Name:  "timezone",
+		Usage: "Time zone of the window of a recurring windowed schedule [ex: Europe/Berlin, defaults to the local time zone of snapd]",
+	}
+	flTaskSchedTriggeredBy = cli.StringFlag{
+		Name:  "triggered-by",
+		Usage: "Fire the task each time the workflow of the task with this ID completes, instead of on an interval",
+	}
+	flTaskSchedSuccessOnly = cli.BoolFlag{
+		Name:  "success-only",
+		Usage: "Fire a triggered-by task only when the workflow of the triggering task succeeds",
+	}
+	flTaskSchedEvents = cli.StringFlag{
+		Name:  "events",
+		Usage: "Comma separated namespaces of the snapd events firing the task, instead of an interval [ex: Control.PluginLoaded,Control.PluginsSwapped or Control.*]",
+	}
+	flTaskSchedEventFilter = cli.StringFlag{
+		Name:  "event-filter",
+		Usage: "Expression the fields of the events firing the task must match [ex: 'Name == \"mock\" && Version != 1']",
+	}
+	flTaskSchedMinInterval = cli.StringFlag{
+		Name:  "min-interval",
+		Usage: "The shortest time between two fires of a task fired by events [defaults to 1s]",
+	}
+	flTaskFireForce = cli.BoolFlag{
+		Name:  "force, f",
+		Usage: "Fire the task even if it is not running",
+	}
 	flTaskSchedNoStart = cli.BoolFlag{
 		Name:  "no-start",
 		Usage: "Do not start task on creation [normally started on creation]",
@@ -126,6 +178,46 @@ var (
 		Name:  "max-failures",
 		Usage: "The number of consecutive failures before snap disables the task",
 	}
+	flTaskCollectPolicy = cli.StringFlag{
+		Name:  "collect-policy",
+		Usage: "How the task handles failing collector plugins, 'all-or-nothing' or 'best-effort' [defaults to all-or-nothing]",
+	}
+	flTaskBackoff = cli.BoolFlag{
+		Name:  "backoff",
+		Usage: "Back off when the task reaches max-failures, doubling its interval while it keeps failing, instead of disabling it",
+	}
+	flTaskMaxBackoff = cli.StringFlag{
+		Name:  "max-backoff",
+		Usage: "The factor the interval of a backing off task is stretched by at most [defaults to 32]",
+	}
+	flTaskDisableAfter = cli.StringFlag{
+		Name:  "disable-after",
+		Usage: "The number of consecutive failures before snap disables a backing off task [defaults to never]",
+	}
+	flTaskPriority = cli.StringFlag{
+		Name:  "priority",
+		Usage: "The order the jobs of the task are worked in when snapd is busy, 'low', 'normal', 'high' or 'critical' [defaults to normal]",
+	}
+	flTaskCatchup = cli.StringFlag{
+		Name:  "catchup",
+		Usage: "What the task does for the intervals of its schedule it missed, 'skip', 'run-once' or 'run-all' [defaults to skip]",
+	}
+	flTaskMaxCatchupRuns = cli.StringFlag{
+		Name:  "max-catchup-runs",
+		Usage: "The number of missed intervals a task with the 'run-all' catch-up policy runs its workflow for at most [defaults to 10]",
+	}
+	flTaskOverlap = cli.StringFlag{
+		Name:  "overlap",
+		Usage: "What the task does when its schedule fires while its workflow is still running, 'queue-one', 'skip' or 'concurrent' [defaults to queue-one]",
+	}
+	flTaskMaxConcurrentRuns = cli.StringFlag{
+		Name:  "max-concurrent-runs",
+		Usage: "The number of runs of a task with the 'concurrent' overlap policy in progress at a time at most [defaults to 4]",
+	}
+	flTaskLatencySLO = cli.StringFlag{
+		Name:  "latency-slo",
+		Usage: "The duration the runs of a task are expected to complete within, a run taking longer being counted as a breach of its latency objective (eg. 500ms) [disabled by default]",
+	}
 
 	// metric
 	flMetricVersion = cli.IntFlag{
@@ -26,6 +26,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -35,7 +36,9 @@ import (
 
 	"github.com/codegangsta/cli"
 	"github.com/ghodss/yaml"
+	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/mgmt/rest/client"
+	"github.com/intelsdi-x/snap/mgmt/rest/rbody"
 	"github.com/intelsdi-x/snap/scheduler/wmap"
 	"github.com/robfig/cron"
 	"golang.org/x/crypto/ssh/terminal"
@@ -47,6 +50,9 @@ var (
 	timeParseFormat  = "3:04PM"
 	dateParseFormat  = "1-02-2006"
 	unionParseFormat = timeParseFormat + " " + dateParseFormat
+	dailyTimeFormat  = "15:04"
+	// the flags which set the schedule of a task
+	scheduleFlags = []string{"interval", "start-date", "start-time", "stop-date", "stop-time", "duration", "splay", "aligned", "align-offset", "days", "daily-start", "daily-stop", "timezone", "triggered-by", "success-only", "events", "event-filter", "min-interval"}
 )
 
 // Constants used to truncate task hit and miss counts
@@ -78,12 +84,18 @@ func trunc(n int) string {
 }
 
 type task struct {
-	Version     int
-	Schedule    *client.Schedule
-	Workflow    *wmap.WorkflowMap
-	Name        string
-	Deadline    string
-	MaxFailures int `json:"max-failures"`
+	Version       int
+	Schedule      *client.Schedule
+	Workflow      *wmap.WorkflowMap
+	Name          string
+	Deadline      string
+	MaxFailures   int                 `json:"max-failures"`
+	CollectPolicy string              `json:"collect-policy"`
+	FailurePolicy *core.FailurePolicy `json:"failure-policy"`
+	Catchup       *core.CatchupPolicy `json:"catchup"`
+	Overlap       *core.OverlapPolicy `json:"overlap"`
+	Priority      string              `json:"priority"`
+	LatencySLO    string              `json:"latency-slo"`
 }
 
 func createTask(ctx *cli.Context) error {
@@ -206,6 +218,41 @@ func (t *task) setWindowedSchedule(start *time.Time, stop *time.Time, duration *
 
 // parse the command-line options and use them to setup a new schedule for this task
 func (t *task) setScheduleFromCliOptions(ctx *cli.Context) error {
+	// a 'triggered-by' schedule fires on the runs of another task, so it takes none of
+	// the interval and window options
+	triggeredBy := ctx.String("triggered-by")
+	if ctx.IsSet("triggered-by") || triggeredBy != "" {
+		if err := t.setScheduleType(ctx, "triggered-by", "triggered-by", "success-only"); err != nil {
+			return err
+		}
+		t.Schedule.TaskID = triggeredBy
+		t.Schedule.SuccessOnly = ctx.Bool("success-only")
+		return nil
+	}
+	// an 'event' schedule fires on the events of snapd, so it takes none of the interval
+	// and window options either
+	events := ctx.String("events")
+	if ctx.IsSet("events") || events != "" {
+		if err := t.setScheduleType(ctx, "event", "events", "event-filter", "min-interval"); err != nil {
+			return err
+		}
+		t.Schedule.Events = strings.Split(events, ",")
+		t.Schedule.Filter = ctx.String("event-filter")
+		minInterval := ctx.String("min-interval")
+		if ctx.IsSet("min-interval") || minInterval != "" {
+			if _, err := time.ParseDuration(minInterval); err != nil {
+				return fmt.Errorf("Usage error (bad min-interval format); %v", err)
+			}
+			t.Schedule.MinInterval = minInterval
+		}
+		return nil
+	}
+	if ctx.IsSet("success-only") {
+		return fmt.Errorf("Usage error; '--success-only' can only be used with '--triggered-by'")
+	}
+	if ctx.IsSet("event-filter") || ctx.IsSet("min-interval") {
+		return fmt.Errorf("Usage error; '--event-filter' and '--min-interval' can only be used with '--events'")
+	}
 	// check the start, stop, and duration values to see if we're looking at a windowed schedule (or not)
 	// first, get the parameters that define the windowed schedule
 	start := mergeDateTime(
@@ -233,9 +280,59 @@ func (t *task) setScheduleFromCliOptions(ctx *cli.Context) error {
 	if !ctx.IsSet("interval") && interval == "" && t.Schedule.Interval == "" {
 		return fmt.Errorf("Usage error (missing interval value); when constructing a new task schedule an interval must be provided")
 	}
-	// if a start, stop, or duration value was provided, or if the existing schedule for this task
-	// is 'windowed', then it's a 'windowed' schedule
-	isWindowed := (start != nil || stop != nil || duration != nil || t.Schedule.Type == "windowed")
+	// set the splay of the schedule (if one was provided)
+	splay := ctx.String("splay")
+	if ctx.IsSet("splay") || splay != "" {
+		if _, err := time.ParseDuration(splay); err != nil {
+			return fmt.Errorf("Usage error (bad splay format); %v", err)
+		}
+		t.Schedule.Splay = splay
+	}
+	// align the schedule to the wall clock (if requested)
+	if ctx.IsSet("aligned") || ctx.Bool("aligned") {
+		t.Schedule.Aligned = ctx.Bool("aligned")
+	}
+	alignOffset := ctx.String("align-offset")
+	if ctx.IsSet("align-offset") || alignOffset != "" {
+		if _, err := time.ParseDuration(alignOffset); err != nil {
+			return fmt.Errorf("Usage error (bad align-offset format); %v", err)
+		}
+		t.Schedule.AlignOffset = alignOffset
+	}
+	// set the recurring window of the schedule (if one was provided)
+	isRecurring := false
+	days := ctx.String("days")
+	if ctx.IsSet("days") || days != "" {
+		t.Schedule.Days = strings.Split(days, ",")
+		isRecurring = true
+	}
+	for _, f := range []struct {
+		flag  string
+		field *string
+	}{
+		{"daily-start", &t.Schedule.DailyStart},
+		{"daily-stop", &t.Schedule.DailyStop},
+	} {
+		val := ctx.String(f.flag)
+		if ctx.IsSet(f.flag) || val != "" {
+			if _, err := time.Parse(dailyTimeFormat, val); err != nil {
+				return fmt.Errorf("Usage error (bad %v format); %v", f.flag, err)
+			}
+			*f.field = val
+			isRecurring = true
+		}
+	}
+	timezone := ctx.String("timezone")
+	if ctx.IsSet("timezone") || timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return fmt.Errorf("Usage error (bad timezone); %v", err)
+		}
+		t.Schedule.Timezone = timezone
+		isRecurring = true
+	}
+	// if a start, stop, duration or recurring window value was provided, or if the existing
+	// schedule for this task is 'windowed', then it's a 'windowed' schedule
+	isWindowed := (start != nil || stop != nil || duration != nil || isRecurring || t.Schedule.Type == "windowed")
 	// if an interval was passed in, then attempt to parse it (first as a duration,
 	// then as the definition of a cron job)
 	isCron := false
@@ -286,6 +383,71 @@ func (t *task) setScheduleFromCliOptions(ctx *cli.Context) error {
 	return nil
 }
 
+// setScheduleType sets the type of a schedule which is not driven by an interval,
+// returning an error if any schedule option other than the given ones was provided
+// or the task has an existing schedule of another type
+func (t *task) setScheduleType(ctx *cli.Context, schedType string, flags ...string) error {
+	for _, flag := range scheduleFlags {
+		allowed := false
+		for _, f := range flags {
+			allowed = allowed || f == flag
+		}
+		if !allowed && ctx.IsSet(flag) {
+			return fmt.Errorf("Usage error; cannot use '%v' with a '%v' schedule", flag, schedType)
+		}
+	}
+	if t.Schedule.Type != "" && t.Schedule.Type != schedType {
+		return fmt.Errorf("Usage error; cannot replace existing schedule of type '%v' with a new, '%v' schedule", t.Schedule.Type, schedType)
+	}
+	t.Schedule.Type = schedType
+	return nil
+}
+
+// failurePolicy returns the failure policy of the task, adding one if the
+// task has none yet
+func (t *task) failurePolicy() *core.FailurePolicy {
+	if t.FailurePolicy == nil {
+		t.FailurePolicy = &core.FailurePolicy{}
+	}
+	return t.FailurePolicy
+}
+
+// catchupPolicy returns the catch-up policy of the task, adding one if the
+// task has none yet
+func (t *task) catchupPolicy() *core.CatchupPolicy {
+	if t.Catchup == nil {
+		t.Catchup = &core.CatchupPolicy{}
+	}
+	return t.Catchup
+}
+
+// overlapPolicy returns the overlap policy of the task, adding one if the
+// task has none yet
+func (t *task) overlapPolicy() *core.OverlapPolicy {
+	if t.Overlap == nil {
+		t.Overlap = &core.OverlapPolicy{}
+	}
+	return t.Overlap
+}
+
+// createOptions returns the optional fields of the task creation request
+func (t *task) createOptions() []client.TaskOp {
+	opts := []client.TaskOp{client.CollectPolicy(t.CollectPolicy), client.Priority(t.Priority)}
+	if t.FailurePolicy != nil {
+		opts = append(opts, client.FailurePolicy(*t.FailurePolicy))
+	}
+	if t.Catchup != nil {
+		opts = append(opts, client.Catchup(*t.Catchup))
+	}
+	if t.Overlap != nil {
+		opts = append(opts, client.Overlap(*t.Overlap))
+	}
+	if t.LatencySLO != "" {
+		opts = append(opts, client.LatencySLO(t.LatencySLO))
+	}
+	return opts
+}
+
 // merge the command-line options into the current task
 func (t *task) mergeCliOptions(ctx *cli.Context) error {
 	// set the name of the task (if a 'name' was provided in the CLI options)
@@ -298,6 +460,14 @@ func (t *task) mergeCliOptions(ctx *cli.Context) error {
 	if ctx.IsSet("deadline") || deadline != "" {
 		t.Deadline = deadline
 	}
+	// set the latency objective of the task (if a 'latency-slo' was provided in the CLI options)
+	latencySLO := ctx.String("latency-slo")
+	if ctx.IsSet("latency-slo") || latencySLO != "" {
+		if _, err := time.ParseDuration(latencySLO); err != nil {
+			return fmt.Errorf("Usage error (bad latency-slo value); %v", err)
+		}
+		t.LatencySLO = latencySLO
+	}
 	// set the MaxFailures for the task (if a 'max-failures' value was provided in the CLI options)
 	maxFailuresStrVal := ctx.String("max-failures")
 	if ctx.IsSet("max-failures") || maxFailuresStrVal != "" {
@@ -307,6 +477,83 @@ func (t *task) mergeCliOptions(ctx *cli.Context) error {
 		}
 		t.MaxFailures = maxFailures
 	}
+	// set the collect policy of the task (if a 'collect-policy' was provided in the CLI options)
+	collectPolicy := ctx.String("collect-policy")
+	if ctx.IsSet("collect-policy") || collectPolicy != "" {
+		t.CollectPolicy = collectPolicy
+	}
+	// set the priority of the task (if a 'priority' value was provided in the CLI options)
+	priority := ctx.String("priority")
+	if ctx.IsSet("priority") || priority != "" {
+		if _, err := core.ParseTaskPriority(priority); err != nil {
+			return fmt.Errorf("Usage error (bad priority value); %v", err)
+		}
+		t.Priority = priority
+	}
+	// set the failure policy of the task (if any of the 'backoff', 'max-backoff' or
+	// 'disable-after' values were provided in the CLI options)
+	if ctx.IsSet("backoff") || ctx.Bool("backoff") {
+		t.failurePolicy().Backoff = ctx.Bool("backoff")
+	}
+	maxBackoffStrVal := ctx.String("max-backoff")
+	if ctx.IsSet("max-backoff") || maxBackoffStrVal != "" {
+		maxBackoff, err := stringValToInt(maxBackoffStrVal)
+		if err != nil {
+			return err
+		}
+		if maxBackoff < 1 {
+			return fmt.Errorf("Usage error (bad max-backoff value); the max-backoff must be at least 1")
+		}
+		t.failurePolicy().MaxBackoff = uint(maxBackoff)
+	}
+	disableAfterStrVal := ctx.String("disable-after")
+	if ctx.IsSet("disable-after") || disableAfterStrVal != "" {
+		disableAfter, err := stringValToInt(disableAfterStrVal)
+		if err != nil {
+			return err
+		}
+		t.failurePolicy().DisableAfter = disableAfter
+	}
+	// set the catch-up policy of the task (if a 'catchup' or 'max-catchup-runs' value
+	// was provided in the CLI options)
+	catchup := ctx.String("catchup")
+	if ctx.IsSet("catchup") || catchup != "" {
+		t.catchupPolicy().Mode = catchup
+		if err := t.Catchup.Validate(); err != nil {
+			return fmt.Errorf("Usage error (bad catchup value); %v", err)
+		}
+	}
+	maxCatchupRunsStrVal := ctx.String("max-catchup-runs")
+	if ctx.IsSet("max-catchup-runs") || maxCatchupRunsStrVal != "" {
+		maxCatchupRuns, err := stringValToInt(maxCatchupRunsStrVal)
+		if err != nil {
+			return err
+		}
+		if maxCatchupRuns < 1 {
+			return fmt.Errorf("Usage error (bad max-catchup-runs value); the max-catchup-runs must be at least 1")
+		}
+		t.catchupPolicy().MaxRuns = uint(maxCatchupRuns)
+	}
+	// set the overlap policy of the task (if an 'overlap' or 'max-concurrent-runs' value
+	// was provided in the CLI options)
+	overlap := ctx.String("overlap")
+	if ctx.IsSet("overlap") || overlap != "" {
+		t.overlapPolicy().Mode = overlap
+		if err := t.Overlap.Validate(); err != nil {
+			return fmt.Errorf("Usage error (bad overlap value); %v", err)
+		}
+	}
+	maxConcurrentRunsStrVal := ctx.String("max-concurrent-runs")
+	if ctx.IsSet("max-concurrent-runs") || maxConcurrentRunsStrVal != "" {
+		maxConcurrentRuns, err := stringValToInt(maxConcurrentRunsStrVal)
+		if err != nil {
+			return err
+		}
+		if maxConcurrentRuns < 1 {
+			return fmt.Errorf("Usage error (bad max-concurrent-runs value); the max-concurrent-runs must be at least 1")
+		}
+		t.overlapPolicy().MaxConcurrent = uint(maxConcurrentRuns)
+	}
 	// set the schedule for the task from the CLI options (and return the results
 	// of that method call, indicating whether or not an error was encountered while
 	// setting up that schedule)
@@ -323,20 +570,9 @@ func createTaskUsingTaskManifest(ctx *cli.Context) error {
 	}
 
 	// create an empty task struct and unmarshal the contents of the file into that object
-	t := task{}
-	switch ext {
-	case ".yaml", ".yml":
-		e = yaml.Unmarshal(file, &t)
-		if e != nil {
-			return fmt.Errorf("Error parsing YAML file input - %v\n", e)
-		}
-	case ".json":
-		e = json.Unmarshal(file, &t)
-		if e != nil {
-			return fmt.Errorf("Error parsing JSON file input - %v\n", e)
-		}
-	default:
-		return fmt.Errorf("Unsupported file type %s\n", ext)
+	t, e := parseTaskManifest(file, ext)
+	if e != nil {
+		return e
 	}
 
 	// Validate task manifest includes schedule, workflow, and version
@@ -351,7 +587,7 @@ func createTaskUsingTaskManifest(ctx *cli.Context) error {
 	}
 
 	// and use the resulting struct to create a new task
-	r := pClient.CreateTask(t.Schedule, t.Workflow, t.Name, t.Deadline, !ctx.IsSet("no-start"), t.MaxFailures)
+	r := pClient.CreateTask(t.Schedule, t.Workflow, t.Name, t.Deadline, !ctx.IsSet("no-start"), t.MaxFailures, t.createOptions()...)
 
 	if r.Err != nil {
 		errors := strings.Split(r.Err.Error(), " -- ")
@@ -369,6 +605,25 @@ func createTaskUsingTaskManifest(ctx *cli.Context) error {
 	return nil
 }
 
+// parseTaskManifest unmarshals the contents of a task manifest file (either
+// JSON or YAML, depending on the extension of the file) into a task
+func parseTaskManifest(file []byte, ext string) (task, error) {
+	t := task{}
+	switch ext {
+	case ".yaml", ".yml":
+		if e := yaml.Unmarshal(file, &t); e != nil {
+			return t, fmt.Errorf("Error parsing YAML file input - %v\n", e)
+		}
+	case ".json":
+		if e := json.Unmarshal(file, &t); e != nil {
+			return t, fmt.Errorf("Error parsing JSON file input - %v\n", e)
+		}
+	default:
+		return t, fmt.Errorf("Unsupported file type %s\n", ext)
+	}
+	return t, nil
+}
+
 func createTaskUsingWFManifest(ctx *cli.Context) error {
 	// Get the workflow manifest filename from the command-line
 	path := ctx.String("workflow-manifest")
@@ -414,7 +669,7 @@ func createTaskUsingWFManifest(ctx *cli.Context) error {
 	}
 
 	// and use the resulting struct (along with the workflow map we constructed, above) to create a new task
-	r := pClient.CreateTask(t.Schedule, wf, t.Name, t.Deadline, !ctx.IsSet("no-start"), t.MaxFailures)
+	r := pClient.CreateTask(t.Schedule, wf, t.Name, t.Deadline, !ctx.IsSet("no-start"), t.MaxFailures, t.createOptions()...)
 	if r.Err != nil {
 		errors := strings.Split(r.Err.Error(), " -- ")
 		errString := "Error creating task:"
@@ -475,14 +730,17 @@ func listTask(ctx *cli.Context) error {
 		"STATE",
 		"HIT",
 		"MISS",
+		"BUSY",
 		"FAIL",
+		"OVERFLOW",
+		"WINDOW",
 		"CREATED",
 		"LAST FAILURE",
 	)
 	for _, task := range tasks.ScheduledTasks {
-		//165 is the width of the error message from ID - LAST FAILURE inclusive.
+		//210 is the width of the error message from ID - LAST FAILURE inclusive.
 		//If the header row wraps, then the error message will automatically wrap too
-		if termWidth < 165 {
+		if termWidth < 210 {
 			verbose = true
 		}
 		printFields(w, false, 0,
@@ -491,10 +749,13 @@ func listTask(ctx *cli.Context) error {
 			task.State,
 			trunc(task.HitCount),
 			trunc(task.MissCount),
+			trunc(task.BusySkipCount),
 			trunc(task.FailedCount),
+			trunc(task.OverflowCount),
+			fixSize(verbose, recurringWindow(task.Schedule), 30),
 			task.CreationTime().Format(unionParseFormat),
-			/*153 is the width of the error message from ID up to LAST FAILURE*/
-			fixSize(verbose, task.LastFailureMessage, termWidth-153),
+			/*198 is the width of the error message from ID up to LAST FAILURE*/
+			fixSize(verbose, task.LastFailureMessage, termWidth-198),
 		)
 	}
 	w.Flush()
@@ -502,6 +763,32 @@ func listTask(ctx *cli.Context) error {
 	return nil
 }
 
+// recurringWindow returns the description of the recurring window of a
+// schedule, such as "mon,tue 08:00-18:00 Europe/Berlin", or "-" if it has none
+func recurringWindow(s *core.Schedule) string {
+	if s == nil || (len(s.Days) == 0 && s.DailyStart == "" && s.DailyStop == "" && s.Timezone == "") {
+		return "-"
+	}
+	days := "daily"
+	if len(s.Days) > 0 {
+		days = strings.Join(s.Days, ",")
+	}
+	window := fmt.Sprintf("%s %s-%s", days, dailyTime(s.DailyStart), dailyTime(s.DailyStop))
+	if s.Timezone != "" {
+		window += " " + s.Timezone
+	}
+	return window
+}
+
+// dailyTime returns a time of day of a recurring window, which is midnight
+// if it is not set
+func dailyTime(t string) string {
+	if t == "" {
+		return "00:00"
+	}
+	return t
+}
+
 func fixSize(verbose bool, msg string, width int) string {
 	if len(msg) < width {
 		for i := len(msg); i < width; i++ {
@@ -700,6 +987,174 @@ func enableTask(ctx *cli.Context) error {
 	return nil
 }
 
+func taskHistory(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return newUsageError("Incorrect usage", ctx)
+	}
+	id := ctx.Args().First()
+	r := pClient.GetTaskHistory(id)
+	if r.Err != nil {
+		return fmt.Errorf("Error getting task history:\n%v\n", r.Err)
+	}
+	if len(r.Runs) == 0 {
+		fmt.Println("No runs recorded for this task.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', 0)
+	printFields(w, false, 0, "FIRED", "NODE", "DURATION", "METRICS", "ERRORS")
+	for _, run := range r.Runs {
+		printTaskRun(w, run)
+	}
+	w.Flush()
+	return nil
+}
+
+func taskLatency(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return newUsageError("Incorrect usage", ctx)
+	}
+	id := ctx.Args().First()
+	r := pClient.GetTaskLatencies(id)
+	if r.Err != nil {
+		return fmt.Errorf("Error getting task latencies:\n%v\n", r.Err)
+	}
+	if r.LatencySLO != "" {
+		fmt.Printf("Latency objective: %s (breached %d times)\n\n", r.LatencySLO, r.SLOBreachCount)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', 0)
+	printFields(w, false, 0, "NODE", "COUNT", "MEAN", "P50", "P90", "P99", "MAX")
+	printLatency(w, "task", r.Workflow)
+	for _, job := range r.Jobs {
+		node := job.Type
+		if job.PluginName != "" {
+			node = fmt.Sprintf("%s:%s:%d", job.Type, job.PluginName, job.PluginVersion)
+		}
+		printLatency(w, node, job.Latency)
+	}
+	w.Flush()
+	return nil
+}
+
+// printLatency prints the summary of a latency distribution
+func printLatency(w *tabwriter.Writer, node string, l rbody.LatencyHistogram) {
+	printFields(w, false, 0, node, l.Count, l.Mean, l.P50, l.P90, l.P99, l.Max)
+}
+
+// printTaskRun prints a run of a task, followed by a row for each of its jobs
+func printTaskRun(w *tabwriter.Writer, run rbody.TaskRun) {
+	status := "ok"
+	if run.Failed {
+		status = "failed"
+	}
+	if run.CatchUp {
+		status += " (catch-up)"
+	}
+	printFields(w, false, 0, run.FireTime.Format(timeFormat), "task", run.Duration, "", status)
+	for _, job := range run.Jobs {
+		node := job.Type
+		if job.PluginName != "" {
+			node = fmt.Sprintf("%s:%s:%d", job.Type, job.PluginName, job.PluginVersion)
+		}
+		printFields(w, false, 0, "", node, job.Duration, job.MetricCount, strings.Join(job.Errors, "; "))
+	}
+}
+
+func fireTask(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return newUsageError("Incorrect usage", ctx)
+	}
+	id := ctx.Args().First()
+	r := pClient.FireTask(id, ctx.Bool("force"))
+	if r.Err != nil {
+		return fmt.Errorf("Error firing task:\n%v\n", r.Err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', 0)
+	printFields(w, false, 0, "FIRED", "NODE", "DURATION", "METRICS", "ERRORS")
+	printTaskRun(w, r.Run)
+	w.Flush()
+	if r.Run.Failed {
+		return fmt.Errorf("Task fired with errors")
+	}
+	return nil
+}
+
+func updateTask(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return newUsageError("Incorrect usage", ctx)
+	}
+	id := ctx.Args().First()
+
+	// the schedule and workflow are taken from the task manifest (if one was
+	// provided) and a workflow manifest replaces the workflow of the task manifest
+	t := task{}
+	if ctx.IsSet("task-manifest") {
+		path := ctx.String("task-manifest")
+		ext := filepath.Ext(path)
+		file, e := ioutil.ReadFile(path)
+		if e != nil {
+			return fmt.Errorf("File error [%s] - %v\n", ext, e)
+		}
+		if t, e = parseTaskManifest(file, ext); e != nil {
+			return e
+		}
+	}
+	if ctx.IsSet("workflow-manifest") {
+		path := ctx.String("workflow-manifest")
+		ext := filepath.Ext(path)
+		file, e := ioutil.ReadFile(path)
+		if e != nil {
+			return fmt.Errorf("File error [%s] - %v\n", ext, e)
+		}
+		switch ext {
+		case ".yaml", ".yml":
+			t.Workflow, e = wmap.FromYaml(file)
+			if e != nil {
+				return fmt.Errorf("Error parsing YAML file input - %v\n", e)
+			}
+		case ".json":
+			t.Workflow, e = wmap.FromJson(file)
+			if e != nil {
+				return fmt.Errorf("Error parsing JSON file input - %v\n", e)
+			}
+		default:
+			return fmt.Errorf("Unsupported file type %s\n", ext)
+		}
+	}
+
+	// merge the schedule options specified on the command-line (if any) into
+	// the schedule of the task
+	for _, flag := range scheduleFlags {
+		if ctx.IsSet(flag) {
+			if t.Schedule == nil {
+				t.Schedule = &client.Schedule{}
+			}
+			if err := t.setScheduleFromCliOptions(ctx); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	if t.Schedule == nil && t.Workflow == nil {
+		return newUsageError("Must provide a new schedule and/or workflow, either with --task-manifest, --workflow-manifest or the schedule arguments", ctx)
+	}
+
+	r := pClient.UpdateTask(id, t.Schedule, t.Workflow)
+	if r.Err != nil {
+		errors := strings.Split(r.Err.Error(), " -- ")
+		errString := "Error updating task:"
+		for _, err := range errors {
+			errString += fmt.Sprintf("%v\n", err)
+		}
+		return fmt.Errorf(errString)
+	}
+	fmt.Println("Task updated:")
+	fmt.Printf("ID: %s\n", r.ID)
+	fmt.Printf("Name: %s\n", r.Name)
+	fmt.Printf("State: %s\n", r.State)
+	return nil
+}
+
 func sortTags(tags map[string]string) []string {
 	var tagSlice []string
 	var keys []string
@@ -734,7 +1189,7 @@ func validateScheduleExists(schedule *client.Schedule) error {
 	if schedule == nil {
 		return fmt.Errorf("Error: Task manifest did not include a schedule")
 	}
-	if *schedule == (client.Schedule{}) {
+	if reflect.DeepEqual(*schedule, client.Schedule{}) {
 		return fmt.Errorf("Error: Task manifest included an empty schedule. Task manifests need to include a schedule.")
 	}
 	return nil
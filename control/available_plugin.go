@@ -310,12 +310,22 @@ type availablePlugins struct {
 	// The Pools' primary keys are equal to
 	// {plugin_type}:{plugin_name}:{plugin_version}
 	table map[string]strategy.Pool
+	// latencies holds the histogram of the durations of the calls to the
+	// plugins of each pool, keyed like table
+	latencyMutex sync.Mutex
+	latencies    map[string]*core.LatencyHistogram
+	// deadlineExceeded counts the calls to the plugins of each pool cancelled
+	// because the deadline of their job passed, keyed like table. It is
+	// guarded by latencyMutex.
+	deadlineExceeded map[string]uint64
 }
 
 func newAvailablePlugins() *availablePlugins {
 	return &availablePlugins{
-		RWMutex: &sync.RWMutex{},
-		table:   make(map[string]strategy.Pool),
+		RWMutex:          &sync.RWMutex{},
+		table:            make(map[string]strategy.Pool),
+		latencies:        make(map[string]*core.LatencyHistogram),
+		deadlineExceeded: make(map[string]uint64),
 	}
 }
 
@@ -372,7 +382,7 @@ func (ap *availablePlugins) getPool(key string) (strategy.Pool, serror.SnapError
 	return pool, nil
 }
 
-func (ap *availablePlugins) collectMetrics(pluginKey string, metricTypes []core.Metric, taskID string) ([]core.Metric, error) {
+func (ap *availablePlugins) collectMetrics(pluginKey string, metricTypes []core.Metric, taskID string, deadline time.Time) ([]core.Metric, error) {
 	var results []core.Metric
 	pool, serr := ap.getPool(pluginKey)
 	if serr != nil {
@@ -414,7 +424,9 @@ func (ap *availablePlugins) collectMetrics(pluginKey string, metricTypes []core.
 	}
 
 	// collect metrics
-	metrics, err := cli.CollectMetrics(metricsToCollect)
+	start := time.Now()
+	metrics, err := cli.CollectMetrics(metricsToCollect, deadline)
+	ap.observeCall(pluginKey, time.Since(start), err)
 	if err != nil {
 		return nil, serror.New(err)
 	}
@@ -439,7 +451,7 @@ func (ap *availablePlugins) collectMetrics(pluginKey string, metricTypes []core.
 	return results, nil
 }
 
-func (ap *availablePlugins) publishMetrics(metrics []core.Metric, pluginName string, pluginVersion int, config map[string]ctypes.ConfigValue, taskID string) []error {
+func (ap *availablePlugins) publishMetrics(metrics []core.Metric, pluginName string, pluginVersion int, config map[string]ctypes.ConfigValue, taskID string, deadline time.Time) []error {
 	var errs []error
 	key := strings.Join([]string{plugin.PublisherPluginType.String(), pluginName, strconv.Itoa(pluginVersion)}, core.Separator)
 	pool, serr := ap.getPool(key)
@@ -465,7 +477,9 @@ func (ap *availablePlugins) publishMetrics(metrics []core.Metric, pluginName str
 		return []error{errors.New("unable to cast client to PluginPublisherClient")}
 	}
 
-	errp := cli.Publish(metrics, config)
+	start := time.Now()
+	errp := cli.Publish(metrics, config, deadline)
+	ap.observeCall(key, time.Since(start), errp)
 	if errp != nil {
 		return []error{errp}
 	}
@@ -474,7 +488,7 @@ func (ap *availablePlugins) publishMetrics(metrics []core.Metric, pluginName str
 	return nil
 }
 
-func (ap *availablePlugins) processMetrics(metrics []core.Metric, pluginName string, pluginVersion int, config map[string]ctypes.ConfigValue, taskID string) ([]core.Metric, []error) {
+func (ap *availablePlugins) processMetrics(metrics []core.Metric, pluginName string, pluginVersion int, config map[string]ctypes.ConfigValue, taskID string, deadline time.Time) ([]core.Metric, []error) {
 	var errs []error
 	key := strings.Join([]string{plugin.ProcessorPluginType.String(), pluginName, strconv.Itoa(pluginVersion)}, core.Separator)
 	pool, serr := ap.getPool(key)
@@ -499,7 +513,9 @@ func (ap *availablePlugins) processMetrics(metrics []core.Metric, pluginName str
 		return nil, []error{errors.New("unable to cast client to PluginProcessorClient")}
 	}
 
-	mts, errp := cli.Process(metrics, config)
+	start := time.Now()
+	mts, errp := cli.Process(metrics, config, deadline)
+	ap.observeCall(key, time.Since(start), errp)
 	if errp != nil {
 		return nil, []error{errp}
 	}
@@ -553,6 +569,73 @@ func (ap *availablePlugins) pools() map[string]strategy.Pool {
 	return ap.table
 }
 
+// observeCall records the duration of a call to a plugin of the pool with
+// the given key, and counts the call if it exceeded the deadline of its job
+func (ap *availablePlugins) observeCall(key string, d time.Duration, err error) {
+	ap.latencyMutex.Lock()
+	h, ok := ap.latencies[key]
+	if !ok {
+		h = core.NewLatencyHistogram()
+		ap.latencies[key] = h
+	}
+	if err == core.ErrDeadlineExceeded {
+		ap.deadlineExceeded[key]++
+	}
+	ap.latencyMutex.Unlock()
+	h.Observe(d)
+}
+
+// deadlineExceededCount returns the number of calls to the plugins of the
+// pool with the given key which exceeded the deadline of their job
+func (ap *availablePlugins) deadlineExceededCount(key string) uint64 {
+	ap.latencyMutex.Lock()
+	defer ap.latencyMutex.Unlock()
+	return ap.deadlineExceeded[key]
+}
+
+// latency returns the histogram of the durations of the calls to the plugins
+// of the pool with the given key
+func (ap *availablePlugins) latency(key string) core.LatencySnapshot {
+	ap.latencyMutex.Lock()
+	h, ok := ap.latencies[key]
+	ap.latencyMutex.Unlock()
+	if !ok {
+		return core.NewLatencyHistogram().Snapshot()
+	}
+	return h.Snapshot()
+}
+
+// poolStats returns the state of each pool
+func (ap *availablePlugins) poolStats() []core.PluginPoolStats {
+	ap.RLock()
+	defer ap.RUnlock()
+	stats := []core.PluginPoolStats{}
+	for key, pool := range ap.table {
+		tnv := strings.Split(key, core.Separator)
+		if len(tnv) != 3 {
+			continue
+		}
+		ps := core.PluginPoolStats{
+			TypeName:         tnv[0],
+			Name:             tnv[1],
+			Version:          pool.Version(),
+			Running:          pool.Count(),
+			Subscriptions:    pool.SubscriptionCount(),
+			Restarts:         pool.RestartCount(),
+			Latency:          ap.latency(key),
+			DeadlineExceeded: ap.deadlineExceededCount(key),
+		}
+		// the routing and caching strategy is only known once a plugin of
+		// the pool started
+		if pool.Strategy() != nil {
+			ps.CacheHits = pool.AllCacheHits()
+			ps.CacheMisses = pool.AllCacheMisses()
+		}
+		stats = append(stats, ps)
+	}
+	return stats
+}
+
 func (ap *availablePlugins) all() []strategy.AvailablePlugin {
 	var aps = []strategy.AvailablePlugin{}
 	ap.RLock()
@@ -79,6 +79,7 @@ type Config struct {
 	Plugins           *pluginConfig     `json:"plugins"yaml:"plugins"`
 	ListenAddr        string            `json:"listen_addr,omitempty"yaml:"listen_addr"`
 	ListenPort        int               `json:"listen_port,omitempty"yaml:"listen_port"`
+	Telemetry         bool              `json:"telemetry"yaml:"telemetry"`
 }
 
 const (
@@ -119,6 +120,9 @@ const (
 					},
 					"listen_port": {
 						"type": "integer"
+					},
+					"telemetry": {
+						"type": "boolean"
 					}
 				},
 				"additionalProperties": false
@@ -191,6 +195,10 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 			if err := json.Unmarshal(v, &(c.ListenPort)); err != nil {
 				return err
 			}
+		case "telemetry":
+			if err := json.Unmarshal(v, &(c.Telemetry)); err != nil {
+				return fmt.Errorf("%v (while parsing 'control::telemetry')", err)
+			}
 		default:
 			return fmt.Errorf("Unrecognized key '%v' in global config file while parsing 'control'", k)
 		}
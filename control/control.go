@@ -92,6 +92,8 @@ type pluginControl struct {
 	wg          sync.WaitGroup
 
 	subscriptionGroups ManagesSubscriptionGroups
+
+	telemetry *telemetryCollector
 }
 
 type subscribedPlugin struct {
@@ -225,6 +227,16 @@ func New(cfg *Config) *pluginControl {
 	c.pluginRunner.SetMetricCatalog(c.metricCatalog)
 	c.pluginRunner.SetPluginManager(c.pluginManager)
 
+	// Built-in telemetry collector, its metrics are only cataloged when
+	// it is enabled
+	c.telemetry = newTelemetryCollector(c.pluginRunner)
+	if cfg.Telemetry {
+		c.telemetry.addToCatalog(c.metricCatalog)
+		controlLogger.WithFields(log.Fields{
+			"_block": "new",
+		}).Debug("telemetry collector enabled")
+	}
+
 	// Pass runner events to control main module
 	c.eventManager.RegisterHandler(c.Name(), c)
 
@@ -740,6 +752,11 @@ func (p *pluginControl) getMetricsAndCollectors(requested []core.RequestedMetric
 					config:    config,
 				})
 
+				// the built-in telemetry collector runs within control, there
+				// is no plugin to subscribe to
+				if p.telemetry.isTelemetryPlugin(m.Plugin) {
+					continue
+				}
 				config = configTree.Get([]string{""})
 				if config == nil {
 					config = cdata.NewNode()
@@ -779,6 +796,18 @@ func (p *pluginControl) getMetricsAndCollectors(requested []core.RequestedMetric
 	return newMetrics, newPlugins, serrs
 }
 
+// SetTaskManager sets the scheduler the built-in telemetry collector gathers
+// the task and work queue metrics from
+func (p *pluginControl) SetTaskManager(s telemetryScheduler) {
+	p.telemetry.setScheduler(s)
+}
+
+// SetRequestCounter sets the REST API server the built-in telemetry collector
+// gathers the request metrics from
+func (p *pluginControl) SetRequestCounter(rc countsRequests) {
+	p.telemetry.setRequestCounter(rc)
+}
+
 // SetMonitorOptions exposes monitors options
 func (p *pluginControl) SetMonitorOptions(options ...monitorOption) {
 	p.pluginRunner.Monitor().Option(options...)
@@ -807,6 +836,11 @@ func (p *pluginControl) AvailablePlugins() []core.AvailablePlugin {
 	return caps
 }
 
+// PluginPoolStats returns the state of the pools of the running plugins
+func (p *pluginControl) PluginPoolStats() []core.PluginPoolStats {
+	return p.pluginRunner.AvailablePlugins().poolStats()
+}
+
 // MetricCatalog returns the entire metric catalog
 // NOTE: The returned data from this function should be considered constant and read only
 func (p *pluginControl) MetricCatalog() ([]core.CatalogedMetric, error) {
@@ -873,9 +907,14 @@ func (p *pluginControl) MetricExists(mns core.Namespace, ver int) bool {
 }
 
 // CollectMetrics is a blocking call to collector plugins returning a collection
-// of metrics and errors.  If an error is encountered no metrics will be
-// returned.
-func (p *pluginControl) CollectMetrics(id string, allTags map[string]map[string]string) (metrics []core.Metric, errs []error) {
+// of metrics and errors.  The metrics gathered by the plugins which succeeded
+// are returned along with the errors of the plugins which failed.  Errors
+// returned by a plugin are SnapErrors carrying the plugin-name and
+// plugin-version fields, so that callers can tell which plugin failed and
+// decide whether to use the partial result.  The calls to the plugins still
+// in progress once the deadline passed are cancelled, their errors being
+// core.ErrDeadlineExceeded.
+func (p *pluginControl) CollectMetrics(id string, allTags map[string]map[string]string, deadline time.Time) (metrics []core.Metric, errs []error) {
 	// If control is not started we don't want tasks to be able to
 	// go through a workflow.
 	if !p.Started {
@@ -934,14 +973,32 @@ func (p *pluginControl) CollectMetrics(id string, allTags map[string]map[string]
 
 		wg.Add(1)
 
-		go func(pluginKey string, mt []core.Metric) {
-			mts, err := p.pluginRunner.AvailablePlugins().collectMetrics(pluginKey, mt, id)
+		go func(pluginKey string, plugin *loadedPlugin, mt []core.Metric) {
+			var mts []core.Metric
+			var err error
+			if p.telemetry.isTelemetryPlugin(plugin) {
+				mts = p.telemetry.collect(mt)
+			} else {
+				mts, err = p.pluginRunner.AvailablePlugins().collectMetrics(pluginKey, mt, id, deadline)
+			}
 			if err != nil {
-				cError <- err
+				fields := map[string]interface{}{
+					"plugin-name":    plugin.Name(),
+					"plugin-version": plugin.Version(),
+				}
+				if serr, ok := err.(serror.SnapError); ok {
+					for k, v := range serr.Fields() {
+						fields[k] = v
+					}
+					serr.SetFields(fields)
+					cError <- serr
+				} else {
+					cError <- serror.New(err, fields)
+				}
 			} else {
 				cMetrics <- mts
 			}
-		}(pluginKey, pmt.metricTypes)
+		}(pluginKey, pmt.plugin, pmt.metricTypes)
 	}
 
 	go func() {
@@ -968,14 +1025,11 @@ func (p *pluginControl) CollectMetrics(id string, allTags map[string]map[string]
 	close(cMetrics)
 	close(cError)
 
-	if len(errs) > 0 {
-		return nil, errs
-	}
 	return
 }
 
 // PublishMetrics
-func (p *pluginControl) PublishMetrics(metrics []core.Metric, config map[string]ctypes.ConfigValue, taskID, pluginName string, pluginVersion int) []error {
+func (p *pluginControl) PublishMetrics(metrics []core.Metric, config map[string]ctypes.ConfigValue, taskID, pluginName string, pluginVersion int, deadline time.Time) []error {
 	// If control is not started we don't want tasks to be able to
 	// go through a workflow.
 	if !p.Started {
@@ -992,11 +1046,11 @@ func (p *pluginControl) PublishMetrics(metrics []core.Metric, config map[string]
 		merged[k] = v
 	}
 
-	return p.pluginRunner.AvailablePlugins().publishMetrics(metrics, pluginName, pluginVersion, merged, taskID)
+	return p.pluginRunner.AvailablePlugins().publishMetrics(metrics, pluginName, pluginVersion, merged, taskID, deadline)
 }
 
 // ProcessMetrics
-func (p *pluginControl) ProcessMetrics(metrics []core.Metric, config map[string]ctypes.ConfigValue, taskID, pluginName string, pluginVersion int) ([]core.Metric, []error) {
+func (p *pluginControl) ProcessMetrics(metrics []core.Metric, config map[string]ctypes.ConfigValue, taskID, pluginName string, pluginVersion int, deadline time.Time) ([]core.Metric, []error) {
 	// If control is not started we don't want tasks to be able to
 	// go through a workflow.
 	if !p.Started {
@@ -1013,7 +1067,7 @@ func (p *pluginControl) ProcessMetrics(metrics []core.Metric, config map[string]
 		merged[k] = v
 	}
 
-	return p.pluginRunner.AvailablePlugins().processMetrics(metrics, pluginName, pluginVersion, merged, taskID)
+	return p.pluginRunner.AvailablePlugins().processMetrics(metrics, pluginName, pluginVersion, merged, taskID, deadline)
 }
 
 func (p *pluginControl) SetAutodiscoverPaths(paths []string) {
@@ -22,6 +22,7 @@ package control
 import (
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/serror"
 	"github.com/intelsdi-x/snap/grpc/common"
 	"github.com/intelsdi-x/snap/grpc/controlproxy/rpc"
 	"golang.org/x/net/context"
@@ -32,24 +33,30 @@ type ControlGRPCServer struct {
 }
 
 // --------- Scheduler's managesMetrics implementation ----------
+// The deadline of the job a request is made for is carried by its context,
+// the zero time being returned by ctx.Deadline() when it has none.
 func (pc *ControlGRPCServer) PublishMetrics(ctx context.Context, r *rpc.PubProcMetricsRequest) (*rpc.ErrorReply, error) {
 	metrics := common.ToCoreMetrics(r.Metrics)
+	deadline, _ := ctx.Deadline()
 	errs := pc.control.PublishMetrics(
 		metrics,
 		common.ParseConfig(r.Config),
 		r.TaskId, r.PluginName,
-		int(r.PluginVersion))
+		int(r.PluginVersion),
+		deadline)
 
 	return &rpc.ErrorReply{Errors: errorsToStrings(errs)}, nil
 }
 
 func (pc *ControlGRPCServer) ProcessMetrics(ctx context.Context, r *rpc.PubProcMetricsRequest) (*rpc.ProcessMetricsReply, error) {
 	metrics := common.ToCoreMetrics(r.Metrics)
+	deadline, _ := ctx.Deadline()
 	mts, errs := pc.control.ProcessMetrics(
 		metrics,
 		common.ParseConfig(r.Config),
 		r.TaskId, r.PluginName,
-		int(r.PluginVersion))
+		int(r.PluginVersion),
+		deadline)
 
 	reply := &rpc.ProcessMetricsReply{
 		Metrics: common.NewMetrics(mts),
@@ -66,16 +73,17 @@ func (pc *ControlGRPCServer) CollectMetrics(ctx context.Context, r *rpc.CollectM
 			AllTags[k][entry.Key] = entry.Value
 		}
 	}
-	mts, errs := pc.control.CollectMetrics(r.TaskID, AllTags)
+	deadline, _ := ctx.Deadline()
+	mts, errs := pc.control.CollectMetrics(r.TaskID, AllTags, deadline)
 	var reply *rpc.CollectMetricsResponse
 	if mts == nil {
 		reply = &rpc.CollectMetricsResponse{
-			Errors: errorsToStrings(errs),
+			Errors: errorsToSnapErrors(errs),
 		}
 	} else {
 		reply = &rpc.CollectMetricsResponse{
 			Metrics: common.NewMetrics(mts),
-			Errors:  errorsToStrings(errs),
+			Errors:  errorsToSnapErrors(errs),
 		}
 	}
 	return reply, nil
@@ -122,6 +130,20 @@ func convertNSS(nss []core.Namespace) []*rpc.ArrString {
 	return res
 }
 
+// errorsToSnapErrors converts errors to SnapError protobuf messages, which
+// carry the fields of the errors, like the plugin which failed a collection
+func errorsToSnapErrors(in []error) []*common.SnapError {
+	serrs := make([]serror.SnapError, len(in))
+	for i, e := range in {
+		if serr, ok := e.(serror.SnapError); ok {
+			serrs[i] = serr
+		} else {
+			serrs[i] = serror.New(e)
+		}
+	}
+	return common.NewErrors(serrs)
+}
+
 func errorsToStrings(in []error) []string {
 	if len(in) == 0 {
 		return []string{}
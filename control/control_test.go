@@ -923,7 +923,7 @@ func TestRoutingCachingStrategy(t *testing.T) {
 			Convey("Collect metrics", func() {
 				taskID := tasks[rand.Intn(len(tasks))]
 				for i := 0; i < 10; i++ {
-					_, errs := c.CollectMetrics(taskID, nil)
+					_, errs := c.CollectMetrics(taskID, nil, time.Time{})
 					So(errs, ShouldBeEmpty)
 				}
 				Convey("Check cache stats", func() {
@@ -996,7 +996,7 @@ func TestRoutingCachingStrategy(t *testing.T) {
 			Convey("Collect metrics", func() {
 				taskID := tasks[rand.Intn(len(tasks))]
 				for i := 0; i < 10; i++ {
-					cr, errs := c.CollectMetrics(taskID, nil)
+					cr, errs := c.CollectMetrics(taskID, nil, time.Time{})
 					So(errs, ShouldBeEmpty)
 					for i := range cr {
 						So(cr[i].Data(), ShouldContainSubstring, "The mock collected data!")
@@ -1087,13 +1087,13 @@ func TestCollectDynamicMetrics(t *testing.T) {
 
 			// The minimum TTL advertised by the plugin is 100ms therefore the TTL for th			// pool should be the global cache expiration
 			So(ttl, ShouldEqual, strategy.GlobalCacheExpiration)
-			mts, errs := c.CollectMetrics(taskID, nil)
+			mts, errs := c.CollectMetrics(taskID, nil, time.Time{})
 			hits, err := pool.CacheHits(m.namespace.String(), 2, taskID)
 			So(err, ShouldBeNil)
 			So(hits, ShouldEqual, 0)
 			So(errs, ShouldBeNil)
 			So(len(mts), ShouldEqual, 10)
-			mts, errs = c.CollectMetrics(taskID, nil)
+			mts, errs = c.CollectMetrics(taskID, nil, time.Time{})
 			hits, err = pool.CacheHits(m.namespace.String(), 2, taskID)
 			So(err, ShouldBeNil)
 
@@ -1164,7 +1164,7 @@ func TestFailedPlugin(t *testing.T) {
 				var cr []core.Metric
 				eventMap := map[string]int{}
 				for i := 0; i < MaxPluginRestartCount+1; i++ {
-					cr, err = c.CollectMetrics(taskID, nil)
+					cr, err = c.CollectMetrics(taskID, nil, time.Time{})
 					So(err, ShouldNotBeNil)
 					So(cr, ShouldBeNil)
 					<-lpe.done
@@ -1256,7 +1256,7 @@ func TestCollectMetrics(t *testing.T) {
 
 			Convey("collect metrics", func() {
 				for x := 0; x < 4; x++ {
-					cr, err := c.CollectMetrics(taskHit, nil)
+					cr, err := c.CollectMetrics(taskHit, nil, time.Time{})
 					So(err, ShouldBeNil)
 					for i := range cr {
 						So(cr[i].Data(), ShouldContainSubstring, "The mock collected data!")
@@ -1315,7 +1315,7 @@ func TestPublishMetrics(t *testing.T) {
 				metrics := []core.Metric{
 					*plugin.NewMetricType(core.NewNamespace("foo"), time.Now(), nil, "", 1),
 				}
-				errs := c.PublishMetrics(metrics, n.Table(), uuid.New(), "mock-file", 3)
+				errs := c.PublishMetrics(metrics, n.Table(), uuid.New(), "mock-file", 3, time.Time{})
 				So(errs, ShouldBeNil)
 				ap := c.AvailablePlugins()
 				So(ap, ShouldNotBeEmpty)
@@ -1361,7 +1361,7 @@ func TestProcessMetrics(t *testing.T) {
 				metrics := []core.Metric{
 					*plugin.NewMetricType(core.NewNamespace("foo"), time.Now(), nil, "", 1),
 				}
-				mts, errs := c.ProcessMetrics(metrics, n.Table(), uuid.New(), "passthru", 1)
+				mts, errs := c.ProcessMetrics(metrics, n.Table(), uuid.New(), "passthru", 1, time.Time{})
 				So(errs, ShouldBeNil)
 				So(mts[0].Data(), ShouldEqual, 2)
 			})
@@ -1447,7 +1447,7 @@ func TestMetricSubscriptionToNewVersion(t *testing.T) {
 		<-lpe.started
 		So(serr, ShouldBeNil)
 		// collect metrics as a sanity check that everything is setup correctly
-		mts, errs := c.CollectMetrics("testTaskID", nil)
+		mts, errs := c.CollectMetrics("testTaskID", nil, time.Time{})
 		So(errs, ShouldBeNil)
 		So(len(mts), ShouldEqual, 1)
 		// ensure the data coming back is from v1. V1's data is type string
@@ -1473,7 +1473,7 @@ func TestMetricSubscriptionToNewVersion(t *testing.T) {
 			So(errp, ShouldBeNil)
 			So(pool2.SubscriptionCount(), ShouldEqual, 1)
 
-			mts, errs = c.CollectMetrics("testTaskID", nil)
+			mts, errs = c.CollectMetrics("testTaskID", nil, time.Time{})
 			So(len(mts), ShouldEqual, 1)
 
 			// ensure the data coming back is from v2, V2's data is type int
@@ -1506,7 +1506,7 @@ func TestMetricSubscriptionToOlderVersion(t *testing.T) {
 		<-lpe.started
 		So(serr, ShouldBeNil)
 		// collect metrics as a sanity check that everything is setup correctly
-		mts, errs := c.CollectMetrics("testTaskID", nil)
+		mts, errs := c.CollectMetrics("testTaskID", nil, time.Time{})
 		So(errs, ShouldBeNil)
 		So(len(mts), ShouldEqual, 1)
 		// ensure the data coming back is from v2. V2's data is type int
@@ -1548,7 +1548,7 @@ func TestMetricSubscriptionToOlderVersion(t *testing.T) {
 			So(errp, ShouldBeNil)
 			So(pool2.SubscriptionCount(), ShouldEqual, 1)
 
-			mts, errs := c.CollectMetrics("testTaskID", nil)
+			mts, errs := c.CollectMetrics("testTaskID", nil, time.Time{})
 			So(errs, ShouldBeEmpty)
 			So(len(mts), ShouldEqual, 1)
 
@@ -1587,7 +1587,7 @@ func TestDynamicMetricSubscriptionLoad(t *testing.T) {
 		<-lpe.sub  // wait for subscription event
 		So(serr, ShouldBeNil)
 		// collect metrics as a sanity check that everything is setup correctly
-		mts1, errs := c.CollectMetrics("testTaskID", nil)
+		mts1, errs := c.CollectMetrics("testTaskID", nil, time.Time{})
 		So(errs, ShouldBeNil)
 		So(len(mts1), ShouldBeGreaterThan, 1)
 		// ensure the data coming back is from v1. V1's data is type string
@@ -1608,7 +1608,7 @@ func TestDynamicMetricSubscriptionLoad(t *testing.T) {
 			So(errp, ShouldBeNil)
 			So(pool2.SubscriptionCount(), ShouldEqual, 1)
 
-			mts2, errs := c.CollectMetrics("testTaskID", nil)
+			mts2, errs := c.CollectMetrics("testTaskID", nil, time.Time{})
 			So(errs, ShouldBeNil)
 			So(len(mts2), ShouldBeGreaterThan, len(mts1))
 
@@ -1662,7 +1662,7 @@ func TestDynamicMetricSubscriptionUnload(t *testing.T) {
 		}
 		So(serr, ShouldBeNil)
 		// collect metrics as a sanity check that everything is setup correctly
-		mts1, errs := c.CollectMetrics("testTaskID", nil)
+		mts1, errs := c.CollectMetrics("testTaskID", nil, time.Time{})
 		So(errs, ShouldBeNil)
 		So(len(mts1), ShouldBeGreaterThan, 1)
 		Convey("Unloading mock plugin should remove its subscriptions", func() {
@@ -1677,7 +1677,7 @@ func TestDynamicMetricSubscriptionUnload(t *testing.T) {
 			pool2, errp := c.pluginRunner.AvailablePlugins().getOrCreatePool("collector" + core.Separator + "anothermock" + core.Separator + "1")
 			So(errp, ShouldBeNil)
 			So(pool2.SubscriptionCount(), ShouldEqual, 1)
-			mts2, errs := c.CollectMetrics("testTaskID", nil)
+			mts2, errs := c.CollectMetrics("testTaskID", nil, time.Time{})
 			So(errs, ShouldBeNil)
 			So(len(mts2), ShouldBeLessThan, len(mts1))
 
@@ -1724,7 +1724,7 @@ func TestDynamicMetricSubscriptionLoadLessMetrics(t *testing.T) {
 		So(err2, ShouldBeNil)
 		So(lpMock.Name(), ShouldResemble, "mock")
 		// collect metrics as a sanity check that everything is setup correctly
-		mts1, errs := c.CollectMetrics("testTaskID", nil)
+		mts1, errs := c.CollectMetrics("testTaskID", nil, time.Time{})
 		So(errs, ShouldBeNil)
 		So(len(mts1), ShouldBeGreaterThan, 1)
 		Convey("metrics are collected from mock1", func() {
@@ -1756,7 +1756,7 @@ func TestDynamicMetricSubscriptionLoadLessMetrics(t *testing.T) {
 			So(errp, ShouldBeNil)
 			So(pool2.SubscriptionCount(), ShouldEqual, 1)
 
-			mts2, errs := c.CollectMetrics("testTaskID", nil)
+			mts2, errs := c.CollectMetrics("testTaskID", nil, time.Time{})
 			So(errs, ShouldBeNil)
 			So(len(mts2), ShouldEqual, len(mts1))
 
@@ -1787,7 +1787,7 @@ func TestDynamicMetricSubscriptionLoadLessMetrics(t *testing.T) {
 				So(errp, ShouldBeNil)
 				So(pool2.SubscriptionCount(), ShouldEqual, 1)
 
-				mts3, errs := c.CollectMetrics("testTaskID", nil)
+				mts3, errs := c.CollectMetrics("testTaskID", nil, time.Time{})
 				So(errs, ShouldBeNil)
 				So(len(mts3), ShouldBeLessThan, len(mts2))
 
@@ -70,5 +70,11 @@ var (
 		EnvVar: "SNAP_CONTROL_LISTEN_ADDR",
 	}
 
-	Flags = []cli.Flag{flNumberOfPLs, flPluginLoadTimeout, flAutoDiscover, flPluginTrust, flKeyringPaths, flCache, flControlRpcPort, flControlRpcAddr}
+	flTelemetry = cli.BoolFlag{
+		Name:   "telemetry",
+		Usage:  "Expose the internals of snapd as metrics of the built-in snap-telemetry collector",
+		EnvVar: "SNAP_TELEMETRY",
+	}
+
+	Flags = []cli.Flag{flNumberOfPLs, flPluginLoadTimeout, flAutoDiscover, flPluginTrust, flKeyringPaths, flCache, flControlRpcPort, flControlRpcAddr, flTelemetry}
 )
@@ -20,6 +20,8 @@ limitations under the License.
 package client
 
 import (
+	"time"
+
 	"github.com/intelsdi-x/snap/control/plugin"
 	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
 	"github.com/intelsdi-x/snap/core"
@@ -35,20 +37,46 @@ type PluginClient interface {
 }
 
 // PluginCollectorClient A client providing collector specific plugin method calls.
+// The calls collecting, processing or publishing metrics take the deadline of
+// the job they are made for, a zero deadline meaning none. A call still in
+// progress once its deadline passed is cancelled and core.ErrDeadlineExceeded
+// returned.
 type PluginCollectorClient interface {
 	PluginClient
-	CollectMetrics([]core.Metric) ([]core.Metric, error)
+	CollectMetrics([]core.Metric, time.Time) ([]core.Metric, error)
 	GetMetricTypes(plugin.ConfigType) ([]core.Metric, error)
 }
 
 // PluginProcessorClient A client providing processor specific plugin method calls.
 type PluginProcessorClient interface {
 	PluginClient
-	Process([]core.Metric, map[string]ctypes.ConfigValue) ([]core.Metric, error)
+	Process([]core.Metric, map[string]ctypes.ConfigValue, time.Time) ([]core.Metric, error)
 }
 
 // PluginPublisherClient A client providing publishing specific plugin method calls.
 type PluginPublisherClient interface {
 	PluginClient
-	Publish([]core.Metric, map[string]ctypes.ConfigValue) error
+	Publish([]core.Metric, map[string]ctypes.ConfigValue, time.Time) error
+}
+
+// callTimeout returns the time a call made with the given timeout is given to
+// complete before the given deadline, the timeout if the deadline is zero or
+// further away. It is 0 or less once the deadline passed.
+func callTimeout(timeout time.Duration, deadline time.Time) time.Duration {
+	if deadline.IsZero() {
+		return timeout
+	}
+	if left := deadline.Sub(time.Now()); left < timeout {
+		return left
+	}
+	return timeout
+}
+
+// deadlineError returns core.ErrDeadlineExceeded in place of the error of a
+// call which failed once the given deadline passed
+func deadlineError(err error, deadline time.Time) error {
+	if err != nil && !deadline.IsZero() && !time.Now().Before(deadline) {
+		return core.ErrDeadlineExceeded
+	}
+	return err
 }
@@ -166,6 +166,13 @@ func getContext(timeout time.Duration) context.Context {
 	return ctxTimeout
 }
 
+// getDeadlineContext returns a context cancelled once the given timeout
+// elapsed or the given deadline passed, whichever comes first. The deadline
+// of the context is carried to the plugin.
+func getDeadlineContext(timeout time.Duration, deadline time.Time) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), callTimeout(timeout, deadline))
+}
+
 func (g *grpcClient) Ping() error {
 	_, err := g.plugin.Ping(getContext(g.timeout), &rpc.Empty{})
 	if err != nil {
@@ -188,27 +195,31 @@ func (g *grpcClient) Kill(reason string) error {
 	return nil
 }
 
-func (g *grpcClient) Publish(metrics []core.Metric, config map[string]ctypes.ConfigValue) error {
+func (g *grpcClient) Publish(metrics []core.Metric, config map[string]ctypes.ConfigValue, deadline time.Time) error {
 	arg := &rpc.PubProcArg{
 		Metrics: NewMetrics(metrics),
 		Config:  ToConfigMap(config),
 	}
-	_, err := g.publisher.Publish(getContext(g.timeout), arg)
+	ctx, cancel := getDeadlineContext(g.timeout, deadline)
+	defer cancel()
+	_, err := g.publisher.Publish(ctx, arg)
 	if err != nil {
-		return err
+		return deadlineError(err, deadline)
 	}
 	return nil
 }
 
-func (g *grpcClient) Process(metrics []core.Metric, config map[string]ctypes.ConfigValue) ([]core.Metric, error) {
+func (g *grpcClient) Process(metrics []core.Metric, config map[string]ctypes.ConfigValue, deadline time.Time) ([]core.Metric, error) {
 	arg := &rpc.PubProcArg{
 		Metrics: NewMetrics(metrics),
 		Config:  ToConfigMap(config),
 	}
-	reply, err := g.processor.Process(getContext(g.timeout), arg)
+	ctx, cancel := getDeadlineContext(g.timeout, deadline)
+	defer cancel()
+	reply, err := g.processor.Process(ctx, arg)
 
 	if err != nil {
-		return nil, err
+		return nil, deadlineError(err, deadline)
 	}
 	if reply.Error != "" {
 		return nil, errors.New(reply.Error)
@@ -220,14 +231,16 @@ func (g *grpcClient) Process(metrics []core.Metric, config map[string]ctypes.Con
 	return mts, nil
 }
 
-func (g *grpcClient) CollectMetrics(mts []core.Metric) ([]core.Metric, error) {
+func (g *grpcClient) CollectMetrics(mts []core.Metric, deadline time.Time) ([]core.Metric, error) {
 	arg := &rpc.MetricsArg{
 		Metrics: NewMetrics(mts),
 	}
-	reply, err := g.collector.CollectMetrics(getContext(g.timeout), arg)
+	ctx, cancel := getDeadlineContext(g.timeout, deadline)
+	defer cancel()
+	reply, err := g.collector.CollectMetrics(ctx, arg)
 
 	if err != nil {
-		return nil, err
+		return nil, deadlineError(err, deadline)
 	}
 
 	if reply.Error != "" {
@@ -141,7 +141,7 @@ func (h *httpJSONRPCClient) Kill(reason string) error {
 }
 
 // CollectMetrics returns collected metrics
-func (h *httpJSONRPCClient) CollectMetrics(mts []core.Metric) ([]core.Metric, error) {
+func (h *httpJSONRPCClient) CollectMetrics(mts []core.Metric, deadline time.Time) ([]core.Metric, error) {
 	var results []core.Metric
 	if len(mts) == 0 {
 		return nil, errors.New("no metrics to collect")
@@ -165,7 +165,7 @@ func (h *httpJSONRPCClient) CollectMetrics(mts []core.Metric) ([]core.Metric, er
 		return nil, err
 	}
 
-	res, err := h.call("Collector.CollectMetrics", []interface{}{out})
+	res, err := h.callWithDeadline("Collector.CollectMetrics", []interface{}{out}, deadline)
 	if err != nil {
 		return nil, err
 	}
@@ -241,11 +241,11 @@ func (h *httpJSONRPCClient) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) {
 	return cpr.Policy, nil
 }
 
-func (h *httpJSONRPCClient) Publish([]core.Metric, map[string]ctypes.ConfigValue) error {
+func (h *httpJSONRPCClient) Publish([]core.Metric, map[string]ctypes.ConfigValue, time.Time) error {
 	return errors.New("Not Implemented")
 }
 
-func (h *httpJSONRPCClient) Process([]core.Metric, map[string]ctypes.ConfigValue) ([]core.Metric, error) {
+func (h *httpJSONRPCClient) Process([]core.Metric, map[string]ctypes.ConfigValue, time.Time) ([]core.Metric, error) {
 	return nil, errors.New("Not Implemented")
 }
 
@@ -260,6 +260,17 @@ type jsonRpcResp struct {
 }
 
 func (h *httpJSONRPCClient) call(method string, args []interface{}) (*jsonRpcResp, error) {
+	return h.callWithDeadline(method, args, time.Time{})
+}
+
+// callWithDeadline calls a method of the plugin, the request being cancelled
+// once the timeout of the client elapsed or the given deadline passed,
+// whichever comes first
+func (h *httpJSONRPCClient) callWithDeadline(method string, args []interface{}, deadline time.Time) (*jsonRpcResp, error) {
+	timeout := callTimeout(h.timeout, deadline)
+	if timeout <= 0 {
+		return nil, core.ErrDeadlineExceeded
+	}
 	data, err := json.Marshal(map[string]interface{}{
 		"method": method,
 		"id":     h.id,
@@ -276,7 +287,7 @@ func (h *httpJSONRPCClient) call(method string, args []interface{}) (*jsonRpcRes
 		}).Error("error encoding request to json")
 		return nil, err
 	}
-	client := http.Client{Timeout: h.timeout}
+	client := http.Client{Timeout: timeout}
 	resp, err := client.Post(h.url, "application/json", bytes.NewReader(data))
 	if err != nil {
 		logger.WithFields(log.Fields{
@@ -285,7 +296,7 @@ func (h *httpJSONRPCClient) call(method string, args []interface{}) (*jsonRpcRes
 			"request": string(data),
 			"error":   err,
 		}).Error("error posting request to plugin")
-		return nil, err
+		return nil, deadlineError(err, deadline)
 	}
 	defer resp.Body.Close()
 	result := &jsonRpcResp{}
@@ -299,7 +310,7 @@ func (h *httpJSONRPCClient) call(method string, args []interface{}) (*jsonRpcRes
 			"response":    string(bs),
 			"error":       err,
 		}).Error("error decoding result")
-		return nil, err
+		return nil, deadlineError(err, deadline)
 	}
 	atomic.AddUint64(&h.id, 1)
 	if result.Error != "" {
@@ -219,7 +219,7 @@ func TestHTTPJSONRPC(t *testing.T) {
 					Namespace_: core.NewNamespace("foo", "bar"),
 					Config_:    cdn,
 				},
-			})
+			}, time.Time{})
 			So(err, ShouldBeNil)
 			So(mts, ShouldNotBeNil)
 			So(mts, ShouldHaveSameTypeAs, []core.Metric{})
@@ -242,6 +242,16 @@ func TestHTTPJSONRPC(t *testing.T) {
 			})
 		})
 
+		Convey("CollectMetrics past the deadline of its job", func() {
+			mts, err := c.CollectMetrics([]core.Metric{
+				&plugin.MetricType{
+					Namespace_: core.NewNamespace("foo", "bar"),
+				},
+			}, time.Now().Add(-time.Second))
+			So(err, ShouldEqual, core.ErrDeadlineExceeded)
+			So(mts, ShouldBeNil)
+		})
+
 		Convey("CollectMetrics provided an invalid config", func() {
 			cdn := cdata.NewNode()
 			cdn.AddItem("someInt", ctypes.ConfigValueInt{Value: 1})
@@ -252,7 +262,7 @@ func TestHTTPJSONRPC(t *testing.T) {
 					Namespace_: core.NewNamespace("foo", "bar"),
 					Config_:    cdn,
 				},
-			})
+			}, time.Time{})
 			So(err, ShouldBeNil)
 			So(mts, ShouldNotBeNil)
 			So(mts, ShouldHaveSameTypeAs, []core.Metric{})
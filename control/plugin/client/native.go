@@ -25,8 +25,10 @@ import (
 	"encoding/gob"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/rpc"
+	"sync"
 	"time"
 	"unicode"
 
@@ -48,7 +50,11 @@ type CallsRPC interface {
 
 // Native clients use golang net/rpc for communication to a native rpc server.
 type PluginNativeClient struct {
+	// connMutex protects connection, which is reset to nil when a call
+	// passes its deadline and dialed again at address by the next call
+	connMutex  sync.Mutex
 	connection CallsRPC
+	address    string
 	pluginType plugin.PluginType
 	encoder    encoding.Encoder
 	encrypter  *encrypter.Encrypter
@@ -67,9 +73,47 @@ func NewProcessorNativeClient(address string, timeout time.Duration, pub *rsa.Pu
 	return newNativeClient(address, timeout, plugin.ProcessorPluginType, pub, secure)
 }
 
+// conn returns the connection to the plugin, dialing it again if it was reset
+func (p *PluginNativeClient) conn() (CallsRPC, error) {
+	p.connMutex.Lock()
+	defer p.connMutex.Unlock()
+	if p.connection == nil {
+		c, err := net.DialTimeout("tcp", p.address, p.timeout)
+		if err != nil {
+			return nil, err
+		}
+		p.connection = rpc.NewClient(c)
+	}
+	return p.connection, nil
+}
+
+// resetConn closes the given connection to the plugin, so the calls in
+// progress on it return, and has the next call dial the plugin again.
+func (p *PluginNativeClient) resetConn(c CallsRPC) {
+	closer, ok := c.(io.Closer)
+	if !ok {
+		return
+	}
+	p.connMutex.Lock()
+	if p.connection == c {
+		p.connection = nil
+	}
+	p.connMutex.Unlock()
+	closer.Close()
+}
+
+// callRPC calls a method of the plugin on its connection
+func (p *PluginNativeClient) callRPC(method string, args interface{}, reply interface{}) error {
+	c, err := p.conn()
+	if err != nil {
+		return err
+	}
+	return c.Call(method, args, reply)
+}
+
 func (p *PluginNativeClient) Ping() error {
 	var reply []byte
-	err := p.connection.Call("SessionState.Ping", []byte{}, &reply)
+	err := p.callRPC("SessionState.Ping", []byte{}, &reply)
 	return err
 }
 
@@ -78,7 +122,7 @@ func (p *PluginNativeClient) SetKey() error {
 	if err != nil {
 		return err
 	}
-	return p.connection.Call("SessionState.SetKey", plugin.SetKeyArgs{
+	return p.callRPC("SessionState.SetKey", plugin.SetKeyArgs{
 		Key: out,
 	}, &[]byte{})
 }
@@ -91,7 +135,7 @@ func (p *PluginNativeClient) Kill(reason string) error {
 	}
 
 	var reply []byte
-	err = p.connection.Call("SessionState.Kill", out, &reply)
+	err = p.callRPC("SessionState.Kill", out, &reply)
 	return err
 }
 
@@ -150,7 +194,44 @@ func enforceTimeout(p *PluginNativeClient, dl time.Duration, done chan int) {
 	}
 }
 
-func (p *PluginNativeClient) Publish(metrics []core.Metric, config map[string]ctypes.ConfigValue) error {
+// call calls a method of the plugin, which is killed if it does not reply
+// within the timeout of the client. net/rpc calls cannot be interrupted, so
+// the connection of a call still in progress once the given deadline passed
+// is reset instead, which also fails the other calls in progress on it and
+// releases the call from the timeout.
+func (p *PluginNativeClient) call(method string, args []byte, reply *[]byte, deadline time.Time) error {
+	if callTimeout(p.timeout, deadline) <= 0 {
+		return core.ErrDeadlineExceeded
+	}
+	c, err := p.conn()
+	if err != nil {
+		return err
+	}
+	done := make(chan int)
+	go enforceTimeout(p, p.timeout, done)
+	errc := make(chan error, 1)
+	var r []byte
+	go func() {
+		errc <- c.Call(method, args, &r)
+		close(done)
+	}()
+	var expired <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(deadline.Sub(time.Now()))
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case err := <-errc:
+		*reply = r
+		return deadlineError(err, deadline)
+	case <-expired:
+		p.resetConn(c)
+		return core.ErrDeadlineExceeded
+	}
+}
+
+func (p *PluginNativeClient) Publish(metrics []core.Metric, config map[string]ctypes.ConfigValue, deadline time.Time) error {
 
 	args := plugin.PublishArgs{
 		ContentType: plugin.SnapGOBContentType,
@@ -163,14 +244,10 @@ func (p *PluginNativeClient) Publish(metrics []core.Metric, config map[string]ct
 		return err
 	}
 	var reply []byte
-	done := make(chan int)
-	go enforceTimeout(p, p.timeout, done)
-	err = p.connection.Call("Publisher.Publish", out, &reply)
-	close(done)
-	return err
+	return p.call("Publisher.Publish", out, &reply, deadline)
 }
 
-func (p *PluginNativeClient) Process(metrics []core.Metric, config map[string]ctypes.ConfigValue) ([]core.Metric, error) {
+func (p *PluginNativeClient) Process(metrics []core.Metric, config map[string]ctypes.ConfigValue, deadline time.Time) ([]core.Metric, error) {
 
 	args := plugin.ProcessorArgs{
 		ContentType: plugin.SnapGOBContentType,
@@ -184,10 +261,7 @@ func (p *PluginNativeClient) Process(metrics []core.Metric, config map[string]ct
 	}
 
 	var reply []byte
-	done := make(chan int)
-	go enforceTimeout(p, p.timeout, done)
-	err = p.connection.Call("Processor.Process", out, &reply)
-	close(done)
+	err = p.call("Processor.Process", out, &reply, deadline)
 	if err != nil {
 		return nil, err
 	}
@@ -205,7 +279,7 @@ func (p *PluginNativeClient) Process(metrics []core.Metric, config map[string]ct
 
 }
 
-func (p *PluginNativeClient) CollectMetrics(mts []core.Metric) ([]core.Metric, error) {
+func (p *PluginNativeClient) CollectMetrics(mts []core.Metric, deadline time.Time) ([]core.Metric, error) {
 	// Convert core.MetricType slice into plugin.nMetricType slice as we have
 	// to send structs over RPC
 	var results []core.Metric
@@ -231,10 +305,7 @@ func (p *PluginNativeClient) CollectMetrics(mts []core.Metric) ([]core.Metric, e
 	}
 
 	var reply []byte
-	done := make(chan int)
-	go enforceTimeout(p, p.timeout, done)
-	err = p.connection.Call("Collector.CollectMetrics", out, &reply)
-	close(done)
+	err = p.call("Collector.CollectMetrics", out, &reply, deadline)
 	if err != nil {
 		return nil, err
 	}
@@ -266,7 +337,7 @@ func (p *PluginNativeClient) GetMetricTypes(config plugin.ConfigType) ([]core.Me
 		return nil, err
 	}
 
-	err = p.connection.Call("Collector.GetMetricTypes", out, &reply)
+	err = p.callRPC("Collector.GetMetricTypes", out, &reply)
 	if err != nil {
 		return nil, err
 	}
@@ -288,7 +359,7 @@ func (p *PluginNativeClient) GetMetricTypes(config plugin.ConfigType) ([]core.Me
 
 func (p *PluginNativeClient) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) {
 	var reply []byte
-	err := p.connection.Call("SessionState.GetConfigPolicy", []byte{}, &reply)
+	err := p.callRPC("SessionState.GetConfigPolicy", []byte{}, &reply)
 	if err != nil {
 		return nil, err
 	}
@@ -318,6 +389,7 @@ func newNativeClient(address string, timeout time.Duration, t plugin.PluginType,
 	r := rpc.NewClient(conn)
 	p := &PluginNativeClient{
 		connection: r,
+		address:    address,
 		pluginType: t,
 		timeout:    timeout,
 	}
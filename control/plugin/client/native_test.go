@@ -0,0 +1,117 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"net/rpc"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/encoding"
+	"github.com/intelsdi-x/snap/core"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// slowRPC replies to the calls to collect metrics after a delay, unless it is
+// closed first
+type slowRPC struct {
+	delay  time.Duration
+	e      encoding.Encoder
+	closed chan struct{}
+	// returned receives the error of each call to collect metrics
+	returned chan error
+}
+
+func newSlowRPC(delay time.Duration, e encoding.Encoder) *slowRPC {
+	return &slowRPC{
+		delay:    delay,
+		e:        e,
+		closed:   make(chan struct{}),
+		returned: make(chan error, 1),
+	}
+}
+
+func (s *slowRPC) Close() error {
+	close(s.closed)
+	return nil
+}
+
+func (s *slowRPC) Call(method string, args interface{}, reply interface{}) (err error) {
+	if method != "Collector.CollectMetrics" {
+		return nil
+	}
+	defer func() { s.returned <- err }()
+	select {
+	case <-time.After(s.delay):
+	case <-s.closed:
+		return rpc.ErrShutdown
+	}
+	out, err := s.e.Encode(plugin.CollectMetricsReply{PluginMetrics: []plugin.MetricType{
+		*plugin.NewMetricType(core.NewNamespace("foo", "bar"), time.Now(), nil, "", 1),
+	}})
+	if err != nil {
+		return err
+	}
+	*reply.(*[]byte) = out
+	return nil
+}
+
+func TestNativeDeadline(t *testing.T) {
+	Convey("Native client", t, func() {
+		e := encoding.NewGobEncoder()
+		conn := newSlowRPC(200*time.Millisecond, e)
+		c := &PluginNativeClient{
+			connection: conn,
+			pluginType: plugin.CollectorPluginType,
+			encoder:    e,
+			timeout:    time.Second,
+		}
+		mts := []core.Metric{&plugin.MetricType{Namespace_: core.NewNamespace("foo", "bar")}}
+
+		Convey("returns the reply of a call completing before its deadline", func() {
+			res, err := c.CollectMetrics(mts, time.Now().Add(time.Second))
+			So(err, ShouldBeNil)
+			So(len(res), ShouldEqual, 1)
+		})
+
+		Convey("returns the reply of a call without a deadline", func() {
+			res, err := c.CollectMetrics(mts, time.Time{})
+			So(err, ShouldBeNil)
+			So(len(res), ShouldEqual, 1)
+		})
+
+		Convey("resets the connection of a call still in progress at its deadline", func() {
+			start := time.Now()
+			res, err := c.CollectMetrics(mts, start.Add(50*time.Millisecond))
+			So(err, ShouldEqual, core.ErrDeadlineExceeded)
+			So(res, ShouldBeNil)
+			So(time.Since(start), ShouldBeLessThan, 200*time.Millisecond)
+			So(<-conn.returned, ShouldEqual, rpc.ErrShutdown)
+			So(c.connection, ShouldBeNil)
+		})
+
+		Convey("does not make a call past its deadline", func() {
+			res, err := c.CollectMetrics(mts, time.Now().Add(-time.Second))
+			So(err, ShouldEqual, core.ErrDeadlineExceeded)
+			So(res, ShouldBeNil)
+		})
+	})
+}
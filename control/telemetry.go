@@ -0,0 +1,276 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+)
+
+const (
+	// TelemetryPluginName is the name of the built-in collector exposing the
+	// internals of snapd as metrics
+	TelemetryPluginName = "snap-telemetry"
+	// TelemetryPluginVersion is the version of the built-in collector
+	TelemetryPluginVersion = 1
+)
+
+// telemetryQueues are the names of the queues of the scheduler work manager
+var telemetryQueues = []string{"collect", "process", "publish"}
+
+// telemetryScheduler is implemented by the scheduler, which the built-in
+// collector gathers the task and work queue metrics from.
+type telemetryScheduler interface {
+	GetTasks() map[string]core.Task
+	QueueStats() map[string]core.WorkQueueStats
+}
+
+// countsRequests is implemented by the REST API server, which the built-in
+// collector gathers the request metrics from.
+type countsRequests interface {
+	RequestCounts() (requests, errors uint64)
+}
+
+// telemetryMetric is a metric advertised by the built-in collector
+type telemetryMetric struct {
+	namespace   core.Namespace
+	description string
+}
+
+// telemetryCollector is the collector built into snapd. It runs within
+// control, so the metrics it advertises are collected without a plugin
+// being subscribed to or started.
+type telemetryCollector struct {
+	sync.RWMutex
+	plugin    *loadedPlugin
+	runner    runsPlugins
+	scheduler telemetryScheduler
+	requests  countsRequests
+}
+
+func newTelemetryCollector(runner runsPlugins) *telemetryCollector {
+	return &telemetryCollector{
+		plugin: &loadedPlugin{
+			Meta: plugin.PluginMeta{
+				Name:    TelemetryPluginName,
+				Version: TelemetryPluginVersion,
+				Type:    plugin.CollectorPluginType,
+			},
+			Details:      &pluginDetails{},
+			Type:         plugin.CollectorPluginType,
+			State:        LoadedState,
+			LoadedTime:   time.Now(),
+			ConfigPolicy: cpolicy.New(),
+		},
+		runner: runner,
+	}
+}
+
+// metrics returns the metrics the collector advertises
+func (t *telemetryCollector) metrics() []telemetryMetric {
+	mts := []telemetryMetric{
+		{namespace: poolNamespace("running"), description: "running instances of the plugin"},
+		{namespace: poolNamespace("subscriptions"), description: "tasks subscribed to the plugin"},
+		{namespace: poolNamespace("restarts"), description: "times the plugin was restarted after it died"},
+		{namespace: poolNamespace("cache_hits"), description: "metrics of the plugin served from the cache"},
+		{namespace: poolNamespace("cache_misses"), description: "metrics of the plugin missing from the cache"},
+	}
+	for _, q := range telemetryQueues {
+		mts = append(mts,
+			telemetryMetric{namespace: queueNamespace(q, "depth"), description: "jobs waiting in the " + q + " queue"},
+			telemetryMetric{namespace: queueNamespace(q, "limit"), description: "jobs the " + q + " queue holds at most"},
+			telemetryMetric{namespace: queueNamespace(q, "workers"), description: "workers working the " + q + " queue"},
+			telemetryMetric{namespace: queueNamespace(q, "busy_workers"), description: "workers of the " + q + " queue running a job"},
+		)
+	}
+	return append(mts,
+		telemetryMetric{namespace: taskNamespace("hit_count"), description: "times the task ran"},
+		telemetryMetric{namespace: taskNamespace("miss_count"), description: "intervals the task missed"},
+		telemetryMetric{namespace: taskNamespace("fail_count"), description: "runs of the task which failed"},
+		telemetryMetric{namespace: core.NewNamespace("intel", "snap", "rest", "requests"), description: "requests served by the REST API"},
+		telemetryMetric{namespace: core.NewNamespace("intel", "snap", "rest", "errors"), description: "requests answered by the REST API with an error"},
+	)
+}
+
+// addToCatalog adds the metrics of the collector to the metric catalog
+func (t *telemetryCollector) addToCatalog(mc catalogsMetrics) {
+	for _, m := range t.metrics() {
+		mt := &plugin.MetricType{
+			Namespace_:          m.namespace,
+			Version_:            TelemetryPluginVersion,
+			LastAdvertisedTime_: t.plugin.LoadedTime,
+			Description_:        m.description,
+		}
+		if err := mc.AddLoadedMetricType(t.plugin, mt); err != nil {
+			controlLogger.WithFields(log.Fields{
+				"_block": "telemetry",
+				"metric": m.namespace.String(),
+			}).Error(err)
+		}
+	}
+}
+
+// isTelemetryPlugin returns whether the given plugin is the built-in
+// collector
+func (t *telemetryCollector) isTelemetryPlugin(lp *loadedPlugin) bool {
+	return lp == t.plugin
+}
+
+func (t *telemetryCollector) setScheduler(s telemetryScheduler) {
+	t.Lock()
+	defer t.Unlock()
+	t.scheduler = s
+}
+
+func (t *telemetryCollector) setRequestCounter(rc countsRequests) {
+	t.Lock()
+	defer t.Unlock()
+	t.requests = rc
+}
+
+// collect returns the values of the requested metrics. The dynamic elements
+// of the requested namespaces match every value.
+func (t *telemetryCollector) collect(requested []core.Metric) []core.Metric {
+	metrics := []core.Metric{}
+	for _, m := range t.gather() {
+		for _, r := range requested {
+			if matchesTelemetryNamespace(r.Namespace(), m.Namespace()) {
+				m.Version_ = r.Version()
+				m.Config_ = r.Config()
+				metrics = append(metrics, m)
+				break
+			}
+		}
+	}
+	return metrics
+}
+
+// gather returns the current values of all the metrics of the collector.
+// The scheduler and REST API metrics are only gathered once their source is
+// set.
+func (t *telemetryCollector) gather() []plugin.MetricType {
+	t.RLock()
+	defer t.RUnlock()
+	now := time.Now()
+	metrics := []plugin.MetricType{}
+	add := func(ns core.Namespace, tags map[string]string, data interface{}) {
+		// each metric gets its own tags, as the tags are added to further
+		// down the workflow
+		mtags := map[string]string{}
+		for k, v := range tags {
+			mtags[k] = v
+		}
+		metrics = append(metrics, plugin.MetricType{
+			Namespace_: ns,
+			Tags_:      mtags,
+			Data_:      data,
+			Timestamp_: now,
+		})
+	}
+
+	for _, ps := range t.runner.AvailablePlugins().poolStats() {
+		tags := map[string]string{"plugin_type": ps.TypeName, "plugin_version": strconv.Itoa(ps.Version)}
+		ns := func(metric string) core.Namespace {
+			n := poolNamespace(metric)
+			n[4].Value = ps.Name
+			return n
+		}
+		add(ns("running"), tags, ps.Running)
+		add(ns("subscriptions"), tags, ps.Subscriptions)
+		add(ns("restarts"), tags, ps.Restarts)
+		add(ns("cache_hits"), tags, ps.CacheHits)
+		add(ns("cache_misses"), tags, ps.CacheMisses)
+	}
+
+	if t.scheduler != nil {
+		stats := t.scheduler.QueueStats()
+		for _, q := range telemetryQueues {
+			s, ok := stats[q]
+			if !ok {
+				continue
+			}
+			add(queueNamespace(q, "depth"), nil, s.Depth)
+			add(queueNamespace(q, "limit"), nil, s.Limit)
+			add(queueNamespace(q, "workers"), nil, s.Workers)
+			add(queueNamespace(q, "busy_workers"), nil, s.BusyWorkers)
+		}
+		for id, task := range t.scheduler.GetTasks() {
+			tags := map[string]string{"task_name": task.GetName()}
+			ns := func(metric string) core.Namespace {
+				n := taskNamespace(metric)
+				n[4].Value = id
+				return n
+			}
+			add(ns("hit_count"), tags, task.HitCount())
+			add(ns("miss_count"), tags, task.MissedCount())
+			add(ns("fail_count"), tags, task.FailedCount())
+		}
+	}
+
+	if t.requests != nil {
+		requests, errors := t.requests.RequestCounts()
+		add(core.NewNamespace("intel", "snap", "rest", "requests"), nil, requests)
+		add(core.NewNamespace("intel", "snap", "rest", "errors"), nil, errors)
+	}
+	return metrics
+}
+
+// matchesTelemetryNamespace returns whether the namespace of a gathered
+// metric matches the cataloged namespace, whose dynamic elements match
+// every value.
+func matchesTelemetryNamespace(cataloged, ns core.Namespace) bool {
+	if len(cataloged) != len(ns) {
+		return false
+	}
+	for i := range cataloged {
+		if cataloged[i].Value != "*" && cataloged[i].Value != ns[i].Value {
+			return false
+		}
+	}
+	return true
+}
+
+// poolNamespace returns the namespace of a metric of a plugin pool. Each
+// call builds a new namespace, so setting the value of its dynamic element
+// does not change the others.
+func poolNamespace(metric string) core.Namespace {
+	return core.NewNamespace("intel", "snap", "control", "pool").
+		AddDynamicElement("plugin", "name of the plugin").
+		AddStaticElement(metric)
+}
+
+// queueNamespace returns the namespace of a metric of a scheduler work queue
+func queueNamespace(queue, metric string) core.Namespace {
+	return core.NewNamespace("intel", "snap", "scheduler", "queue", queue, metric)
+}
+
+// taskNamespace returns the namespace of a metric of a task
+func taskNamespace(metric string) core.Namespace {
+	return core.NewNamespace("intel", "snap", "scheduler", "task").
+		AddDynamicElement("task_id", "ID of the task").
+		AddStaticElement(metric)
+}
@@ -0,0 +1,114 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/control/fixtures"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+)
+
+type mockTelemetryScheduler struct{}
+
+func (m *mockTelemetryScheduler) GetTasks() map[string]core.Task {
+	return map[string]core.Task{}
+}
+
+func (m *mockTelemetryScheduler) QueueStats() map[string]core.WorkQueueStats {
+	return map[string]core.WorkQueueStats{
+		"collect": {Limit: 25, Depth: 5, Workers: 4, BusyWorkers: 2},
+	}
+}
+
+type mockRequestCounter struct{}
+
+func (m *mockRequestCounter) RequestCounts() (uint64, uint64) {
+	return 7, 1
+}
+
+func requestTelemetry(ns ...string) core.RequestedMetric {
+	return fixtures.MockMetricType{
+		Namespace_: core.NewNamespace(ns...),
+		Cfg:        cdata.NewNode(),
+	}
+}
+
+func TestTelemetryCollector(t *testing.T) {
+	Convey("Built-in telemetry collector", t, func() {
+		cfg := getTestConfig()
+		cfg.Telemetry = true
+		c := New(cfg)
+		c.SetTaskManager(&mockTelemetryScheduler{})
+		c.SetRequestCounter(&mockRequestCounter{})
+		So(c.Start(), ShouldBeNil)
+		defer c.Stop()
+
+		Convey("catalogs its metrics", func() {
+			So(c.MetricExists(core.NewNamespace("intel", "snap", "scheduler", "queue", "collect", "depth"), -1), ShouldBeTrue)
+			mts, err := c.FetchMetrics(core.NewNamespace("intel", "snap", "control", "pool"), 0)
+			So(err, ShouldBeNil)
+			So(len(mts), ShouldEqual, 5)
+		})
+		Convey("collects its metrics without a plugin", func() {
+			serrs := c.SubscribeDeps("telemetry", []core.RequestedMetric{
+				requestTelemetry("intel", "snap", "scheduler", "queue", "collect", "depth"),
+				requestTelemetry("intel", "snap", "rest", "requests"),
+			}, []core.SubscribedPlugin{}, cdata.NewTree())
+			So(serrs, ShouldBeEmpty)
+			So(c.pluginRunner.AvailablePlugins().pools(), ShouldBeEmpty)
+
+			mts, errs := c.CollectMetrics("telemetry", nil, time.Time{})
+			So(errs, ShouldBeEmpty)
+			So(len(mts), ShouldEqual, 2)
+			data := map[string]interface{}{}
+			for _, m := range mts {
+				data[m.Namespace().String()] = m.Data()
+			}
+			So(data["/intel/snap/scheduler/queue/collect/depth"], ShouldEqual, 5)
+			So(data["/intel/snap/rest/requests"], ShouldEqual, uint64(7))
+		})
+		Convey("collects the metrics of each plugin pool", func() {
+			_, err := c.pluginRunner.AvailablePlugins().getOrCreatePool("collector" + core.Separator + "mock" + core.Separator + "1")
+			So(err, ShouldBeNil)
+			serrs := c.SubscribeDeps("telemetry", []core.RequestedMetric{
+				requestTelemetry("intel", "snap", "control", "pool", "*", "running"),
+			}, []core.SubscribedPlugin{}, cdata.NewTree())
+			So(serrs, ShouldBeEmpty)
+
+			mts, errs := c.CollectMetrics("telemetry", nil, time.Time{})
+			So(errs, ShouldBeEmpty)
+			So(len(mts), ShouldEqual, 1)
+			So(mts[0].Namespace().String(), ShouldEqual, "/intel/snap/control/pool/mock/running")
+			So(mts[0].Tags()["plugin_version"], ShouldEqual, "1")
+			So(mts[0].Data(), ShouldEqual, 0)
+		})
+	})
+	Convey("Disabled telemetry collector catalogs no metrics", t, func() {
+		c := New(getTestConfig())
+		So(c.MetricExists(core.NewNamespace("intel", "snap", "scheduler", "queue", "collect", "depth"), -1), ShouldBeFalse)
+	})
+}
@@ -0,0 +1,175 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds of the buckets of a LatencyHistogram.
+// Latencies above the last bound are counted in an unbounded bucket.
+var LatencyBuckets = []time.Duration{
+	time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// LatencyHistogram counts latencies in the buckets bounded by LatencyBuckets.
+// It is safe for concurrent use.
+type LatencyHistogram struct {
+	sync.Mutex
+	// counts holds the count of each bucket, the last being the unbounded
+	// bucket
+	counts []uint64
+	count  uint64
+	sum    time.Duration
+	max    time.Duration
+}
+
+// NewLatencyHistogram returns an empty histogram
+func NewLatencyHistogram() *LatencyHistogram {
+	return &LatencyHistogram{
+		counts: make([]uint64, len(LatencyBuckets)+1),
+	}
+}
+
+// Observe counts a latency in the histogram
+func (h *LatencyHistogram) Observe(d time.Duration) {
+	i := 0
+	for i < len(LatencyBuckets) && d > LatencyBuckets[i] {
+		i++
+	}
+	h.Lock()
+	defer h.Unlock()
+	h.counts[i]++
+	h.count++
+	h.sum += d
+	if d > h.max {
+		h.max = d
+	}
+}
+
+// Snapshot returns the current state of the histogram
+func (h *LatencyHistogram) Snapshot() LatencySnapshot {
+	h.Lock()
+	defer h.Unlock()
+	s := LatencySnapshot{
+		Buckets: make([]LatencyBucket, len(h.counts)),
+		Count:   h.count,
+		Sum:     h.sum,
+		Max:     h.max,
+	}
+	var cumulative uint64
+	for i, c := range h.counts {
+		cumulative += c
+		s.Buckets[i].Count = cumulative
+		if i < len(LatencyBuckets) {
+			s.Buckets[i].UpperBound = LatencyBuckets[i]
+		}
+	}
+	return s
+}
+
+// LatencySnapshot is the state of a LatencyHistogram at a point in time
+type LatencySnapshot struct {
+	// Buckets holds the cumulative count of each bucket, in increasing order
+	// of their upper bound
+	Buckets []LatencyBucket
+	// Count is the number of latencies observed, and Sum their total
+	Count uint64
+	Sum   time.Duration
+	// Max is the highest latency observed
+	Max time.Duration
+}
+
+// LatencyBucket is a bucket of a LatencySnapshot. Count is the number of
+// latencies at or below its upper bound. The unbounded bucket has an upper
+// bound of 0.
+type LatencyBucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+// Mean returns the mean of the observed latencies
+func (s LatencySnapshot) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / time.Duration(s.Count)
+}
+
+// Quantile returns an estimate of the given quantile (0 to 1) of the observed
+// latencies, the upper bound of the bucket holding it. The highest latency
+// observed is returned for quantiles falling in the unbounded bucket.
+func (s LatencySnapshot) Quantile(q float64) time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(q * float64(s.Count)))
+	if rank == 0 {
+		rank = 1
+	}
+	for _, b := range s.Buckets {
+		if b.Count >= rank {
+			if b.UpperBound == 0 || b.UpperBound > s.Max {
+				return s.Max
+			}
+			return b.UpperBound
+		}
+	}
+	return s.Max
+}
+
+// JobLatency is the latency distribution of the jobs run for a node of the
+// workflow of a task.
+type JobLatency struct {
+	// Type is the type of the node, "collector", "processor", "transform" or
+	// "publisher".
+	Type string
+	// PluginName and PluginVersion identify the plugin of a process or
+	// publish node. They are not set for the collect and transform nodes.
+	PluginName    string
+	PluginVersion int
+	Latency       LatencySnapshot
+}
+
+// TaskLatencies holds the latency distributions of the runs of a task and
+// of the jobs run for each node of its workflow.
+type TaskLatencies struct {
+	// Workflow is the distribution of the durations of the runs of the task.
+	Workflow LatencySnapshot
+	Jobs     []JobLatency
+	// SLO is the latency objective of the task, 0 if it has none, and
+	// SLOBreaches the number of runs which took longer.
+	SLO         time.Duration
+	SLOBreaches uint
+}
@@ -0,0 +1,67 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	Convey("Empty latency histogram", t, func() {
+		s := NewLatencyHistogram().Snapshot()
+		So(s.Count, ShouldEqual, 0)
+		So(len(s.Buckets), ShouldEqual, len(LatencyBuckets)+1)
+		So(s.Mean(), ShouldEqual, 0)
+		So(s.Quantile(0.99), ShouldEqual, 0)
+	})
+	Convey("Latency histogram", t, func() {
+		h := NewLatencyHistogram()
+		for i := 0; i < 8; i++ {
+			h.Observe(3 * time.Millisecond)
+		}
+		h.Observe(40 * time.Millisecond)
+		h.Observe(20 * time.Second)
+		s := h.Snapshot()
+
+		Convey("counts the latencies in cumulative buckets", func() {
+			So(s.Count, ShouldEqual, 10)
+			So(s.Buckets[0].Count, ShouldEqual, 0)
+			So(s.Buckets[2].UpperBound, ShouldEqual, 5*time.Millisecond)
+			So(s.Buckets[2].Count, ShouldEqual, 8)
+			So(s.Buckets[5].Count, ShouldEqual, 9)
+			So(s.Buckets[len(s.Buckets)-1].UpperBound, ShouldEqual, 0)
+			So(s.Buckets[len(s.Buckets)-1].Count, ShouldEqual, 10)
+		})
+		Convey("keeps the sum and the maximum", func() {
+			So(s.Sum, ShouldEqual, 24*time.Millisecond+40*time.Millisecond+20*time.Second)
+			So(s.Max, ShouldEqual, 20*time.Second)
+		})
+		Convey("estimates quantiles from the bucket bounds", func() {
+			So(s.Quantile(0.5), ShouldEqual, 5*time.Millisecond)
+			So(s.Quantile(0.9), ShouldEqual, 50*time.Millisecond)
+			So(s.Quantile(0.99), ShouldEqual, 20*time.Second)
+		})
+	})
+}
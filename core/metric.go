@@ -31,6 +31,9 @@ var (
 	// Standard Tags are in added to the metric by the framework on plugin load.
 	// STD_TAG_PLUGIN_RUNNING_ON describes where the plugin is running (hostname).
 	STD_TAG_PLUGIN_RUNNING_ON = "plugin_running_on"
+	// STD_TAG_CATCHUP_TIME is added by the scheduler to the metrics of the runs
+	// catching up a missed interval, with the time the interval was due at.
+	STD_TAG_CATCHUP_TIME = "catchup_time"
 )
 
 // Metric represents a snap metric collected or to be collected
@@ -21,14 +21,28 @@ package core
 
 import (
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"strings"
 	"time"
 
 	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
 	"github.com/intelsdi-x/snap/core/cdata"
 )
 
+// ErrDeadlineExceeded is returned for a call to a plugin which did not
+// complete before the deadline of the job it was made for. The call is
+// cancelled, its result discarded.
+var ErrDeadlineExceeded = errors.New("plugin call exceeded the deadline of its job")
+
+// IsDeadlineExceeded returns whether an error is ErrDeadlineExceeded. The
+// error may be wrapped in a SnapError or carried by its message across a
+// remote call.
+func IsDeadlineExceeded(err error) bool {
+	return err != nil && strings.Contains(err.Error(), ErrDeadlineExceeded.Error())
+}
+
 type Plugin interface {
 	TypeName() string
 	Name() string
@@ -72,6 +86,32 @@ type AvailablePlugin interface {
 	ID() uint32
 }
 
+// PluginPoolStats holds the state of the pool of the running instances of a
+// loaded plugin.
+type PluginPoolStats struct {
+	TypeName string
+	Name     string
+	Version  int
+	// Running is the number of running instances of the plugin.
+	Running int
+	// Subscriptions is the number of tasks subscribed to the plugin.
+	Subscriptions int
+	// Restarts is the number of times an instance of the plugin was
+	// restarted after it died.
+	Restarts int
+	// CacheHits and CacheMisses count the metrics of the plugin served from
+	// and missing from the cache.
+	CacheHits   uint64
+	CacheMisses uint64
+	// DeadlineExceeded is the number of calls to the running instances of
+	// the plugin cancelled because the deadline of their job passed.
+	DeadlineExceeded uint64
+	// Latency is the distribution of the durations of the calls to the
+	// running instances of the plugin collecting, processing or publishing
+	// metrics.
+	Latency LatencySnapshot
+}
+
 // the public interface for a plugin
 // this should be the contract for
 // how mgmt modules know a plugin
@@ -21,6 +21,8 @@ package core
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/intelsdi-x/snap/pkg/schedule"
@@ -31,16 +33,143 @@ type Schedule struct {
 	Interval       string `json:"interval,omitempty"`
 	StartTimestamp *int64 `json:"start_timestamp,omitempty"`
 	StopTimestamp  *int64 `json:"stop_timestamp,omitempty"`
+	Splay          string `json:"splay,omitempty"`
+	Aligned        bool   `json:"aligned,omitempty"`
+	AlignOffset    string `json:"align_offset,omitempty"`
+	// Days, DailyStart, DailyStop and Timezone define the window a windowed
+	// schedule recurs in, such as weekdays between 08:00 and 18:00
+	Days       []string `json:"days,omitempty"`
+	DailyStart string   `json:"daily_start,omitempty"`
+	DailyStop  string   `json:"daily_stop,omitempty"`
+	Timezone   string   `json:"timezone,omitempty"`
+	// TaskID is the ID of the task triggering a triggered-by schedule, which
+	// fires on every completed run of that task or, if SuccessOnly is set,
+	// on its successful runs only
+	TaskID      string `json:"task_id,omitempty"`
+	SuccessOnly bool   `json:"success_only,omitempty"`
+	// Events, Filter and MinInterval define an event schedule, which fires
+	// on the control and scheduler events with one of the namespaces given
+	// whose fields match the filter, at most once per MinInterval
+	Events      []string `json:"events,omitempty"`
+	Filter      string   `json:"filter,omitempty"`
+	MinInterval string   `json:"min_interval,omitempty"`
 }
 
-func makeSchedule(s Schedule) (schedule.Schedule, error) {
+// dailyTimeFormat is the format of the times of day of a recurring window
+const dailyTimeFormat = "15:04"
+
+// weekdays maps the names of the days of a recurring window to the days of
+// the week they stand for
+var weekdays = map[string][]time.Weekday{
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekends": {time.Saturday, time.Sunday},
+}
+
+func init() {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		weekdays[name] = []time.Weekday{d}
+		weekdays[name[:3]] = []time.Weekday{d}
+	}
+}
+
+var (
+	// ErrSplayNotSupported - The error message for a splay given for a cron schedule
+	ErrSplayNotSupported = errors.New("splay is not supported for cron schedules")
+	// ErrAlignedNotSupported - The error message for alignment given for a cron schedule
+	ErrAlignedNotSupported = errors.New("aligned is not supported for cron schedules")
+	// ErrRecurringNotSupported - The error message for a recurring window given for a schedule which is not windowed
+	ErrRecurringNotSupported = errors.New("recurring windows are only supported for windowed schedules")
+	// ErrIntervalNotSupported - The error message for an interval, splay or alignment given for a triggered-by or event schedule
+	ErrIntervalNotSupported = errors.New("interval, splay and aligned are not supported for triggered-by and event schedules")
+)
+
+// isRecurring returns whether any of the fields of a recurring window is set
+func (s Schedule) isRecurring() bool {
+	return len(s.Days) > 0 || s.DailyStart != "" || s.DailyStop != "" || s.Timezone != ""
+}
+
+// hasInterval returns whether any of the fields of an interval schedule is set
+func (s Schedule) hasInterval() bool {
+	return s.Interval != "" || s.Splay != "" || s.Aligned || s.AlignOffset != ""
+}
+
+// makeRecurringWindow returns the recurring window of a windowed schedule,
+// or nil if it has none
+func makeRecurringWindow(s Schedule) (*schedule.RecurringWindow, error) {
+	if !s.isRecurring() {
+		return nil, nil
+	}
+	r := &schedule.RecurringWindow{}
+	for _, name := range s.Days {
+		days, ok := weekdays[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown day of the week %s", name)
+		}
+		r.Days = append(r.Days, days...)
+	}
+	var err error
+	r.Start, err = parseDailyTime(s.DailyStart)
+	if err != nil {
+		return nil, err
+	}
+	r.Stop, err = parseDailyTime(s.DailyStop)
+	if err != nil {
+		return nil, err
+	}
+	if s.Timezone != "" {
+		r.Location, err = time.LoadLocation(s.Timezone)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// parseDailyTime returns the time of day, as a duration since midnight, of
+// a recurring window start or stop, which is midnight if it is not set
+func parseDailyTime(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	t, err := time.Parse(dailyTimeFormat, s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// parseOptionalDuration returns the duration of an optional schedule field,
+// or 0 if it is not set
+func parseOptionalDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// MakeSchedule creates and validates a schedule.Schedule from its
+// serializable representation.
+func MakeSchedule(s Schedule) (schedule.Schedule, error) {
 	switch s.Type {
 	case "simple":
 		d, err := time.ParseDuration(s.Interval)
 		if err != nil {
 			return nil, err
 		}
+		if s.isRecurring() {
+			return nil, ErrRecurringNotSupported
+		}
 		sch := schedule.NewSimpleSchedule(d)
+		sch.Splay, err = parseOptionalDuration(s.Splay)
+		if err != nil {
+			return nil, err
+		}
+		sch.Aligned = s.Aligned
+		sch.AlignOffset, err = parseOptionalDuration(s.AlignOffset)
+		if err != nil {
+			return nil, err
+		}
 
 		err = sch.Validate()
 		if err != nil {
@@ -67,6 +196,19 @@ func makeSchedule(s Schedule) (schedule.Schedule, error) {
 			start,
 			stop,
 		)
+		sch.Splay, err = parseOptionalDuration(s.Splay)
+		if err != nil {
+			return nil, err
+		}
+		sch.Aligned = s.Aligned
+		sch.AlignOffset, err = parseOptionalDuration(s.AlignOffset)
+		if err != nil {
+			return nil, err
+		}
+		sch.Recurring, err = makeRecurringWindow(s)
+		if err != nil {
+			return nil, err
+		}
 
 		err = sch.Validate()
 		if err != nil {
@@ -77,8 +219,52 @@ func makeSchedule(s Schedule) (schedule.Schedule, error) {
 		if s.Interval == "" {
 			return nil, errors.New("missing cron entry")
 		}
+		if s.Splay != "" {
+			return nil, ErrSplayNotSupported
+		}
+		if s.Aligned || s.AlignOffset != "" {
+			return nil, ErrAlignedNotSupported
+		}
+		if s.isRecurring() {
+			return nil, ErrRecurringNotSupported
+		}
 		sch := schedule.NewCronSchedule(s.Interval)
 
+		err := sch.Validate()
+		if err != nil {
+			return nil, err
+		}
+		return sch, nil
+	case "triggered-by":
+		if s.hasInterval() {
+			return nil, ErrIntervalNotSupported
+		}
+		if s.isRecurring() {
+			return nil, ErrRecurringNotSupported
+		}
+		sch := schedule.NewTriggeredSchedule(s.TaskID, s.SuccessOnly)
+
+		err := sch.Validate()
+		if err != nil {
+			return nil, err
+		}
+		return sch, nil
+	case "event":
+		if s.hasInterval() {
+			return nil, ErrIntervalNotSupported
+		}
+		if s.isRecurring() {
+			return nil, ErrRecurringNotSupported
+		}
+		sch := schedule.NewEventSchedule(s.Events, s.Filter)
+		if s.MinInterval != "" {
+			d, err := time.ParseDuration(s.MinInterval)
+			if err != nil {
+				return nil, err
+			}
+			sch.MinInterval = d
+		}
+
 		err := sch.Validate()
 		if err != nil {
 			return nil, err
@@ -88,3 +274,80 @@ func makeSchedule(s Schedule) (schedule.Schedule, error) {
 		return nil, errors.New("unknown schedule type " + s.Type)
 	}
 }
+
+// ScheduleFromSchedule returns the serializable representation of the given
+// schedule.Schedule, or nil if the schedule type is unknown.
+func ScheduleFromSchedule(s schedule.Schedule) *Schedule {
+	switch v := s.(type) {
+	case *schedule.SimpleSchedule:
+		return &Schedule{
+			Type:        "simple",
+			Interval:    v.Interval.String(),
+			Splay:       optionalDurationString(v.Splay),
+			Aligned:     v.Aligned,
+			AlignOffset: optionalDurationString(v.AlignOffset),
+		}
+	case *schedule.WindowedSchedule:
+		sch := &Schedule{
+			Type:        "windowed",
+			Interval:    v.Interval.String(),
+			Splay:       optionalDurationString(v.Splay),
+			Aligned:     v.Aligned,
+			AlignOffset: optionalDurationString(v.AlignOffset),
+		}
+		if v.StartTime != nil {
+			startTime := v.StartTime.Unix()
+			sch.StartTimestamp = &startTime
+		}
+		if v.StopTime != nil {
+			stopTime := v.StopTime.Unix()
+			sch.StopTimestamp = &stopTime
+		}
+		if v.Recurring != nil {
+			for _, d := range v.Recurring.Days {
+				sch.Days = append(sch.Days, strings.ToLower(d.String()[:3]))
+			}
+			sch.DailyStart = dailyTimeString(v.Recurring.Start)
+			sch.DailyStop = dailyTimeString(v.Recurring.Stop)
+			if v.Recurring.Location != nil && v.Recurring.Location != time.Local {
+				sch.Timezone = v.Recurring.Location.String()
+			}
+		}
+		return sch
+	case *schedule.CronSchedule:
+		return &Schedule{
+			Type:     "cron",
+			Interval: v.Entry(),
+		}
+	case *schedule.TriggeredSchedule:
+		return &Schedule{
+			Type:        "triggered-by",
+			TaskID:      v.TaskID,
+			SuccessOnly: v.SuccessOnly,
+		}
+	case *schedule.EventSchedule:
+		return &Schedule{
+			Type:        "event",
+			Events:      v.Events,
+			Filter:      v.Filter,
+			MinInterval: optionalDurationString(v.MinInterval),
+		}
+	default:
+		return nil
+	}
+}
+
+// optionalDurationString returns the serializable representation of an
+// optional schedule field, which is empty if it is not set.
+func optionalDurationString(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return d.String()
+}
+
+// dailyTimeString returns the serializable representation of a recurring
+// window start or stop, given as a duration since midnight.
+func dailyTimeString(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", d/time.Hour, (d%time.Hour)/time.Minute)
+}
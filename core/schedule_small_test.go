@@ -27,6 +27,8 @@ import (
 	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/pkg/schedule"
 )
 
 const (
@@ -37,7 +39,7 @@ func TestMakeSchedule(t *testing.T) {
 
 	Convey("Bad schedule type", t, func() {
 		sched1 := &Schedule{Type: DUMMY_TYPE}
-		rsched, err := makeSchedule(*sched1)
+		rsched, err := MakeSchedule(*sched1)
 		So(rsched, ShouldBeNil)
 		So(err, ShouldNotBeNil)
 		So(err.Error(), ShouldEqual, fmt.Sprintf("unknown schedule type %s", DUMMY_TYPE))
@@ -45,7 +47,7 @@ func TestMakeSchedule(t *testing.T) {
 
 	Convey("Simple schedule with bad duration", t, func() {
 		sched1 := &Schedule{Type: "simple", Interval: "dummy"}
-		rsched, err := makeSchedule(*sched1)
+		rsched, err := MakeSchedule(*sched1)
 		So(rsched, ShouldBeNil)
 		So(err, ShouldNotBeNil)
 		So(err.Error(), ShouldStartWith, "time: invalid duration ")
@@ -53,7 +55,7 @@ func TestMakeSchedule(t *testing.T) {
 
 	Convey("Simple schedule with invalid duration", t, func() {
 		sched1 := &Schedule{Type: "simple", Interval: "-1s"}
-		rsched, err := makeSchedule(*sched1)
+		rsched, err := MakeSchedule(*sched1)
 		So(rsched, ShouldBeNil)
 		So(err, ShouldNotBeNil)
 		So(err.Error(), ShouldEqual, "Interval must be greater than 0")
@@ -61,7 +63,7 @@ func TestMakeSchedule(t *testing.T) {
 
 	Convey("Simple schedule with proper duration", t, func() {
 		sched1 := &Schedule{Type: "simple", Interval: "1s"}
-		rsched, err := makeSchedule(*sched1)
+		rsched, err := MakeSchedule(*sched1)
 		So(err, ShouldBeNil)
 		So(rsched, ShouldNotBeNil)
 		So(rsched.GetState(), ShouldEqual, 0)
@@ -69,7 +71,7 @@ func TestMakeSchedule(t *testing.T) {
 
 	Convey("Windowed schedule with bad duration", t, func() {
 		sched1 := &Schedule{Type: "windowed", Interval: "dummy"}
-		rsched, err := makeSchedule(*sched1)
+		rsched, err := MakeSchedule(*sched1)
 		So(rsched, ShouldBeNil)
 		So(err, ShouldNotBeNil)
 		So(err.Error(), ShouldStartWith, "time: invalid duration ")
@@ -77,7 +79,7 @@ func TestMakeSchedule(t *testing.T) {
 
 	Convey("Windowed schedule with invalid duration", t, func() {
 		sched1 := &Schedule{Type: "windowed", Interval: "-1s"}
-		rsched, err := makeSchedule(*sched1)
+		rsched, err := MakeSchedule(*sched1)
 		So(rsched, ShouldBeNil)
 		So(err, ShouldNotBeNil)
 		So(err.Error(), ShouldEqual, "Interval must be greater than 0")
@@ -89,7 +91,7 @@ func TestMakeSchedule(t *testing.T) {
 		stopSecs := startSecs - 3600
 		sched1 := &Schedule{Type: "windowed", Interval: "1s",
 			StartTimestamp: &startSecs, StopTimestamp: &stopSecs}
-		rsched, err := makeSchedule(*sched1)
+		rsched, err := MakeSchedule(*sched1)
 		So(rsched, ShouldBeNil)
 		So(err, ShouldNotBeNil)
 		So(err.Error(), ShouldEqual, "Stop time is in the past")
@@ -102,7 +104,7 @@ func TestMakeSchedule(t *testing.T) {
 		startSecs = stopSecs + 600
 		sched1 := &Schedule{Type: "windowed", Interval: "1s",
 			StartTimestamp: &startSecs, StopTimestamp: &stopSecs}
-		rsched, err := makeSchedule(*sched1)
+		rsched, err := MakeSchedule(*sched1)
 		So(rsched, ShouldBeNil)
 		So(err, ShouldNotBeNil)
 		So(err.Error(), ShouldEqual, "Stop time cannot occur before start time")
@@ -114,7 +116,7 @@ func TestMakeSchedule(t *testing.T) {
 		stopSecs := startSecs + 600
 		sched1 := &Schedule{Type: "windowed", Interval: "1s",
 			StartTimestamp: &startSecs, StopTimestamp: &stopSecs}
-		rsched, err := makeSchedule(*sched1)
+		rsched, err := MakeSchedule(*sched1)
 		So(err, ShouldBeNil)
 		So(rsched, ShouldNotBeNil)
 		So(rsched.GetState(), ShouldEqual, 0)
@@ -122,7 +124,7 @@ func TestMakeSchedule(t *testing.T) {
 
 	Convey("Cron schedule with bad duration", t, func() {
 		sched1 := &Schedule{Type: "cron", Interval: ""}
-		rsched, err := makeSchedule(*sched1)
+		rsched, err := MakeSchedule(*sched1)
 		So(rsched, ShouldBeNil)
 		So(err, ShouldNotBeNil)
 		So(err.Error(), ShouldEqual, "missing cron entry")
@@ -130,7 +132,7 @@ func TestMakeSchedule(t *testing.T) {
 
 	Convey("Cron schedule with invalid duration", t, func() {
 		sched1 := &Schedule{Type: "windowed", Interval: "-1s"}
-		rsched, err := makeSchedule(*sched1)
+		rsched, err := MakeSchedule(*sched1)
 		So(rsched, ShouldBeNil)
 		So(err, ShouldNotBeNil)
 		So(err.Error(), ShouldEqual, "Interval must be greater than 0")
@@ -138,7 +140,7 @@ func TestMakeSchedule(t *testing.T) {
 
 	Convey("Cron schedule with too few fields entry", t, func() {
 		sched1 := &Schedule{Type: "cron", Interval: "1 2 3"}
-		rsched, err := makeSchedule(*sched1)
+		rsched, err := MakeSchedule(*sched1)
 		So(rsched, ShouldBeNil)
 		So(err, ShouldNotBeNil)
 		So(err.Error(), ShouldStartWith, "Expected 5 or 6 fields, found ")
@@ -146,23 +148,219 @@ func TestMakeSchedule(t *testing.T) {
 
 	Convey("Cron schedule with 5 fields entry", t, func() {
 		sched1 := &Schedule{Type: "cron", Interval: "1 2 3 4 5"}
-		rsched, err := makeSchedule(*sched1)
+		rsched, err := MakeSchedule(*sched1)
 		So(err, ShouldBeNil)
 		So(rsched, ShouldNotBeNil)
 	})
 
 	Convey("Cron schedule with 6 fields entry", t, func() {
 		sched1 := &Schedule{Type: "cron", Interval: "1 2 3 4 5 6"}
-		rsched, err := makeSchedule(*sched1)
+		rsched, err := MakeSchedule(*sched1)
 		So(err, ShouldBeNil)
 		So(rsched, ShouldNotBeNil)
 	})
 
 	Convey("Cron schedule with too many fields entry", t, func() {
 		sched1 := &Schedule{Type: "cron", Interval: "1 2 3 4 5 6 7 8"}
-		rsched, err := makeSchedule(*sched1)
+		rsched, err := MakeSchedule(*sched1)
 		So(rsched, ShouldBeNil)
 		So(err, ShouldNotBeNil)
 		So(err.Error(), ShouldStartWith, "Expected 5 or 6 fields, found ")
 	})
+
+	Convey("Simple schedule with splay", t, func() {
+		sched1 := &Schedule{Type: "simple", Interval: "10s", Splay: "2s"}
+		rsched, err := MakeSchedule(*sched1)
+		So(err, ShouldBeNil)
+		So(rsched.(*schedule.SimpleSchedule).Splay, ShouldEqual, 2*time.Second)
+		So(ScheduleFromSchedule(rsched).Splay, ShouldEqual, "2s")
+	})
+
+	Convey("Windowed schedule with splay", t, func() {
+		sched1 := &Schedule{Type: "windowed", Interval: "10s", Splay: "2s"}
+		rsched, err := MakeSchedule(*sched1)
+		So(err, ShouldBeNil)
+		So(rsched.(*schedule.WindowedSchedule).Splay, ShouldEqual, 2*time.Second)
+		So(ScheduleFromSchedule(rsched).Splay, ShouldEqual, "2s")
+	})
+
+	Convey("Simple schedule with bad splay", t, func() {
+		sched1 := &Schedule{Type: "simple", Interval: "10s", Splay: "abc"}
+		rsched, err := MakeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Simple schedule with splay not less than the interval", t, func() {
+		sched1 := &Schedule{Type: "simple", Interval: "10s", Splay: "10s"}
+		rsched, err := MakeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldEqual, schedule.ErrInvalidSplay)
+	})
+
+	Convey("Simple schedule aligned with an offset", t, func() {
+		sched1 := &Schedule{Type: "simple", Interval: "10s", Aligned: true, AlignOffset: "2s"}
+		rsched, err := MakeSchedule(*sched1)
+		So(err, ShouldBeNil)
+		So(rsched.(*schedule.SimpleSchedule).Aligned, ShouldBeTrue)
+		So(rsched.(*schedule.SimpleSchedule).AlignOffset, ShouldEqual, 2*time.Second)
+		So(*ScheduleFromSchedule(rsched), ShouldResemble, *sched1)
+	})
+
+	Convey("Windowed schedule aligned", t, func() {
+		sched1 := &Schedule{Type: "windowed", Interval: "10s", Aligned: true}
+		rsched, err := MakeSchedule(*sched1)
+		So(err, ShouldBeNil)
+		So(rsched.(*schedule.WindowedSchedule).Aligned, ShouldBeTrue)
+		So(*ScheduleFromSchedule(rsched), ShouldResemble, *sched1)
+	})
+
+	Convey("Simple schedule with an align offset not less than the interval", t, func() {
+		sched1 := &Schedule{Type: "simple", Interval: "10s", Aligned: true, AlignOffset: "10s"}
+		rsched, err := MakeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldEqual, schedule.ErrInvalidAlignOffset)
+	})
+
+	Convey("Cron schedule aligned", t, func() {
+		sched1 := &Schedule{Type: "cron", Interval: "0 * * * * *", Aligned: true}
+		rsched, err := MakeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldEqual, ErrAlignedNotSupported)
+	})
+
+	Convey("Cron schedule with splay", t, func() {
+		sched1 := &Schedule{Type: "cron", Interval: "0 * * * * *", Splay: "2s"}
+		rsched, err := MakeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldEqual, ErrSplayNotSupported)
+	})
+
+	Convey("Windowed schedule with a recurring window", t, func() {
+		sched1 := &Schedule{
+			Type:       "windowed",
+			Interval:   "10s",
+			Days:       []string{"weekdays"},
+			DailyStart: "08:00",
+			DailyStop:  "18:30",
+			Timezone:   "UTC",
+		}
+		rsched, err := MakeSchedule(*sched1)
+		So(err, ShouldBeNil)
+		r := rsched.(*schedule.WindowedSchedule).Recurring
+		So(r, ShouldNotBeNil)
+		So(r.Days, ShouldResemble, []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday})
+		So(r.Start, ShouldEqual, 8*time.Hour)
+		So(r.Stop, ShouldEqual, 18*time.Hour+30*time.Minute)
+		So(r.Location, ShouldEqual, time.UTC)
+		rs := ScheduleFromSchedule(rsched)
+		So(rs.Days, ShouldResemble, []string{"mon", "tue", "wed", "thu", "fri"})
+		So(rs.DailyStart, ShouldEqual, "08:00")
+		So(rs.DailyStop, ShouldEqual, "18:30")
+		So(rs.Timezone, ShouldEqual, "UTC")
+	})
+
+	Convey("Windowed schedule recurring on days only", t, func() {
+		sched1 := &Schedule{Type: "windowed", Interval: "10s", Days: []string{"Sunday", "sat"}}
+		rsched, err := MakeSchedule(*sched1)
+		So(err, ShouldBeNil)
+		r := rsched.(*schedule.WindowedSchedule).Recurring
+		So(r.Days, ShouldResemble, []time.Weekday{time.Sunday, time.Saturday})
+		So(r.Start, ShouldEqual, 0)
+		So(r.Stop, ShouldEqual, 0)
+		So(ScheduleFromSchedule(rsched).Timezone, ShouldEqual, "")
+	})
+
+	Convey("Windowed schedule with an unknown day", t, func() {
+		sched1 := &Schedule{Type: "windowed", Interval: "10s", Days: []string{"someday"}}
+		rsched, err := MakeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Windowed schedule with a bad daily start", t, func() {
+		sched1 := &Schedule{Type: "windowed", Interval: "10s", DailyStart: "8am"}
+		rsched, err := MakeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Windowed schedule with an unknown time zone", t, func() {
+		sched1 := &Schedule{Type: "windowed", Interval: "10s", Timezone: "Nowhere/Special"}
+		rsched, err := MakeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Simple schedule with a recurring window", t, func() {
+		sched1 := &Schedule{Type: "simple", Interval: "10s", Days: []string{"mon"}}
+		rsched, err := MakeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldEqual, ErrRecurringNotSupported)
+	})
+
+	Convey("Triggered-by schedule", t, func() {
+		sched1 := &Schedule{Type: "triggered-by", TaskID: "1234", SuccessOnly: true}
+		rsched, err := MakeSchedule(*sched1)
+		So(err, ShouldBeNil)
+		So(rsched.(*schedule.TriggeredSchedule).TaskID, ShouldEqual, "1234")
+		So(rsched.(*schedule.TriggeredSchedule).SuccessOnly, ShouldBeTrue)
+		So(ScheduleFromSchedule(rsched), ShouldResemble, sched1)
+	})
+
+	Convey("Triggered-by schedule without a task", t, func() {
+		sched1 := &Schedule{Type: "triggered-by"}
+		rsched, err := MakeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldEqual, schedule.ErrMissingTriggeringTask)
+	})
+
+	Convey("Triggered-by schedule with an interval", t, func() {
+		sched1 := &Schedule{Type: "triggered-by", TaskID: "1234", Interval: "10s"}
+		rsched, err := MakeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldEqual, ErrIntervalNotSupported)
+	})
+
+	Convey("Event schedule", t, func() {
+		sched1 := &Schedule{
+			Type:        "event",
+			Events:      []string{"Control.PluginLoaded", "Control.PluginsSwapped"},
+			Filter:      `Name == "mock"`,
+			MinInterval: "5s",
+		}
+		rsched, err := MakeSchedule(*sched1)
+		So(err, ShouldBeNil)
+		So(rsched.(*schedule.EventSchedule).MinInterval, ShouldEqual, 5*time.Second)
+		So(ScheduleFromSchedule(rsched), ShouldResemble, sched1)
+	})
+
+	Convey("Event schedule with the default minimum interval", t, func() {
+		sched1 := &Schedule{Type: "event", Events: []string{"Control.*"}}
+		rsched, err := MakeSchedule(*sched1)
+		So(err, ShouldBeNil)
+		So(rsched.(*schedule.EventSchedule).MinInterval, ShouldEqual, schedule.DefaultEventMinInterval)
+		So(ScheduleFromSchedule(rsched).MinInterval, ShouldEqual, schedule.DefaultEventMinInterval.String())
+	})
+
+	Convey("Event schedule without events", t, func() {
+		sched1 := &Schedule{Type: "event", Filter: `Name == "mock"`}
+		rsched, err := MakeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldEqual, schedule.ErrMissingEvents)
+	})
+
+	Convey("Event schedule with a bad filter", t, func() {
+		sched1 := &Schedule{Type: "event", Events: []string{"Control.*"}, Filter: "Name"}
+		rsched, err := MakeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Event schedule with splay", t, func() {
+		sched1 := &Schedule{Type: "event", Events: []string{"Control.*"}, Splay: "1s"}
+		rsched, err := MakeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldEqual, ErrIntervalNotSupported)
+	})
 }
@@ -20,6 +20,8 @@ limitations under the License.
 package scheduler_event
 
 import (
+	"time"
+
 	"github.com/intelsdi-x/snap/core"
 )
 
@@ -29,8 +31,12 @@ const (
 	TaskStarted            = "Scheduler.TaskStarted"
 	TaskStopped            = "Scheduler.TaskStopped"
 	TaskDisabled           = "Scheduler.TaskDisabled"
+	TaskEnded              = "Scheduler.TaskEnded"
+	TaskUpdated            = "Scheduler.TaskUpdated"
 	MetricCollected        = "Scheduler.MetricsCollected"
 	MetricCollectionFailed = "Scheduler.MetricCollectionFailed"
+	WorkflowCompleted      = "Scheduler.WorkflowCompleted"
+	LatencySLOBreached     = "Scheduler.LatencySLOBreached"
 )
 
 type TaskStartedEvent struct {
@@ -70,6 +76,15 @@ func (e TaskStoppedEvent) Namespace() string {
 	return TaskStopped
 }
 
+type TaskUpdatedEvent struct {
+	TaskID string
+	Source string
+}
+
+func (e TaskUpdatedEvent) Namespace() string {
+	return TaskUpdated
+}
+
 type TaskDisabledEvent struct {
 	TaskID string
 	Why    string
@@ -79,6 +94,15 @@ func (e TaskDisabledEvent) Namespace() string {
 	return TaskDisabled
 }
 
+// TaskEndedEvent is emitted when a task stops because its schedule ended.
+type TaskEndedEvent struct {
+	TaskID string
+}
+
+func (e TaskEndedEvent) Namespace() string {
+	return TaskEnded
+}
+
 type MetricCollectedEvent struct {
 	TaskID  string
 	Metrics []core.Metric
@@ -91,8 +115,38 @@ func (e MetricCollectedEvent) Namespace() string {
 type MetricCollectionFailedEvent struct {
 	TaskID string
 	Errors []error
+	// PluginErrors holds the errors returned by each collector plugin which
+	// failed, keyed by plugin name and version (name:version)
+	PluginErrors map[string][]error
+	// Partial is set when the metrics of the collector plugins which
+	// succeeded continued down the workflow (best-effort collect policy)
+	Partial bool
 }
 
 func (e MetricCollectionFailedEvent) Namespace() string {
 	return MetricCollectionFailed
 }
+
+// WorkflowCompletedEvent is emitted once the whole workflow of a task has
+// run, after the collect, process and publish jobs of the run completed.
+type WorkflowCompletedEvent struct {
+	TaskID string
+	// Failed is set when any job of the run failed
+	Failed bool
+}
+
+func (e WorkflowCompletedEvent) Namespace() string {
+	return WorkflowCompleted
+}
+
+// LatencySLOBreachedEvent is emitted when a run of a task takes longer than
+// the latency objective of the task.
+type LatencySLOBreachedEvent struct {
+	TaskID  string
+	Latency time.Duration
+	SLO     time.Duration
+}
+
+func (e LatencySLOBreachedEvent) Namespace() string {
+	return LatencySLOBreached
+}
@@ -0,0 +1,49 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import "time"
+
+// SpoolEntry is a payload of metrics a publish node failed to publish, held
+// in the spool of the scheduler until it is replayed once the publisher
+// recovers.
+type SpoolEntry struct {
+	ID     string
+	TaskID string
+	// PluginName and PluginVersion identify the plugin of the publish node.
+	PluginName    string
+	PluginVersion int
+	// ContentType is the content type the metrics are written in.
+	ContentType string
+	MetricCount int
+	// Size is the size of the payload in bytes.
+	Size    int64
+	Created time.Time
+}
+
+// SpoolStats describes the spool of the scheduler and the entries it holds.
+type SpoolStats struct {
+	Path string
+	// Size is the total size of the payloads in bytes, and MaxSize the size
+	// they are limited to, 0 if unlimited.
+	Size    int64
+	MaxSize int64
+	Entries []SpoolEntry
+}
@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"reflect"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
@@ -56,6 +57,255 @@ var (
 	}
 )
 
+// CollectPolicy determines how a task handles the failure of some of the
+// collector plugins it gathers metrics from.
+type CollectPolicy int
+
+const (
+	// CollectAllOrNothing fails the collection if any collector plugin fails
+	CollectAllOrNothing CollectPolicy = iota
+	// CollectBestEffort continues the workflow with the metrics gathered by
+	// the collector plugins which succeeded
+	CollectBestEffort
+)
+
+var (
+	CollectPolicyLookup = map[CollectPolicy]string{
+		CollectAllOrNothing: "all-or-nothing",
+		CollectBestEffort:   "best-effort",
+	}
+
+	// ErrUnknownCollectPolicy - The error message for an unknown collect policy
+	ErrUnknownCollectPolicy = errors.New("Unknown collect policy")
+)
+
+func (c CollectPolicy) String() string {
+	return CollectPolicyLookup[c]
+}
+
+// ParseCollectPolicy returns the collect policy with the given name. An empty
+// name is the default all-or-nothing policy.
+func ParseCollectPolicy(s string) (CollectPolicy, error) {
+	if s == "" {
+		return CollectAllOrNothing, nil
+	}
+	for k, v := range CollectPolicyLookup {
+		if v == s {
+			return k, nil
+		}
+	}
+	return CollectAllOrNothing, fmt.Errorf("%v: %v", ErrUnknownCollectPolicy, s)
+}
+
+// TaskPriority determines the order the jobs of the tasks waiting in the
+// work manager queues are worked in.
+type TaskPriority int
+
+const (
+	// PriorityLow jobs are worked after the jobs of the other tasks
+	PriorityLow TaskPriority = iota - 1
+	// PriorityNormal is the priority of a task unless it is set otherwise
+	PriorityNormal
+	// PriorityHigh jobs are worked before the jobs of normal tasks
+	PriorityHigh
+	// PriorityCritical jobs are worked before the jobs of all other tasks
+	PriorityCritical
+)
+
+var (
+	TaskPriorityLookup = map[TaskPriority]string{
+		PriorityLow:      "low",
+		PriorityNormal:   "normal",
+		PriorityHigh:     "high",
+		PriorityCritical: "critical",
+	}
+
+	// ErrUnknownTaskPriority - The error message for an unknown task priority
+	ErrUnknownTaskPriority = errors.New("Unknown task priority")
+)
+
+func (p TaskPriority) String() string {
+	return TaskPriorityLookup[p]
+}
+
+// ParseTaskPriority returns the task priority with the given name. An empty
+// name is the normal priority.
+func ParseTaskPriority(s string) (TaskPriority, error) {
+	if s == "" {
+		return PriorityNormal, nil
+	}
+	for k, v := range TaskPriorityLookup {
+		if v == s {
+			return k, nil
+		}
+	}
+	return PriorityNormal, fmt.Errorf("%v: %v", ErrUnknownTaskPriority, s)
+}
+
+// WorkQueueStats holds the depth of one of the queues of the scheduler work
+// manager and the utilization of the worker pool working it.
+type WorkQueueStats struct {
+	// Limit is the number of jobs the queue holds at most, 0 if unbounded.
+	Limit uint
+	// Depth is the number of jobs waiting in the queue.
+	Depth int
+	// DepthByPriority is the number of jobs waiting in the queue for each
+	// priority.
+	DepthByPriority map[TaskPriority]int
+	// Workers is the size of the worker pool working the queue.
+	Workers uint
+	// BusyWorkers is the number of workers running a job.
+	BusyWorkers uint
+}
+
+// WorkQueueResizeRequest changes the limit of a queue of the scheduler work
+// manager and/or the size of its worker pool. The values which are not set
+// are left unchanged.
+type WorkQueueResizeRequest struct {
+	Limit   *uint `json:"limit,omitempty"`
+	Workers *uint `json:"workers,omitempty"`
+}
+
+// DefaultMaxBackoff is the factor the interval of a backing off task is
+// stretched by at most, unless its failure policy sets another one
+const DefaultMaxBackoff = 32
+
+// FailurePolicy determines what a task does once its consecutive failures
+// reach its max-failures.
+type FailurePolicy struct {
+	// Backoff makes the task back off instead of being disabled: each further
+	// failure doubles the interval between its fires, until it succeeds again.
+	Backoff bool `json:"backoff"`
+	// MaxBackoff caps the factor the interval of a backing off task is
+	// stretched by, DefaultMaxBackoff if it is 0.
+	MaxBackoff uint `json:"max-backoff,omitempty"`
+	// DisableAfter is the number of consecutive failures a backing off task
+	// is disabled after. It is never disabled if DisableAfter is 0.
+	DisableAfter int `json:"disable-after,omitempty"`
+}
+
+// MaxBackoffFactor returns the factor the interval of a backing off task is
+// stretched by at most
+func (f FailurePolicy) MaxBackoffFactor() uint {
+	if f.MaxBackoff == 0 {
+		return DefaultMaxBackoff
+	}
+	return f.MaxBackoff
+}
+
+const (
+	// CatchupSkip skips the intervals a task missed
+	CatchupSkip = "skip"
+	// CatchupRunOnce runs the workflow once for the most recent interval a
+	// task missed
+	CatchupRunOnce = "run-once"
+	// CatchupRunAll runs the workflow for each interval a task missed, up to
+	// the max-runs most recent ones
+	CatchupRunAll = "run-all"
+	// DefaultMaxCatchupRuns is the number of missed intervals a task catches
+	// up at most, unless its catch-up policy sets another one
+	DefaultMaxCatchupRuns = 10
+)
+
+// ErrUnknownCatchupMode - The error message for an unknown catch-up mode
+var ErrUnknownCatchupMode = errors.New("Unknown catch-up mode")
+
+// CatchupPolicy determines whether a task makes up for the intervals of its
+// schedule it missed. The runs catching up an interval carry the time the
+// interval was due at.
+type CatchupPolicy struct {
+	// Mode is skip, run-once or run-all. An empty mode is skip.
+	Mode string `json:"mode"`
+	// MaxRuns caps the intervals caught up by the run-all mode,
+	// DefaultMaxCatchupRuns if it is 0.
+	MaxRuns uint `json:"max-runs,omitempty"`
+}
+
+// Validate returns an error if the mode of the policy is unknown
+func (c CatchupPolicy) Validate() error {
+	switch c.Mode {
+	case "", CatchupSkip, CatchupRunOnce, CatchupRunAll:
+		return nil
+	}
+	return fmt.Errorf("%v: %v", ErrUnknownCatchupMode, c.Mode)
+}
+
+// Runs returns the number of intervals caught up out of the given number of
+// missed intervals
+func (c CatchupPolicy) Runs(missed uint) uint {
+	switch c.Mode {
+	case CatchupRunOnce:
+		if missed > 0 {
+			return 1
+		}
+	case CatchupRunAll:
+		max := c.MaxRuns
+		if max == 0 {
+			max = DefaultMaxCatchupRuns
+		}
+		if missed > max {
+			return max
+		}
+		return missed
+	}
+	return 0
+}
+
+const (
+	// OverlapQueueOne holds a fire of the schedule received while the
+	// workflow of a task is running, and runs it once the run in progress
+	// completes. The fires received while one is held are skipped.
+	OverlapQueueOne = "queue-one"
+	// OverlapSkip skips the fires of the schedule received while the workflow
+	// of a task is running
+	OverlapSkip = "skip"
+	// OverlapConcurrent runs the workflow of a task for each fire of its
+	// schedule, up to max-concurrent runs at a time. The fires received while
+	// that many runs are in progress are skipped.
+	OverlapConcurrent = "concurrent"
+	// DefaultMaxConcurrentRuns is the number of runs of a task in progress at
+	// a time with the concurrent mode, unless its overlap policy sets another
+	// one
+	DefaultMaxConcurrentRuns = 4
+)
+
+// ErrUnknownOverlapMode - The error message for an unknown overlap mode
+var ErrUnknownOverlapMode = errors.New("Unknown overlap mode")
+
+// OverlapPolicy determines what a task does when its schedule fires while
+// its workflow is still running. The fires skipped because the task is busy
+// are counted apart from the intervals its schedule missed.
+type OverlapPolicy struct {
+	// Mode is queue-one, skip or concurrent. An empty mode is queue-one.
+	Mode string `json:"mode"`
+	// MaxConcurrent caps the runs in progress with the concurrent mode,
+	// DefaultMaxConcurrentRuns if it is 0.
+	MaxConcurrent uint `json:"max-concurrent,omitempty"`
+}
+
+// Validate returns an error if the mode of the policy is unknown
+func (o OverlapPolicy) Validate() error {
+	switch o.Mode {
+	case "", OverlapQueueOne, OverlapSkip, OverlapConcurrent:
+		return nil
+	}
+	return fmt.Errorf("%v: %v", ErrUnknownOverlapMode, o.Mode)
+}
+
+// MaxRuns returns the number of runs of a task in progress at a time
+func (o OverlapPolicy) MaxRuns() uint {
+	if o.Mode != OverlapConcurrent {
+		return 1
+	}
+	if o.MaxConcurrent == 0 {
+		return DefaultMaxConcurrentRuns
+	}
+	return o.MaxConcurrent
+}
+
+// ErrNegativeLatencySLO - The error message for a negative latency objective
+var ErrNegativeLatencySLO = errors.New("Latency objective must not be negative")
+
 type TaskWatcherCloser interface {
 	Close() error
 }
@@ -71,6 +321,42 @@ func (t TaskState) String() string {
 	return TaskStateLookup[t]
 }
 
+// TaskRun is the record of a run of a task, as kept in the history of the
+// task.
+type TaskRun struct {
+	// FireTime is the time the task fired.
+	FireTime time.Time
+	// Duration is the time it took to run the workflow of the task.
+	Duration time.Duration
+	// Failed is set if the run was recorded as a failure of the task.
+	Failed bool
+	// CatchUp is set if the run caught up an interval the task missed. Its
+	// FireTime is the time the interval was due at.
+	CatchUp bool
+	// Jobs holds a record for each node of the workflow which was run, in
+	// the order they completed.
+	Jobs []TaskRunJob
+}
+
+// TaskRunJob is the record of the job run for a node of the workflow of a
+// task.
+type TaskRunJob struct {
+	// Type is the type of the node, "collector", "processor", "transform" or
+	// "publisher".
+	Type string
+	// PluginName and PluginVersion identify the plugin of a process or
+	// publish node. They are not set for the collect and transform nodes.
+	PluginName    string
+	PluginVersion int
+	// Duration is the time from the submission of the job until it
+	// completed.
+	Duration time.Duration
+	// MetricCount is the number of metrics the job returned, or for a
+	// publish job the number of metrics it published.
+	MetricCount int
+	Errors      []string
+}
+
 type Task interface {
 	ID() string
 	// Status() WorkflowState TODO, switch to string
@@ -81,6 +367,9 @@ type Task interface {
 	SetID(string)
 	MissedCount() uint
 	FailedCount() uint
+	OverflowCount() uint
+	DeadlineExceededCount() uint
+	BusySkipCount() uint
 	LastFailureMessage() string
 	LastRunTime() *time.Time
 	CreationTime() *time.Time
@@ -89,6 +378,21 @@ type Task interface {
 	SetTaskID(id string)
 	SetStopOnFailure(int)
 	GetStopOnFailure() int
+	SetCollectPolicy(CollectPolicy)
+	GetCollectPolicy() CollectPolicy
+	SetFailurePolicy(FailurePolicy)
+	GetFailurePolicy() FailurePolicy
+	BackoffLevel() uint
+	SetCatchupPolicy(CatchupPolicy)
+	GetCatchupPolicy() CatchupPolicy
+	SetPriority(TaskPriority)
+	GetPriority() TaskPriority
+	SetOverlapPolicy(OverlapPolicy)
+	GetOverlapPolicy() OverlapPolicy
+	SetLatencySLO(time.Duration)
+	LatencySLO() time.Duration
+	SLOBreachCount() uint
+	LastRunDuration() time.Duration
 	Option(...TaskOption) TaskOption
 	WMap() *wmap.WorkflowMap
 	Schedule() schedule.Schedule
@@ -133,6 +437,114 @@ func OptionStopOnFailure(v int) TaskOption {
 	}
 }
 
+// OptionCollectPolicy sets the tasks collect policy.
+// The collect policy determines whether the metrics of the collector plugins
+// which succeeded continue down the workflow when other collector plugins fail.
+func OptionCollectPolicy(v CollectPolicy) TaskOption {
+	return func(t Task) TaskOption {
+		previous := t.GetCollectPolicy()
+		t.SetCollectPolicy(v)
+		log.WithFields(log.Fields{
+			"_module":        "core",
+			"_block":         "OptionCollectPolicy",
+			"task-id":        t.ID(),
+			"task-name":      t.GetName(),
+			"collect policy": t.GetCollectPolicy(),
+		}).Debug("Setting collect policy for task")
+		return OptionCollectPolicy(previous)
+	}
+}
+
+// OptionFailurePolicy sets the tasks failure policy.
+// The failure policy determines whether a task reaching its consecutive
+// failure limit is disabled or backs off.
+func OptionFailurePolicy(v FailurePolicy) TaskOption {
+	return func(t Task) TaskOption {
+		previous := t.GetFailurePolicy()
+		t.SetFailurePolicy(v)
+		log.WithFields(log.Fields{
+			"_module":        "core",
+			"_block":         "OptionFailurePolicy",
+			"task-id":        t.ID(),
+			"task-name":      t.GetName(),
+			"failure policy": t.GetFailurePolicy(),
+		}).Debug("Setting failure policy for task")
+		return OptionFailurePolicy(previous)
+	}
+}
+
+// OptionCatchupPolicy sets the tasks catch-up policy.
+// The catch-up policy determines whether a task runs its workflow for the
+// intervals of its schedule it missed.
+func OptionCatchupPolicy(v CatchupPolicy) TaskOption {
+	return func(t Task) TaskOption {
+		previous := t.GetCatchupPolicy()
+		t.SetCatchupPolicy(v)
+		log.WithFields(log.Fields{
+			"_module":        "core",
+			"_block":         "OptionCatchupPolicy",
+			"task-id":        t.ID(),
+			"task-name":      t.GetName(),
+			"catchup policy": t.GetCatchupPolicy(),
+		}).Debug("Setting catch-up policy for task")
+		return OptionCatchupPolicy(previous)
+	}
+}
+
+// OptionOverlapPolicy sets the tasks overlap policy.
+// The overlap policy determines what the task does when its schedule fires
+// while its workflow is still running.
+func OptionOverlapPolicy(v OverlapPolicy) TaskOption {
+	return func(t Task) TaskOption {
+		previous := t.GetOverlapPolicy()
+		t.SetOverlapPolicy(v)
+		log.WithFields(log.Fields{
+			"_module":        "core",
+			"_block":         "OptionOverlapPolicy",
+			"task-id":        t.ID(),
+			"task-name":      t.GetName(),
+			"overlap policy": t.GetOverlapPolicy(),
+		}).Debug("Setting overlap policy for task")
+		return OptionOverlapPolicy(previous)
+	}
+}
+
+// OptionLatencySLO sets the tasks latency objective.
+// A run of the task taking longer than its latency objective is counted as a
+// breach and emits an event. A latency objective of 0 disables it.
+func OptionLatencySLO(v time.Duration) TaskOption {
+	return func(t Task) TaskOption {
+		previous := t.LatencySLO()
+		t.SetLatencySLO(v)
+		log.WithFields(log.Fields{
+			"_module":     "core",
+			"_block":      "OptionLatencySLO",
+			"task-id":     t.ID(),
+			"task-name":   t.GetName(),
+			"latency slo": t.LatencySLO(),
+		}).Debug("Setting latency objective for task")
+		return OptionLatencySLO(previous)
+	}
+}
+
+// OptionPriority sets the tasks priority.
+// The priority determines the order the jobs of the task are worked in when
+// the work manager queues hold the jobs of several tasks.
+func OptionPriority(v TaskPriority) TaskOption {
+	return func(t Task) TaskOption {
+		previous := t.GetPriority()
+		t.SetPriority(v)
+		log.WithFields(log.Fields{
+			"_module":   "core",
+			"_block":    "OptionPriority",
+			"task-id":   t.ID(),
+			"task-name": t.GetName(),
+			"priority":  t.GetPriority(),
+		}).Debug("Setting priority for task")
+		return OptionPriority(previous)
+	}
+}
+
 // SetTaskName sets the name of the task.
 // This is optional.
 // If task name is not set, the task name is then defaulted to "Task-<task-id>"
@@ -157,13 +569,19 @@ type TaskErrors interface {
 }
 
 type TaskCreationRequest struct {
-	Name        string            `json:"name"`
-	Version     int               `json:"version"`
-	Deadline    string            `json:"deadline"`
-	Workflow    *wmap.WorkflowMap `json:"workflow"`
-	Schedule    *Schedule         `json:"schedule"`
-	Start       bool              `json:"start"`
-	MaxFailures int               `json:"max-failures"`
+	Name          string            `json:"name"`
+	Version       int               `json:"version"`
+	Deadline      string            `json:"deadline"`
+	Workflow      *wmap.WorkflowMap `json:"workflow"`
+	Schedule      *Schedule         `json:"schedule"`
+	Start         bool              `json:"start"`
+	MaxFailures   int               `json:"max-failures"`
+	CollectPolicy string            `json:"collect-policy,omitempty"`
+	FailurePolicy *FailurePolicy    `json:"failure-policy,omitempty"`
+	Catchup       *CatchupPolicy    `json:"catchup,omitempty"`
+	Priority      string            `json:"priority,omitempty"`
+	Overlap       *OverlapPolicy    `json:"overlap,omitempty"`
+	LatencySLO    string            `json:"latency-slo,omitempty"`
 }
 
 func (tr *TaskCreationRequest) UnmarshalJSON(data []byte) error {
@@ -197,6 +615,30 @@ func (tr *TaskCreationRequest) UnmarshalJSON(data []byte) error {
 			if err := json.Unmarshal(v, &(tr.MaxFailures)); err != nil {
 				return fmt.Errorf("%v (while parsing 'max-failures')", err)
 			}
+		case "collect-policy":
+			if err := json.Unmarshal(v, &(tr.CollectPolicy)); err != nil {
+				return fmt.Errorf("%v (while parsing 'collect-policy')", err)
+			}
+		case "failure-policy":
+			if err := json.Unmarshal(v, &(tr.FailurePolicy)); err != nil {
+				return fmt.Errorf("%v (while parsing 'failure-policy')", err)
+			}
+		case "catchup":
+			if err := json.Unmarshal(v, &(tr.Catchup)); err != nil {
+				return fmt.Errorf("%v (while parsing 'catchup')", err)
+			}
+		case "overlap":
+			if err := json.Unmarshal(v, &(tr.Overlap)); err != nil {
+				return fmt.Errorf("%v (while parsing 'overlap')", err)
+			}
+		case "priority":
+			if err := json.Unmarshal(v, &(tr.Priority)); err != nil {
+				return fmt.Errorf("%v (while parsing 'priority')", err)
+			}
+		case "latency-slo":
+			if err := json.Unmarshal(v, &(tr.LatencySLO)); err != nil {
+				return fmt.Errorf("%v (while parsing 'latency-slo')", err)
+			}
 		case "version":
 			if err := json.Unmarshal(v, &(tr.Version)); err != nil {
 				return fmt.Errorf("%v (while parsing 'version')", err)
@@ -208,6 +650,34 @@ func (tr *TaskCreationRequest) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// TaskUpdateRequest replaces the schedule and/or the workflow of a task
+type TaskUpdateRequest struct {
+	Workflow *wmap.WorkflowMap `json:"workflow,omitempty"`
+	Schedule *Schedule         `json:"schedule,omitempty"`
+}
+
+func (tr *TaskUpdateRequest) UnmarshalJSON(data []byte) error {
+	t := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	for k, v := range t {
+		switch k {
+		case "workflow":
+			if err := json.Unmarshal(v, &(tr.Workflow)); err != nil {
+				return err
+			}
+		case "schedule":
+			if err := json.Unmarshal(v, &(tr.Schedule)); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("Unrecognized key '%v' in task update request", k)
+		}
+	}
+	return nil
+}
+
 // Function used to create a task according to content (1st parameter)
 // . Content can be retrieved from a configuration file or a HTTP REST request body
 // . Mode is used to specify if the created task should start right away or not
@@ -228,7 +698,7 @@ func CreateTaskFromContent(body io.ReadCloser,
 		return nil, err
 	}
 
-	sch, err := makeSchedule(*tr.Schedule)
+	sch, err := MakeSchedule(*tr.Schedule)
 	if err != nil {
 		return nil, err
 	}
@@ -252,6 +722,51 @@ func CreateTaskFromContent(body io.ReadCloser,
 		opts = append(opts, OptionStopOnFailure(tr.MaxFailures))
 	}
 
+	if tr.CollectPolicy != "" {
+		cp, err := ParseCollectPolicy(tr.CollectPolicy)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, OptionCollectPolicy(cp))
+	}
+
+	if tr.FailurePolicy != nil {
+		opts = append(opts, OptionFailurePolicy(*tr.FailurePolicy))
+	}
+
+	if tr.Catchup != nil {
+		if err := tr.Catchup.Validate(); err != nil {
+			return nil, err
+		}
+		opts = append(opts, OptionCatchupPolicy(*tr.Catchup))
+	}
+
+	if tr.Priority != "" {
+		p, err := ParseTaskPriority(tr.Priority)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, OptionPriority(p))
+	}
+
+	if tr.Overlap != nil {
+		if err := tr.Overlap.Validate(); err != nil {
+			return nil, err
+		}
+		opts = append(opts, OptionOverlapPolicy(*tr.Overlap))
+	}
+
+	if tr.LatencySLO != "" {
+		slo, err := time.ParseDuration(tr.LatencySLO)
+		if err != nil {
+			return nil, err
+		}
+		if slo < 0 {
+			return nil, ErrNegativeLatencySLO
+		}
+		opts = append(opts, OptionLatencySLO(slo))
+	}
+
 	if mode == nil {
 		mode = &tr.Start
 	}
@@ -291,7 +806,7 @@ func UnmarshalBody(in interface{}, body io.ReadCloser) (int, error) {
 }
 
 func validateTaskRequest(tr *TaskCreationRequest) error {
-	if tr.Schedule == nil || *tr.Schedule == (Schedule{}) {
+	if tr.Schedule == nil || reflect.DeepEqual(*tr.Schedule, Schedule{}) {
 		return fmt.Errorf("Task must include a schedule, and the schedule must not be empty")
 	}
 
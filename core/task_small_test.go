@@ -0,0 +1,158 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseCollectPolicy(t *testing.T) {
+	Convey("Empty collect policy", t, func() {
+		cp, err := ParseCollectPolicy("")
+		So(err, ShouldBeNil)
+		So(cp, ShouldEqual, CollectAllOrNothing)
+	})
+	Convey("All-or-nothing collect policy", t, func() {
+		cp, err := ParseCollectPolicy("all-or-nothing")
+		So(err, ShouldBeNil)
+		So(cp, ShouldEqual, CollectAllOrNothing)
+		So(cp.String(), ShouldEqual, "all-or-nothing")
+	})
+	Convey("Best-effort collect policy", t, func() {
+		cp, err := ParseCollectPolicy("best-effort")
+		So(err, ShouldBeNil)
+		So(cp, ShouldEqual, CollectBestEffort)
+		So(cp.String(), ShouldEqual, "best-effort")
+	})
+	Convey("Unknown collect policy", t, func() {
+		_, err := ParseCollectPolicy("most-of-it")
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, ErrUnknownCollectPolicy.Error())
+	})
+}
+
+func TestTaskCreationRequestCollectPolicy(t *testing.T) {
+	Convey("Task creation request with a collect policy", t, func() {
+		tr := TaskCreationRequest{}
+		err := json.Unmarshal([]byte(`{"collect-policy": "best-effort"}`), &tr)
+		So(err, ShouldBeNil)
+		So(tr.CollectPolicy, ShouldEqual, "best-effort")
+	})
+	Convey("Task creation request with an invalid collect policy", t, func() {
+		tr := TaskCreationRequest{}
+		err := json.Unmarshal([]byte(`{"collect-policy": 1}`), &tr)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "collect-policy")
+	})
+}
+
+func TestTaskCreationRequestFailurePolicy(t *testing.T) {
+	Convey("Task creation request with a failure policy", t, func() {
+		var tr TaskCreationRequest
+		err := json.Unmarshal([]byte(`{"failure-policy": {"backoff": true, "max-backoff": 8, "disable-after": 100}}`), &tr)
+		So(err, ShouldBeNil)
+		So(tr.FailurePolicy, ShouldNotBeNil)
+		So(*tr.FailurePolicy, ShouldResemble, FailurePolicy{Backoff: true, MaxBackoff: 8, DisableAfter: 100})
+		So(tr.FailurePolicy.MaxBackoffFactor(), ShouldEqual, 8)
+	})
+	Convey("Failure policy without a max backoff", t, func() {
+		So(FailurePolicy{Backoff: true}.MaxBackoffFactor(), ShouldEqual, DefaultMaxBackoff)
+	})
+	Convey("Task creation request with a bad failure policy", t, func() {
+		var tr TaskCreationRequest
+		err := json.Unmarshal([]byte(`{"failure-policy": "backoff"}`), &tr)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "failure-policy")
+	})
+}
+
+func TestTaskCreationRequestCatchupPolicy(t *testing.T) {
+	Convey("Task creation request with a catch-up policy", t, func() {
+		var tr TaskCreationRequest
+		err := json.Unmarshal([]byte(`{"catchup": {"mode": "run-all", "max-runs": 5}}`), &tr)
+		So(err, ShouldBeNil)
+		So(tr.Catchup, ShouldNotBeNil)
+		So(*tr.Catchup, ShouldResemble, CatchupPolicy{Mode: CatchupRunAll, MaxRuns: 5})
+		So(tr.Catchup.Validate(), ShouldBeNil)
+	})
+	Convey("Catch-up policy runs", t, func() {
+		So(CatchupPolicy{}.Runs(3), ShouldEqual, 0)
+		So(CatchupPolicy{Mode: CatchupSkip}.Runs(3), ShouldEqual, 0)
+		So(CatchupPolicy{Mode: CatchupRunOnce}.Runs(0), ShouldEqual, 0)
+		So(CatchupPolicy{Mode: CatchupRunOnce}.Runs(3), ShouldEqual, 1)
+		So(CatchupPolicy{Mode: CatchupRunAll, MaxRuns: 5}.Runs(3), ShouldEqual, 3)
+		So(CatchupPolicy{Mode: CatchupRunAll, MaxRuns: 5}.Runs(30), ShouldEqual, 5)
+		So(CatchupPolicy{Mode: CatchupRunAll}.Runs(30), ShouldEqual, DefaultMaxCatchupRuns)
+	})
+	Convey("Catch-up policy with an unknown mode", t, func() {
+		err := CatchupPolicy{Mode: "run-some"}.Validate()
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, ErrUnknownCatchupMode.Error())
+	})
+}
+
+func TestParseTaskPriority(t *testing.T) {
+	Convey("Empty task priority is normal", t, func() {
+		p, err := ParseTaskPriority("")
+		So(err, ShouldBeNil)
+		So(p, ShouldEqual, PriorityNormal)
+	})
+	Convey("Known task priorities are parsed", t, func() {
+		for p, name := range TaskPriorityLookup {
+			parsed, err := ParseTaskPriority(name)
+			So(err, ShouldBeNil)
+			So(parsed, ShouldEqual, p)
+		}
+		So(PriorityLow, ShouldBeLessThan, PriorityNormal)
+		So(PriorityHigh, ShouldBeLessThan, PriorityCritical)
+	})
+	Convey("Unknown task priority returns an error", t, func() {
+		_, err := ParseTaskPriority("urgent")
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, ErrUnknownTaskPriority.Error())
+	})
+}
+
+func TestTaskCreationRequestOverlapPolicy(t *testing.T) {
+	Convey("Task creation request with an overlap policy", t, func() {
+		var tr TaskCreationRequest
+		err := json.Unmarshal([]byte(`{"overlap": {"mode": "concurrent", "max-concurrent": 2}}`), &tr)
+		So(err, ShouldBeNil)
+		So(tr.Overlap, ShouldNotBeNil)
+		So(*tr.Overlap, ShouldResemble, OverlapPolicy{Mode: OverlapConcurrent, MaxConcurrent: 2})
+		So(tr.Overlap.Validate(), ShouldBeNil)
+	})
+	Convey("Overlap policy max runs", t, func() {
+		So(OverlapPolicy{}.MaxRuns(), ShouldEqual, 1)
+		So(OverlapPolicy{Mode: OverlapSkip, MaxConcurrent: 3}.MaxRuns(), ShouldEqual, 1)
+		So(OverlapPolicy{Mode: OverlapConcurrent, MaxConcurrent: 3}.MaxRuns(), ShouldEqual, 3)
+		So(OverlapPolicy{Mode: OverlapConcurrent}.MaxRuns(), ShouldEqual, DefaultMaxConcurrentRuns)
+	})
+	Convey("Overlap policy with an unknown mode", t, func() {
+		err := OverlapPolicy{Mode: "wait"}.Validate()
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, ErrUnknownOverlapMode.Error())
+	})
+}
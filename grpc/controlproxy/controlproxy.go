@@ -42,6 +42,27 @@ func getContext() context.Context {
 	return cd
 }
 
+// getDeadlineContext returns a context cancelled once MAX_CONNECTION_TIMEOUT
+// elapsed or the deadline of the job the call is made for passed, whichever
+// comes first. The deadline is carried to the remote control.
+func getDeadlineContext(deadline time.Time) (context.Context, context.CancelFunc) {
+	timeout := time.Now().Add(MAX_CONNECTION_TIMEOUT)
+	if deadline.IsZero() || timeout.Before(deadline) {
+		deadline = timeout
+	}
+	return context.WithDeadline(context.Background(), deadline)
+}
+
+// callError returns the error of a call to the remote control, which is
+// core.ErrDeadlineExceeded if the call failed once the deadline of its job
+// passed
+func callError(err error, deadline time.Time) error {
+	if !deadline.IsZero() && !time.Now().Before(deadline) {
+		return core.ErrDeadlineExceeded
+	}
+	return err
+}
+
 // Implements managesMetrics interface provided by scheduler and
 // proxies those calls to the grpc client.
 type ControlProxy struct {
@@ -61,7 +82,8 @@ func (c ControlProxy) PublishMetrics(metrics []core.Metric,
 	config map[string]ctypes.ConfigValue,
 	taskId string,
 	pluginName string,
-	pluginVersion int) []error {
+	pluginVersion int,
+	deadline time.Time) []error {
 
 	req := &rpc.PubProcMetricsRequest{
 		Metrics:       common.NewMetrics(metrics),
@@ -70,10 +92,12 @@ func (c ControlProxy) PublishMetrics(metrics []core.Metric,
 		TaskId:        taskId,
 		Config:        common.ToConfigMap(config),
 	}
-	reply, err := c.Client.PublishMetrics(getContext(), req)
+	ctx, cancel := getDeadlineContext(deadline)
+	defer cancel()
+	reply, err := c.Client.PublishMetrics(ctx, req)
 	var errs []error
 	if err != nil {
-		errs = append(errs, err)
+		errs = append(errs, callError(err, deadline))
 		return errs
 	}
 	rerrs := replyErrorsToErrors(reply.Errors)
@@ -85,7 +109,8 @@ func (c ControlProxy) ProcessMetrics(metrics []core.Metric,
 	config map[string]ctypes.ConfigValue,
 	taskId string,
 	pluginName string,
-	pluginVersion int) ([]core.Metric, []error) {
+	pluginVersion int,
+	deadline time.Time) ([]core.Metric, []error) {
 	req := &rpc.PubProcMetricsRequest{
 		Metrics:       common.NewMetrics(metrics),
 		PluginName:    pluginName,
@@ -93,10 +118,12 @@ func (c ControlProxy) ProcessMetrics(metrics []core.Metric,
 		TaskId:        taskId,
 		Config:        common.ToConfigMap(config),
 	}
-	reply, err := c.Client.ProcessMetrics(getContext(), req)
+	ctx, cancel := getDeadlineContext(deadline)
+	defer cancel()
+	reply, err := c.Client.ProcessMetrics(ctx, req)
 	var errs []error
 	if err != nil {
-		errs = append(errs, err)
+		errs = append(errs, callError(err, deadline))
 		return nil, errs
 	}
 	rerrs := replyErrorsToErrors(reply.Errors)
@@ -104,7 +131,7 @@ func (c ControlProxy) ProcessMetrics(metrics []core.Metric,
 	return common.ToCoreMetrics(reply.Metrics), errs
 }
 
-func (c ControlProxy) CollectMetrics(taskID string, AllTags map[string]map[string]string) ([]core.Metric, []error) {
+func (c ControlProxy) CollectMetrics(taskID string, AllTags map[string]map[string]string, deadline time.Time) ([]core.Metric, []error) {
 	var allTags map[string]*rpc.Map
 	for k, v := range AllTags {
 		tags := &rpc.Map{}
@@ -121,19 +148,22 @@ func (c ControlProxy) CollectMetrics(taskID string, AllTags map[string]map[strin
 		TaskID:  taskID,
 		AllTags: allTags,
 	}
-	reply, err := c.Client.CollectMetrics(getContext(), req)
+	ctx, cancel := getDeadlineContext(deadline)
+	defer cancel()
+	reply, err := c.Client.CollectMetrics(ctx, req)
 	var errs []error
 	if err != nil {
-		errs = append(errs, err)
+		errs = append(errs, callError(err, deadline))
 		return nil, errs
 	}
-	rerrs := replyErrorsToErrors(reply.Errors)
-	if len(rerrs) > 0 {
-		errs = append(errs, rerrs...)
-		return nil, errs
+	// the metrics of the plugins which succeeded are returned along with
+	// the errors of the plugins which failed, which keep the fields naming
+	// the plugin
+	for _, e := range common.ConvertSnapErrors(reply.Errors) {
+		errs = append(errs, e)
 	}
 	metrics := common.ToCoreMetrics(reply.Metrics)
-	return metrics, nil
+	return metrics, errs
 }
 
 func (c ControlProxy) ValidateDeps(mts []core.RequestedMetric, plugins []core.SubscribedPlugin, _ *cdata.ConfigDataTree) []serror.SnapError {
@@ -28,6 +28,7 @@ import (
 
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/ctypes"
+	"github.com/intelsdi-x/snap/core/serror"
 	"github.com/intelsdi-x/snap/grpc/common"
 	"github.com/intelsdi-x/snap/grpc/controlproxy/rpc"
 
@@ -99,20 +100,29 @@ func (mc mockClient) UnsubscribeDeps(ctx context.Context, in *rpc.UnsubscribeDep
 func TestPublishMetrics(t *testing.T) {
 	Convey("RPC client errors", t, func() {
 		proxy := ControlProxy{Client: mockClient{RpcErr: true}}
-		errs := proxy.PublishMetrics([]core.Metric{}, map[string]ctypes.ConfigValue{}, "", "fake", 1)
+		errs := proxy.PublishMetrics([]core.Metric{}, map[string]ctypes.ConfigValue{}, "", "fake", 1, time.Time{})
 
 		Convey("So the error should be passed through", func() {
 			So(errs[0].Error(), ShouldResemble, rpcErr.Error())
 		})
 	})
 
+	Convey("RPC client errors once the deadline passed", t, func() {
+		proxy := ControlProxy{Client: mockClient{RpcErr: true}}
+		errs := proxy.PublishMetrics([]core.Metric{}, map[string]ctypes.ConfigValue{}, "", "fake", 1, time.Now().Add(-time.Second))
+
+		Convey("So the error should be a deadline exceeded error", func() {
+			So(errs[0], ShouldEqual, core.ErrDeadlineExceeded)
+		})
+	})
+
 	Convey("Control.Publish returns an error", t, func() {
 		reply := &rpc.ErrorReply{
 			Errors: []string{"errors"},
 		}
 
 		proxy := ControlProxy{Client: mockClient{PublishReply: reply}}
-		errs := proxy.PublishMetrics([]core.Metric{}, map[string]ctypes.ConfigValue{}, "", "fake", 1)
+		errs := proxy.PublishMetrics([]core.Metric{}, map[string]ctypes.ConfigValue{}, "", "fake", 1, time.Time{})
 
 		Convey("So err should not be nil", func() {
 			So(errs, ShouldNotBeNil)
@@ -127,7 +137,7 @@ func TestPublishMetrics(t *testing.T) {
 		reply := &rpc.ErrorReply{Errors: []string{}}
 
 		proxy := ControlProxy{Client: mockClient{PublishReply: reply}}
-		errs := proxy.PublishMetrics([]core.Metric{}, map[string]ctypes.ConfigValue{}, "", "fake", 1)
+		errs := proxy.PublishMetrics([]core.Metric{}, map[string]ctypes.ConfigValue{}, "", "fake", 1, time.Time{})
 
 		Convey("So publishing should not error", func() {
 			So(len(errs), ShouldEqual, 0)
@@ -138,7 +148,7 @@ func TestPublishMetrics(t *testing.T) {
 func TestProcessMetrics(t *testing.T) {
 	Convey("RPC client errors", t, func() {
 		proxy := ControlProxy{Client: mockClient{RpcErr: true}}
-		_, errs := proxy.ProcessMetrics([]core.Metric{}, map[string]ctypes.ConfigValue{}, "", "fake", 1)
+		_, errs := proxy.ProcessMetrics([]core.Metric{}, map[string]ctypes.ConfigValue{}, "", "fake", 1, time.Time{})
 
 		Convey("So the error should be passed through", func() {
 			So(errs[0].Error(), ShouldResemble, rpcErr.Error())
@@ -152,7 +162,7 @@ func TestProcessMetrics(t *testing.T) {
 		}
 
 		proxy := ControlProxy{Client: mockClient{ProcessReply: reply}}
-		_, errs := proxy.ProcessMetrics([]core.Metric{}, map[string]ctypes.ConfigValue{}, "", "", 1)
+		_, errs := proxy.ProcessMetrics([]core.Metric{}, map[string]ctypes.ConfigValue{}, "", "", 1, time.Time{})
 
 		Convey("So errs should not be nil", func() {
 			So(errs, ShouldNotBeNil)
@@ -174,7 +184,7 @@ func TestProcessMetrics(t *testing.T) {
 		}
 
 		proxy := ControlProxy{Client: mockClient{ProcessReply: reply}}
-		_, errs := proxy.ProcessMetrics([]core.Metric{}, map[string]ctypes.ConfigValue{}, "", "", 1)
+		_, errs := proxy.ProcessMetrics([]core.Metric{}, map[string]ctypes.ConfigValue{}, "", "", 1, time.Time{})
 
 		Convey("So len of errs should be 0", func() {
 			So(len(errs), ShouldEqual, 0)
@@ -186,7 +196,7 @@ func TestProcessMetrics(t *testing.T) {
 func TestCollectMetrics(t *testing.T) {
 	Convey("RPC client errors", t, func() {
 		proxy := ControlProxy{Client: mockClient{RpcErr: true}}
-		_, errs := proxy.CollectMetrics("", map[string]map[string]string{})
+		_, errs := proxy.CollectMetrics("", map[string]map[string]string{}, time.Time{})
 
 		Convey("So the error should be passed through", func() {
 			So(errs[0].Error(), ShouldResemble, rpcErr.Error())
@@ -196,11 +206,14 @@ func TestCollectMetrics(t *testing.T) {
 	Convey("Control.CollectMetrics returns an error", t, func() {
 		reply := &rpc.CollectMetricsResponse{
 			Metrics: nil,
-			Errors:  []string{"error in collect"},
+			Errors: []*common.SnapError{&common.SnapError{
+				ErrorString: "error in collect",
+				ErrorFields: map[string]string{"plugin-name": "mock", "plugin-version": "2"},
+			}},
 		}
 
 		proxy := ControlProxy{Client: mockClient{CollectReply: reply}}
-		_, errs := proxy.CollectMetrics("", map[string]map[string]string{})
+		_, errs := proxy.CollectMetrics("", map[string]map[string]string{}, time.Time{})
 
 		Convey("So len of errs should be 1", func() {
 			So(len(errs), ShouldEqual, 1)
@@ -209,6 +222,13 @@ func TestCollectMetrics(t *testing.T) {
 		Convey("So error should contain the string 'error in collect'", func() {
 			So(errs[0].Error(), ShouldResemble, "error in collect")
 		})
+
+		Convey("So error should keep the fields naming the plugin", func() {
+			serr, ok := errs[0].(serror.SnapError)
+			So(ok, ShouldBeTrue)
+			So(serr.Fields()["plugin-name"], ShouldEqual, "mock")
+			So(serr.Fields()["plugin-version"], ShouldEqual, "2")
+		})
 	})
 
 	Convey("Control.CollectMetrics returns sucessfully", t, func() {
@@ -224,7 +244,7 @@ func TestCollectMetrics(t *testing.T) {
 		}
 
 		proxy := ControlProxy{Client: mockClient{CollectReply: reply}}
-		mts, errs := proxy.CollectMetrics("", map[string]map[string]string{})
+		mts, errs := proxy.CollectMetrics("", map[string]map[string]string{}, time.Time{})
 
 		Convey("So len of errs should be 0", func() {
 			So(len(errs), ShouldEqual, 0)
@@ -279,8 +279,8 @@ func (m *CollectMetricsRequest) GetAllTags() map[string]*Map {
 }
 
 type CollectMetricsResponse struct {
-	Metrics []*common.Metric `protobuf:"bytes,1,rep,name=Metrics,json=metrics" json:"Metrics,omitempty"`
-	Errors  []string         `protobuf:"bytes,2,rep,name=Errors,json=errors" json:"Errors,omitempty"`
+	Metrics []*common.Metric    `protobuf:"bytes,1,rep,name=Metrics,json=metrics" json:"Metrics,omitempty"`
+	Errors  []*common.SnapError `protobuf:"bytes,2,rep,name=Errors,json=errors" json:"Errors,omitempty"`
 }
 
 func (m *CollectMetricsResponse) Reset()                    { *m = CollectMetricsResponse{} }
@@ -295,6 +295,13 @@ func (m *CollectMetricsResponse) GetMetrics() []*common.Metric {
 	return nil
 }
 
+func (m *CollectMetricsResponse) GetErrors() []*common.SnapError {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
 type ArrString struct {
 	S []*common.NamespaceElement `protobuf:"bytes,1,rep,name=S,json=s" json:"S,omitempty"`
 }
@@ -616,52 +623,52 @@ func init() {
 }
 
 var fileDescriptor0 = []byte{
-	// 748 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xac, 0x55, 0xdd, 0x4e, 0x1a, 0x41,
-	0x14, 0x66, 0x5d, 0x01, 0x39, 0x08, 0xd6, 0x11, 0x29, 0x6e, 0x13, 0x43, 0x36, 0xa6, 0x62, 0xd2,
-	0x42, 0x8b, 0x37, 0x4d, 0x2f, 0x34, 0x54, 0x88, 0x6d, 0x0c, 0x0d, 0x59, 0xac, 0x4d, 0x7a, 0x37,
-	0x2c, 0x53, 0xdc, 0xb8, 0xec, 0x4c, 0x67, 0x66, 0x8d, 0xbc, 0x41, 0x2f, 0xfb, 0x38, 0x7d, 0x86,
-	0x3e, 0x55, 0xb3, 0x3b, 0xbb, 0xc8, 0xe2, 0xda, 0x56, 0xd3, 0x2b, 0xf6, 0xfc, 0xcc, 0x77, 0xbe,
-	0xef, 0xcc, 0x9c, 0x03, 0x1c, 0x4d, 0x1c, 0x79, 0xe9, 0x8f, 0x9a, 0x36, 0x9d, 0xb6, 0x1c, 0x4f,
-	0x12, 0x57, 0x8c, 0x9d, 0x97, 0x37, 0x2d, 0xe1, 0x61, 0xd6, 0x9a, 0x70, 0x66, 0xb7, 0x6c, 0xea,
-	0x49, 0x4e, 0x5d, 0xc6, 0xe9, 0xcd, 0xac, 0xb5, 0xe0, 0x68, 0x32, 0x4e, 0x25, 0x45, 0x3a, 0x67,
-	0xb6, 0x71, 0xf8, 0x77, 0x90, 0xe9, 0x94, 0x7a, 0xd1, 0x8f, 0x3a, 0x69, 0x96, 0xa0, 0x38, 0x24,
-	0x9c, 0x53, 0x6e, 0x11, 0xe6, 0xce, 0xcc, 0x5f, 0x1a, 0x6c, 0x0f, 0xfc, 0xd1, 0x80, 0x53, 0xbb,
-	0x4f, 0x24, 0x77, 0x6c, 0x61, 0x91, 0x6f, 0x3e, 0x11, 0x12, 0x35, 0x20, 0x1f, 0x79, 0x6a, 0x5a,
-	0x5d, 0x6f, 0x14, 0xdb, 0xe5, 0x66, 0x04, 0xa4, 0xdc, 0x56, 0x7e, 0xaa, 0xc2, 0x68, 0x17, 0x60,
-	0xe0, 0xfa, 0x13, 0xc7, 0xfb, 0x88, 0xa7, 0xa4, 0xb6, 0x52, 0xd7, 0x1a, 0x05, 0x0b, 0xd8, 0xdc,
-	0x83, 0xf6, 0xa0, 0xa4, 0xe2, 0x17, 0x84, 0x0b, 0x87, 0x7a, 0x35, 0xbd, 0xae, 0x35, 0x74, 0xab,
-	0xc4, 0x16, 0x9d, 0xe8, 0x00, 0x72, 0x27, 0xd4, 0xfb, 0xea, 0x4c, 0x6a, 0xab, 0x75, 0xad, 0x51,
-	0x6c, 0x6f, 0xc6, 0xe5, 0x94, 0xb7, 0x8f, 0x99, 0x95, 0xb3, 0xc3, 0x4f, 0x54, 0x85, 0xdc, 0x39,
-	0x16, 0x57, 0x1f, 0xc6, 0xb5, 0x6c, 0x58, 0x2c, 0x27, 0x43, 0xcb, 0xdc, 0x03, 0xe8, 0xcd, 0xa5,
-	0x05, 0x59, 0xa1, 0xa5, 0xf8, 0x17, 0xac, 0x5c, 0x28, 0x5b, 0x98, 0x9f, 0x61, 0x2b, 0x90, 0x4b,
-	0x84, 0x98, 0x2b, 0x0e, 0xd2, 0xff, 0x5d, 0xef, 0x2d, 0xf0, 0x4a, 0x02, 0x58, 0xc0, 0xd6, 0x05,
-	0x76, 0x9d, 0x31, 0x96, 0xa4, 0x4b, 0xd8, 0x23, 0x1a, 0xd9, 0x86, 0xbc, 0x6a, 0x94, 0x42, 0x2e,
-	0xb6, 0x6b, 0x71, 0xe6, 0xd0, 0x1f, 0x09, 0x9b, 0x3b, 0x23, 0x32, 0x56, 0x09, 0x56, 0x5e, 0x35,
-	0x4f, 0x98, 0x47, 0xb0, 0x99, 0x2c, 0x1a, 0x68, 0x39, 0x48, 0x48, 0x5f, 0xe8, 0xe5, 0xd0, 0xc3,
-	0x4c, 0xb5, 0x28, 0x26, 0xfd, 0x43, 0x83, 0xca, 0x1c, 0x7d, 0x91, 0xf6, 0x0b, 0x28, 0x44, 0x9f,
-	0x64, 0x7c, 0x0f, 0xf1, 0x02, 0x8f, 0x13, 0x1e, 0x43, 0x7d, 0xe1, 0x1a, 0xf5, 0xc4, 0x35, 0x1e,
-	0x03, 0x5a, 0x62, 0xf4, 0x40, 0x4d, 0xaf, 0xa0, 0xfa, 0xc9, 0x13, 0x69, 0xa2, 0x6e, 0x4b, 0x6a,
-	0x89, 0x92, 0x1d, 0xa8, 0xdc, 0x39, 0xf1, 0xc0, 0xa2, 0x4d, 0xd0, 0xfb, 0x98, 0xa1, 0x7d, 0xc8,
-	0xf7, 0x3c, 0xc9, 0x1d, 0x12, 0x1f, 0x29, 0x35, 0x39, 0xb3, 0x9b, 0x7d, 0xcc, 0x02, 0xf7, 0xcc,
-	0xca, 0x13, 0x15, 0x35, 0xdb, 0xb0, 0x16, 0x3b, 0xd1, 0x13, 0xd0, 0xcf, 0xc8, 0x2c, 0xe2, 0xa4,
-	0x5f, 0x91, 0x19, 0xaa, 0x40, 0xf6, 0x02, 0xbb, 0x7e, 0x3c, 0x4e, 0xd9, 0xeb, 0xc0, 0x30, 0x7f,
-	0x6a, 0xb0, 0x7d, 0x42, 0x5d, 0x97, 0xd8, 0x72, 0x69, 0x5a, 0x63, 0x61, 0xdd, 0x84, 0xb0, 0x2e,
-	0xea, 0x40, 0xbe, 0xe3, 0xba, 0xe7, 0x78, 0x12, 0xdf, 0xcb, 0x7e, 0x48, 0x27, 0x15, 0xa4, 0x19,
-	0x65, 0x46, 0x44, 0xb1, 0xb2, 0x8c, 0x2e, 0xac, 0x2f, 0x06, 0x02, 0xb2, 0x57, 0x49, 0xb2, 0xbb,
-	0xa0, 0xf8, 0x85, 0x64, 0x8b, 0xed, 0xb5, 0x58, 0x71, 0x44, 0xfb, 0xed, 0xca, 0x1b, 0xcd, 0xfc,
-	0x02, 0xd5, 0xe5, 0xa2, 0x82, 0x51, 0x4f, 0x90, 0xff, 0x30, 0x78, 0x87, 0x50, 0xe8, 0x70, 0x3e,
-	0x94, 0xdc, 0xf1, 0x26, 0xe8, 0x39, 0x68, 0xc3, 0x08, 0x68, 0xfe, 0x06, 0x83, 0x35, 0x24, 0x18,
-	0xb6, 0x49, 0xcf, 0x25, 0x53, 0xe2, 0x49, 0x4b, 0x13, 0xe6, 0x6b, 0xd8, 0x39, 0x25, 0xb2, 0xe3,
-	0x4b, 0x3a, 0x76, 0x84, 0x4d, 0xaf, 0x09, 0x1f, 0x60, 0x79, 0x19, 0xdd, 0x7b, 0x05, 0xb2, 0xa1,
-	0x15, 0xad, 0x8e, 0x2c, 0x0b, 0x8c, 0xf6, 0xf7, 0x55, 0x28, 0x29, 0x4e, 0x7d, 0xec, 0xe1, 0x09,
-	0xe1, 0xe8, 0x0c, 0xca, 0x49, 0x55, 0xc8, 0xb8, 0xbf, 0xbf, 0xc6, 0xb3, 0xd4, 0x98, 0x6a, 0x83,
-	0x99, 0x41, 0xc7, 0x50, 0x1e, 0xf8, 0x23, 0xd7, 0x11, 0x97, 0x49, 0xb0, 0xd4, 0xfd, 0x6c, 0x6c,
-	0x84, 0xb1, 0xdb, 0x7d, 0x67, 0x66, 0xd0, 0x7b, 0x28, 0x27, 0x37, 0xdb, 0x1f, 0x01, 0x6a, 0x2a,
-	0x76, 0x77, 0x15, 0x9a, 0x19, 0xf4, 0x0e, 0xd6, 0x17, 0xb7, 0x0a, 0x52, 0xb9, 0x29, 0xdb, 0xcd,
-	0xa8, 0xa6, 0x44, 0x14, 0x46, 0x0f, 0x4a, 0x89, 0x31, 0x46, 0x3b, 0x61, 0x6a, 0xda, 0xb2, 0x31,
-	0x9e, 0xa6, 0x85, 0x14, 0xcc, 0x19, 0x6c, 0x2c, 0x8d, 0x26, 0x52, 0x7d, 0x4c, 0x1f, 0x71, 0x63,
-	0x27, 0x3d, 0xa8, 0xc0, 0x4e, 0xa1, 0x92, 0x76, 0xe9, 0xa8, 0x14, 0xbf, 0x94, 0xde, 0x94, 0xc9,
-	0x99, 0xb1, 0x1b, 0x62, 0xdc, 0xfb, 0x3c, 0xcc, 0xcc, 0x28, 0x17, 0xfe, 0x9b, 0x1e, 0xfe, 0x0e,
-	0x00, 0x00, 0xff, 0xff, 0x1b, 0x5c, 0xfe, 0x59, 0xc9, 0x07, 0x00, 0x00,
+	// 752 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x55, 0xdd, 0x4e, 0xe3, 0x46,
+	0x14, 0x8e, 0x31, 0x49, 0xc8, 0x09, 0x09, 0x65, 0x08, 0xa9, 0x71, 0x25, 0x14, 0x59, 0xa8, 0x04,
+	0xa9, 0x4d, 0xda, 0x70, 0x53, 0xf5, 0x02, 0x94, 0x92, 0x88, 0x56, 0x28, 0x55, 0xe4, 0xb0, 0xec,
+	0xf5, 0xc4, 0x99, 0x0d, 0x16, 0xb6, 0x67, 0x76, 0x66, 0x8c, 0xc8, 0x1b, 0xec, 0xe5, 0x3e, 0xce,
+	0x3e, 0xc3, 0x3e, 0xd5, 0xca, 0x1e, 0x3b, 0xc4, 0xc1, 0xb0, 0x0b, 0x57, 0xf1, 0xf9, 0x99, 0xef,
+	0x7c, 0xdf, 0x99, 0x39, 0x27, 0x70, 0x36, 0x77, 0xe5, 0x6d, 0x38, 0xed, 0x38, 0xd4, 0xef, 0xba,
+	0x81, 0x24, 0x9e, 0x98, 0xb9, 0xbf, 0x3f, 0x74, 0x45, 0x80, 0x59, 0x77, 0xce, 0x99, 0xd3, 0x75,
+	0x68, 0x20, 0x39, 0xf5, 0x18, 0xa7, 0x0f, 0x8b, 0xee, 0x8a, 0xa3, 0xc3, 0x38, 0x95, 0x14, 0xe9,
+	0x9c, 0x39, 0xe6, 0xe9, 0xf7, 0x41, 0x7c, 0x9f, 0x06, 0xc9, 0x8f, 0x3a, 0x69, 0xd5, 0xa0, 0x3a,
+	0x21, 0x9c, 0x53, 0x6e, 0x13, 0xe6, 0x2d, 0xac, 0xaf, 0x1a, 0xec, 0x8f, 0xc3, 0xe9, 0x98, 0x53,
+	0x67, 0x44, 0x24, 0x77, 0x1d, 0x61, 0x93, 0x8f, 0x21, 0x11, 0x12, 0xb5, 0xa1, 0x9c, 0x78, 0x0c,
+	0xad, 0xa5, 0xb7, 0xab, 0xbd, 0x7a, 0x27, 0x01, 0x52, 0x6e, 0xbb, 0xec, 0xab, 0x30, 0x3a, 0x04,
+	0x18, 0x7b, 0xe1, 0xdc, 0x0d, 0xfe, 0xc7, 0x3e, 0x31, 0x36, 0x5a, 0x5a, 0xbb, 0x62, 0x03, 0x5b,
+	0x7a, 0xd0, 0x11, 0xd4, 0x54, 0xfc, 0x86, 0x70, 0xe1, 0xd2, 0xc0, 0xd0, 0x5b, 0x5a, 0x5b, 0xb7,
+	0x6b, 0x6c, 0xd5, 0x89, 0x4e, 0xa0, 0x74, 0x41, 0x83, 0x0f, 0xee, 0xdc, 0xd8, 0x6c, 0x69, 0xed,
+	0x6a, 0x6f, 0x37, 0x2d, 0xa7, 0xbc, 0x23, 0xcc, 0xec, 0x92, 0x13, 0x7f, 0xa2, 0x26, 0x94, 0xae,
+	0xb1, 0xb8, 0xfb, 0x6f, 0x66, 0x14, 0xe3, 0x62, 0x25, 0x19, 0x5b, 0xd6, 0x11, 0xc0, 0x70, 0x29,
+	0x2d, 0xca, 0x8a, 0x2d, 0xc5, 0xbf, 0x62, 0x97, 0x62, 0xd9, 0xc2, 0x7a, 0x0f, 0x7b, 0x91, 0x5c,
+	0x22, 0xc4, 0x52, 0x71, 0x94, 0xfe, 0xe3, 0x7a, 0x1f, 0x81, 0x37, 0x32, 0xc0, 0x02, 0xf6, 0x6e,
+	0xb0, 0xe7, 0xce, 0xb0, 0x24, 0x03, 0xc2, 0xde, 0xd0, 0xc8, 0x1e, 0x94, 0x55, 0xa3, 0x14, 0x72,
+	0xb5, 0x67, 0xa4, 0x99, 0x93, 0x70, 0x2a, 0x1c, 0xee, 0x4e, 0xc9, 0x4c, 0x25, 0xd8, 0x65, 0xd5,
+	0x3c, 0x61, 0x9d, 0xc1, 0x6e, 0xb6, 0x68, 0xa4, 0xe5, 0x24, 0x23, 0x7d, 0xa5, 0x97, 0x93, 0x00,
+	0x33, 0xd5, 0xa2, 0x94, 0xf4, 0x67, 0x0d, 0x1a, 0x4b, 0xf4, 0x55, 0xda, 0xbf, 0x41, 0x25, 0xf9,
+	0x24, 0xb3, 0x67, 0x88, 0x57, 0x78, 0x9a, 0xf0, 0x16, 0xea, 0x2b, 0xd7, 0xa8, 0x67, 0xae, 0xf1,
+	0x1c, 0xd0, 0x1a, 0xa3, 0x57, 0x6a, 0xfa, 0x03, 0x9a, 0xef, 0x02, 0x91, 0x27, 0xea, 0xb1, 0xa4,
+	0x96, 0x29, 0xd9, 0x87, 0xc6, 0x93, 0x13, 0xaf, 0x2c, 0xda, 0x01, 0x7d, 0x84, 0x19, 0x3a, 0x86,
+	0xf2, 0x30, 0x90, 0xdc, 0x25, 0xe9, 0x91, 0x5a, 0x87, 0x33, 0xa7, 0x33, 0xc2, 0x2c, 0x72, 0x2f,
+	0xec, 0x32, 0x51, 0x51, 0xab, 0x07, 0x5b, 0xa9, 0x13, 0xfd, 0x04, 0xfa, 0x15, 0x59, 0x24, 0x9c,
+	0xf4, 0x3b, 0xb2, 0x40, 0x0d, 0x28, 0xde, 0x60, 0x2f, 0x4c, 0xc7, 0xa9, 0x78, 0x1f, 0x19, 0xd6,
+	0x17, 0x0d, 0xf6, 0x2f, 0xa8, 0xe7, 0x11, 0x47, 0xae, 0x4d, 0x6b, 0x2a, 0x6c, 0x90, 0x11, 0x36,
+	0x40, 0x7d, 0x28, 0xf7, 0x3d, 0xef, 0x1a, 0xcf, 0xd3, 0x7b, 0x39, 0x8e, 0xe9, 0xe4, 0x82, 0x74,
+	0x92, 0xcc, 0x84, 0x28, 0x56, 0x96, 0x39, 0x80, 0xed, 0xd5, 0x40, 0x44, 0xf6, 0x2e, 0x4b, 0xf6,
+	0x10, 0x8a, 0xf7, 0x4b, 0xb2, 0xd5, 0xde, 0x56, 0xaa, 0x38, 0xa1, 0xfd, 0xf7, 0xc6, 0x5f, 0x9a,
+	0xe5, 0x43, 0x73, 0xbd, 0xa8, 0x60, 0x34, 0x10, 0xe4, 0x15, 0xf3, 0x71, 0x92, 0x19, 0xbc, 0x17,
+	0x6f, 0xe3, 0x14, 0x2a, 0x7d, 0xce, 0x27, 0x92, 0xbb, 0xc1, 0x1c, 0xfd, 0x0a, 0xda, 0xc4, 0xd0,
+	0xb2, 0xcf, 0x32, 0xda, 0x4c, 0x82, 0x61, 0x87, 0x0c, 0x3d, 0xe2, 0x93, 0x40, 0xda, 0x9a, 0xb0,
+	0xfe, 0x84, 0x83, 0x4b, 0x22, 0xfb, 0xa1, 0xa4, 0x33, 0x57, 0x38, 0xf4, 0x9e, 0xf0, 0x31, 0x96,
+	0xb7, 0xc9, 0x53, 0x68, 0x40, 0x31, 0xb6, 0x92, 0x6d, 0x52, 0x64, 0x91, 0xd1, 0xfb, 0xb4, 0x09,
+	0x35, 0x45, 0x73, 0x84, 0x03, 0x3c, 0x27, 0x1c, 0x5d, 0x41, 0x3d, 0x2b, 0x14, 0x99, 0xcf, 0xb7,
+	0xdc, 0xfc, 0x25, 0x37, 0xa6, 0x3a, 0x63, 0x15, 0xd0, 0x39, 0xd4, 0xc7, 0xe1, 0xd4, 0x73, 0xc5,
+	0x6d, 0x16, 0x2c, 0x77, 0x65, 0x9b, 0x3b, 0x71, 0xec, 0x71, 0x05, 0x5a, 0x05, 0xf4, 0x2f, 0xd4,
+	0xb3, 0xcb, 0xee, 0x45, 0x00, 0x43, 0xc5, 0x9e, 0x6e, 0x47, 0xab, 0x80, 0xfe, 0x81, 0xed, 0xd5,
+	0x45, 0x83, 0x54, 0x6e, 0xce, 0xc2, 0x33, 0x9b, 0x39, 0x11, 0x85, 0x31, 0x84, 0x5a, 0x66, 0xb2,
+	0xd1, 0x41, 0x9c, 0x9a, 0xb7, 0x7f, 0xcc, 0x9f, 0xf3, 0x42, 0x0a, 0xe6, 0x0a, 0x76, 0xd6, 0xa6,
+	0x15, 0xa9, 0x3e, 0xe6, 0x4f, 0xbd, 0x79, 0x90, 0x1f, 0x54, 0x60, 0x97, 0xd0, 0xc8, 0xbb, 0x74,
+	0x54, 0x4b, 0x5f, 0xca, 0xd0, 0x67, 0x72, 0x61, 0x1e, 0xc6, 0x18, 0xcf, 0x3e, 0x0f, 0xab, 0x30,
+	0x2d, 0xc5, 0x7f, 0xb0, 0xa7, 0xdf, 0x06, 0x00, 0x19, 0x54, 0x55, 0x7d, 0xdc, 0x07, 0x00, 0x00,
 }
@@ -172,7 +172,7 @@ func (c *Client) do(method, path string, ct contentType, body ...[]byte) (*rbody
 			}
 			return nil, fmt.Errorf("URL target is not available. %v", err)
 		}
-	case "PUT":
+	case "PUT", "PATCH":
 		var b *bytes.Reader
 		if len(body) == 0 {
 			b = bytes.NewReader([]byte{})
@@ -0,0 +1,147 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/mgmt/rest/rbody"
+)
+
+// GetSchedulerQueues retrieves the depth of the collect, process and publish
+// queues of the scheduler, in total and for each task priority, and the
+// utilization of their worker pools through an HTTP GET call. Otherwise, an
+// error is returned.
+func (c *Client) GetSchedulerQueues() *GetSchedulerQueuesResult {
+	resp, err := c.do("GET", "/scheduler/queues", ContentTypeJSON, nil)
+	if err != nil {
+		return &GetSchedulerQueuesResult{Err: err}
+	}
+	switch resp.Meta.Type {
+	case rbody.SchedulerQueuesReturnedType:
+		// Success
+		return &GetSchedulerQueuesResult{resp.Body.(*rbody.SchedulerQueuesReturned), nil}
+	case rbody.ErrorType:
+		return &GetSchedulerQueuesResult{Err: resp.Body.(*rbody.Error)}
+	default:
+		return &GetSchedulerQueuesResult{Err: ErrAPIResponseMetaType}
+	}
+}
+
+// GetSchedulerQueuesResult is the response from snap/client on a GetSchedulerQueues call.
+type GetSchedulerQueuesResult struct {
+	*rbody.SchedulerQueuesReturned
+	Err error
+}
+
+// ResizeSchedulerQueue changes the limit of the scheduler queue with the given
+// name and/or the size of its worker pool through an HTTP PUT call. A nil
+// limit or number of workers is left unchanged. Otherwise, an error is
+// returned.
+func (c *Client) ResizeSchedulerQueue(name string, limit, workers *uint) *ResizeSchedulerQueueResult {
+	rr := core.WorkQueueResizeRequest{
+		Limit:   limit,
+		Workers: workers,
+	}
+	j, err := json.Marshal(rr)
+	if err != nil {
+		return &ResizeSchedulerQueueResult{Err: err}
+	}
+	resp, err := c.do("PUT", fmt.Sprintf("/scheduler/queues/%v", name), ContentTypeJSON, j)
+	if err != nil {
+		return &ResizeSchedulerQueueResult{Err: err}
+	}
+	switch resp.Meta.Type {
+	case rbody.SchedulerQueueResizedType:
+		// Success
+		return &ResizeSchedulerQueueResult{resp.Body.(*rbody.SchedulerQueueResized), nil}
+	case rbody.ErrorType:
+		return &ResizeSchedulerQueueResult{Err: resp.Body.(*rbody.Error)}
+	default:
+		return &ResizeSchedulerQueueResult{Err: ErrAPIResponseMetaType}
+	}
+}
+
+// ResizeSchedulerQueueResult is the response from snap/client on a ResizeSchedulerQueue call.
+type ResizeSchedulerQueueResult struct {
+	*rbody.SchedulerQueueResized
+	Err error
+}
+
+// GetSchedulerSpool retrieves the spool holding the metrics the publish nodes
+// failed to publish and the entries it holds, those of the task with the given
+// id if it is not empty, through an HTTP GET call. Otherwise, an error is
+// returned.
+func (c *Client) GetSchedulerSpool(taskID string) *GetSchedulerSpoolResult {
+	resp, err := c.do("GET", spoolPath(taskID), ContentTypeJSON, nil)
+	if err != nil {
+		return &GetSchedulerSpoolResult{Err: err}
+	}
+	switch resp.Meta.Type {
+	case rbody.SchedulerSpoolReturnedType:
+		// Success
+		return &GetSchedulerSpoolResult{resp.Body.(*rbody.SchedulerSpoolReturned), nil}
+	case rbody.ErrorType:
+		return &GetSchedulerSpoolResult{Err: resp.Body.(*rbody.Error)}
+	default:
+		return &GetSchedulerSpoolResult{Err: ErrAPIResponseMetaType}
+	}
+}
+
+// GetSchedulerSpoolResult is the response from snap/client on a GetSchedulerSpool call.
+type GetSchedulerSpoolResult struct {
+	*rbody.SchedulerSpoolReturned
+	Err error
+}
+
+// PurgeSchedulerSpool removes the entries of the spool, those of the task with
+// the given id if it is not empty, through an HTTP DELETE call. Otherwise, an
+// error is returned.
+func (c *Client) PurgeSchedulerSpool(taskID string) *PurgeSchedulerSpoolResult {
+	resp, err := c.do("DELETE", spoolPath(taskID), ContentTypeJSON)
+	if err != nil {
+		return &PurgeSchedulerSpoolResult{Err: err}
+	}
+	switch resp.Meta.Type {
+	case rbody.SchedulerSpoolPurgedType:
+		// Success
+		return &PurgeSchedulerSpoolResult{resp.Body.(*rbody.SchedulerSpoolPurged), nil}
+	case rbody.ErrorType:
+		return &PurgeSchedulerSpoolResult{Err: resp.Body.(*rbody.Error)}
+	default:
+		return &PurgeSchedulerSpoolResult{Err: ErrAPIResponseMetaType}
+	}
+}
+
+// PurgeSchedulerSpoolResult is the response from snap/client on a PurgeSchedulerSpool call.
+type PurgeSchedulerSpoolResult struct {
+	*rbody.SchedulerSpoolPurged
+	Err error
+}
+
+func spoolPath(taskID string) string {
+	if taskID == "" {
+		return "/scheduler/spool"
+	}
+	return "/scheduler/spool?task=" + url.QueryEscape(taskID)
+}
@@ -34,7 +34,7 @@ import (
 )
 
 type Schedule struct {
-	// Type specifies the type of the schedule. Currently, the type of "simple", "windowed" and "cron" are supported.
+	// Type specifies the type of the schedule. Currently, the type of "simple", "windowed", "cron", "triggered-by" and "event" are supported.
 	Type string
 	// Interval specifies the time duration.
 	Interval string
@@ -42,17 +42,106 @@ type Schedule struct {
 	StartTime *time.Time
 	// StopTime specifies the end time.
 	StopTime *time.Time
+	// Splay specifies the time duration each fire is randomly delayed by, at most.
+	Splay string
+	// Aligned specifies whether the schedule fires at multiples of the interval since the epoch.
+	Aligned bool
+	// AlignOffset specifies the time duration the aligned fires are shifted by.
+	AlignOffset string
+	// Days specifies the days of the week a windowed schedule recurs on.
+	Days []string
+	// DailyStart specifies the time of day a recurring window opens at.
+	DailyStart string
+	// DailyStop specifies the time of day a recurring window closes at.
+	DailyStop string
+	// Timezone specifies the time zone of a recurring window.
+	Timezone string
+	// TaskID specifies the task whose runs fire a triggered-by schedule.
+	TaskID string
+	// SuccessOnly specifies whether a triggered-by schedule fires on the successful runs only.
+	SuccessOnly bool
+	// Events specifies the namespaces of the events firing an event schedule.
+	Events []string
+	// Filter specifies the expression the fields of the events firing an event schedule must match.
+	Filter string
+	// MinInterval specifies the shortest time duration between two fires of an event schedule.
+	MinInterval string
+}
+
+// TaskOp sets an optional field of a task creation request
+type TaskOp func(t *core.TaskCreationRequest)
+
+// CollectPolicy sets the collect policy of a task, either "all-or-nothing"
+// or "best-effort".
+func CollectPolicy(p string) TaskOp {
+	return func(t *core.TaskCreationRequest) {
+		t.CollectPolicy = p
+	}
+}
+
+// FailurePolicy sets the failure policy of a task, which decides whether a
+// task reaching its max-failures backs off rather than being disabled.
+func FailurePolicy(p core.FailurePolicy) TaskOp {
+	return func(t *core.TaskCreationRequest) {
+		t.FailurePolicy = &p
+	}
+}
+
+// Catchup sets the catch-up policy of a task, which decides whether a task
+// runs its workflow for the intervals of its schedule it missed.
+func Catchup(p core.CatchupPolicy) TaskOp {
+	return func(t *core.TaskCreationRequest) {
+		t.Catchup = &p
+	}
+}
+
+// Overlap sets the overlap policy of a task, which decides what a task does
+// when its schedule fires while its workflow is still running.
+func Overlap(p core.OverlapPolicy) TaskOp {
+	return func(t *core.TaskCreationRequest) {
+		t.Overlap = &p
+	}
+}
+
+// Priority sets the priority of a task, "low", "normal", "high" or
+// "critical", which decides the order its jobs are worked in when the work
+// manager queues hold the jobs of several tasks.
+func Priority(p string) TaskOp {
+	return func(t *core.TaskCreationRequest) {
+		t.Priority = p
+	}
+}
+
+// LatencySLO sets the latency objective of a task, a duration its runs are
+// expected to complete within. A run taking longer is counted as a breach and
+// emits an event.
+func LatencySLO(d string) TaskOp {
+	return func(t *core.TaskCreationRequest) {
+		t.LatencySLO = d
+	}
 }
 
 // CreateTask creates a task given the schedule, workflow, task name, and task state.
 // If the startTask flag is true, the newly created task is started after the creation.
 // Otherwise, it's in the Stopped state. CreateTask is accomplished through a POST HTTP JSON request.
 // A ScheduledTask is returned if it succeeds, otherwise an error is returned.
-func (c *Client) CreateTask(s *Schedule, wf *wmap.WorkflowMap, name string, deadline string, startTask bool, maxFailures int) *CreateTaskResult {
+func (c *Client) CreateTask(s *Schedule, wf *wmap.WorkflowMap, name string, deadline string, startTask bool, maxFailures int, opts ...TaskOp) *CreateTaskResult {
 	t := core.TaskCreationRequest{
 		Schedule: &core.Schedule{
-			Type:     s.Type,
-			Interval: s.Interval,
+			Type:        s.Type,
+			Interval:    s.Interval,
+			Splay:       s.Splay,
+			Aligned:     s.Aligned,
+			AlignOffset: s.AlignOffset,
+			Days:        s.Days,
+			DailyStart:  s.DailyStart,
+			DailyStop:   s.DailyStop,
+			Timezone:    s.Timezone,
+			TaskID:      s.TaskID,
+			SuccessOnly: s.SuccessOnly,
+			Events:      s.Events,
+			Filter:      s.Filter,
+			MinInterval: s.MinInterval,
 		},
 		Workflow:    wf,
 		Start:       startTask,
@@ -74,6 +163,9 @@ func (c *Client) CreateTask(s *Schedule, wf *wmap.WorkflowMap, name string, dead
 	if deadline != "" {
 		t.Deadline = deadline
 	}
+	for _, opt := range opts {
+		opt(&t)
+	}
 	// Marshal to JSON for request body
 	j, err := json.Marshal(t)
 	if err != nil {
@@ -211,6 +303,63 @@ func (c *Client) GetTask(id string) *GetTaskResult {
 	}
 }
 
+// GetTaskHistory retrieves the records of the most recent runs of a task
+// given a task id through an HTTP GET call. Otherwise, an error is returned.
+func (c *Client) GetTaskHistory(id string) *GetTaskHistoryResult {
+	resp, err := c.do("GET", fmt.Sprintf("/tasks/%v/history", id), ContentTypeJSON, nil)
+	if err != nil {
+		return &GetTaskHistoryResult{Err: err}
+	}
+	switch resp.Meta.Type {
+	case rbody.ScheduledTaskHistoryType:
+		// Success
+		return &GetTaskHistoryResult{resp.Body.(*rbody.ScheduledTaskHistory), nil}
+	case rbody.ErrorType:
+		return &GetTaskHistoryResult{Err: resp.Body.(*rbody.Error)}
+	default:
+		return &GetTaskHistoryResult{Err: ErrAPIResponseMetaType}
+	}
+}
+
+// FireTask runs the workflow of a task once, right away, given a task id
+// through an HTTP POST call. A task which is not running is only fired if
+// force is set. The record of the run, holding the errors of its jobs, is
+// returned if it succeeds. Otherwise, an error is returned.
+func (c *Client) FireTask(id string, force bool) *FireTaskResult {
+	resp, err := c.do("POST", fmt.Sprintf("/tasks/%v/fire?force=%v", id, force), ContentTypeJSON)
+	if err != nil {
+		return &FireTaskResult{Err: err}
+	}
+	switch resp.Meta.Type {
+	case rbody.ScheduledTaskFiredType:
+		// Success
+		return &FireTaskResult{resp.Body.(*rbody.ScheduledTaskFired), nil}
+	case rbody.ErrorType:
+		return &FireTaskResult{Err: resp.Body.(*rbody.Error)}
+	default:
+		return &FireTaskResult{Err: ErrAPIResponseMetaType}
+	}
+}
+
+// GetTaskLatencies retrieves the latency distributions of the runs of a task
+// and of the jobs run for each node of its workflow given a task id through
+// an HTTP GET call. Otherwise, an error is returned.
+func (c *Client) GetTaskLatencies(id string) *GetTaskLatenciesResult {
+	resp, err := c.do("GET", fmt.Sprintf("/tasks/%v/latency", id), ContentTypeJSON, nil)
+	if err != nil {
+		return &GetTaskLatenciesResult{Err: err}
+	}
+	switch resp.Meta.Type {
+	case rbody.ScheduledTaskLatenciesType:
+		// Success
+		return &GetTaskLatenciesResult{resp.Body.(*rbody.ScheduledTaskLatencies), nil}
+	case rbody.ErrorType:
+		return &GetTaskLatenciesResult{Err: resp.Body.(*rbody.Error)}
+	default:
+		return &GetTaskLatenciesResult{Err: ErrAPIResponseMetaType}
+	}
+}
+
 // StartTask starts a task given a task id. The scheduled task will be in
 // the started state if it succeeds. Otherwise, an error is returned.
 func (c *Client) StartTask(id string) *StartTasksResult {
@@ -290,6 +439,62 @@ func (c *Client) EnableTask(id string) *EnableTaskResult {
 	}
 }
 
+// UpdateTask replaces the schedule and/or the workflow of a task given its ID.
+// A nil schedule or workflow map keeps the current one of the task.
+// UpdateTask is accomplished through a PATCH HTTP JSON request.
+// The updated task is returned if it succeeds, otherwise an error is returned.
+func (c *Client) UpdateTask(id string, s *Schedule, wf *wmap.WorkflowMap) *UpdateTaskResult {
+	t := core.TaskUpdateRequest{
+		Workflow: wf,
+	}
+	if s != nil {
+		t.Schedule = &core.Schedule{
+			Type:        s.Type,
+			Interval:    s.Interval,
+			Splay:       s.Splay,
+			Aligned:     s.Aligned,
+			AlignOffset: s.AlignOffset,
+			Days:        s.Days,
+			DailyStart:  s.DailyStart,
+			DailyStop:   s.DailyStop,
+			Timezone:    s.Timezone,
+			TaskID:      s.TaskID,
+			SuccessOnly: s.SuccessOnly,
+			Events:      s.Events,
+			Filter:      s.Filter,
+			MinInterval: s.MinInterval,
+		}
+		// Add start and/or stop timestamps if they exist
+		if s.StartTime != nil {
+			u := s.StartTime.Unix()
+			t.Schedule.StartTimestamp = &u
+		}
+		if s.StopTime != nil {
+			u := s.StopTime.Unix()
+			t.Schedule.StopTimestamp = &u
+		}
+	}
+	// Marshal to JSON for request body
+	j, err := json.Marshal(t)
+	if err != nil {
+		return &UpdateTaskResult{Err: err}
+	}
+
+	resp, err := c.do("PATCH", fmt.Sprintf("/tasks/%v", id), ContentTypeJSON, j)
+	if err != nil {
+		return &UpdateTaskResult{Err: err}
+	}
+
+	switch resp.Meta.Type {
+	case rbody.ScheduledTaskUpdatedType:
+		return &UpdateTaskResult{resp.Body.(*rbody.ScheduledTaskUpdated), nil}
+	case rbody.ErrorType:
+		return &UpdateTaskResult{Err: resp.Body.(*rbody.Error)}
+	default:
+		return &UpdateTaskResult{Err: ErrAPIResponseMetaType}
+	}
+}
+
 // CreateTaskResult is the response from snap/client on a CreateTask call.
 type CreateTaskResult struct {
 	*rbody.AddScheduledTask
@@ -320,6 +525,24 @@ type GetTaskResult struct {
 	Err error
 }
 
+// GetTaskHistoryResult is the response from snap/client on a GetTaskHistory call.
+type GetTaskHistoryResult struct {
+	*rbody.ScheduledTaskHistory
+	Err error
+}
+
+// FireTaskResult is the response from snap/client on a FireTask call.
+type FireTaskResult struct {
+	*rbody.ScheduledTaskFired
+	Err error
+}
+
+// GetTaskLatenciesResult is the response from snap/client on a GetTaskLatencies call.
+type GetTaskLatenciesResult struct {
+	*rbody.ScheduledTaskLatencies
+	Err error
+}
+
 // StartTasksResult is the response from snap/client on a StartTask call.
 type StartTasksResult struct {
 	*rbody.ScheduledTaskStarted
@@ -343,3 +566,9 @@ type EnableTaskResult struct {
 	*rbody.ScheduledTaskEnabled
 	Err error
 }
+
+// UpdateTaskResult is the response from snap/client on an UpdateTask call.
+type UpdateTaskResult struct {
+	*rbody.ScheduledTaskUpdated
+	Err error
+}
@@ -57,6 +57,16 @@ var (
 		Usage: "Enables snap's REST API authentication",
 	}
 
+	flMetricsAddr = cli.StringFlag{
+		Name:   "metrics-addr",
+		Usage:  "Address[:port] the listener serving the internal metrics of snapd on /metrics binds to. Default: empty string => disabled",
+		EnvVar: "SNAP_METRICS_ADDR",
+	}
+	flMetricsAuth = cli.BoolFlag{
+		Name:  "metrics-auth",
+		Usage: "Enables authentication on the listener serving the internal metrics of snapd",
+	}
+
 	// Flags consumed by snapd
-	Flags = []cli.Flag{flAPIDisabled, flAPIAddr, flAPIPort, flRestHTTPS, flRestCert, flRestKey, flRestAuth}
+	Flags = []cli.Flag{flAPIDisabled, flAPIAddr, flAPIPort, flRestHTTPS, flRestCert, flRestKey, flRestAuth, flMetricsAddr, flMetricsAuth}
 )
@@ -21,14 +21,20 @@ package rest
 
 import (
 	"net/http"
+	"sync/atomic"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/codegangsta/negroni"
 )
 
-// Logger is a snap middleware that logs to a logrus facility
+// Logger is a snap middleware that logs to a logrus facility. It counts the
+// requests it logged and the ones answered with an error, and sums the time
+// taken to answer them.
 type Logger struct {
-	counter uint
+	counter  uint64
+	errors   uint64
+	duration int64
 }
 
 // NewLogger returns a new Logger instance
@@ -37,19 +43,35 @@ func NewLogger() *Logger {
 }
 
 func (l *Logger) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
-	l.counter++
+	index := atomic.AddUint64(&l.counter, 1)
 	restLogger.WithFields(log.Fields{
-		"index":  l.counter,
+		"index":  index,
 		"method": r.Method,
 		"url":    r.URL.Path,
 	}).Debug("API request")
+	start := time.Now()
 	next(rw, r)
+	atomic.AddInt64(&l.duration, int64(time.Since(start)))
 	res := rw.(negroni.ResponseWriter)
+	if res.Status() >= http.StatusBadRequest {
+		atomic.AddUint64(&l.errors, 1)
+	}
 	restLogger.WithFields(log.Fields{
-		"index":       l.counter,
+		"index":       index,
 		"method":      r.Method,
 		"url":         r.URL.Path,
 		"status-code": res.Status(),
 		"status":      http.StatusText(res.Status()),
 	}).Debug("API response")
 }
+
+// Counts returns the number of requests logged and of the ones answered
+// with an error
+func (l *Logger) Counts() (requests, errors uint64) {
+	return atomic.LoadUint64(&l.counter), atomic.LoadUint64(&l.errors)
+}
+
+// Duration returns the time taken to answer all the requests logged
+func (l *Logger) Duration() time.Duration {
+	return time.Duration(atomic.LoadInt64(&l.duration))
+}
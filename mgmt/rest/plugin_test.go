@@ -101,6 +101,12 @@ func (m MockManagesMetrics) GetAutodiscoverPaths() []string {
 	return nil
 }
 
+func (m MockManagesMetrics) PluginPoolStats() []core.PluginPoolStats {
+	return []core.PluginPoolStats{
+		{TypeName: "collector", Name: "foo", Version: 1, Running: 2, Subscriptions: 1, CacheHits: 3, CacheMisses: 1},
+	}
+}
+
 func TestGetPlugins(t *testing.T) {
 	mm := MockManagesMetrics{}
 	host := "localhost"
@@ -0,0 +1,305 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/core"
+)
+
+// prometheusContentType is the content type of the Prometheus text
+// exposition format
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metricsAuthUsername is the user name the metrics listener authenticates
+const metricsAuthUsername = "snap"
+
+// metricsListener serves the internal metrics of snapd on /metrics in the
+// Prometheus text exposition format. It listens apart from the REST API, so
+// it can be bound to another address and protected by another password.
+type metricsListener struct {
+	server   *Server
+	addr     string
+	auth     bool
+	password string
+	listener net.Listener
+	wg       sync.WaitGroup
+}
+
+func newMetricsListener(s *Server, addr string, auth bool, password string) *metricsListener {
+	return &metricsListener{
+		server:   s,
+		addr:     addr,
+		auth:     auth,
+		password: password,
+	}
+}
+
+func (m *metricsListener) start() error {
+	ln, err := net.Listen("tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	m.listener = ln
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", m.serveMetrics)
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		// the error returned once the listener is closed is expected
+		http.Serve(ln, mux)
+	}()
+	restLogger.WithFields(log.Fields{
+		"_block": "metrics-listener",
+		"addr":   ln.Addr().String(),
+	}).Info("Serving internal metrics on /metrics")
+	return nil
+}
+
+func (m *metricsListener) stop() {
+	if m.listener != nil {
+		m.listener.Close()
+	}
+	m.wg.Wait()
+}
+
+func (m *metricsListener) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	if m.auth && !m.authorized(r) {
+		http.Error(w, "Not Authorized", 401)
+		return
+	}
+	var buf bytes.Buffer
+	for _, f := range m.server.prometheusFamilies() {
+		f.write(&buf)
+	}
+	w.Header().Set("Content-Type", prometheusContentType)
+	w.WriteHeader(200)
+	w.Write(buf.Bytes())
+}
+
+// authorized returns whether the request carries the basic authentication
+// credentials of the listener: the user name snap, as used by the clients of
+// the REST API, and the password of the listener. They are compared in
+// constant time.
+func (m *metricsListener) authorized(r *http.Request) bool {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	userOK := subtle.ConstantTimeCompare([]byte(username), []byte(metricsAuthUsername)) == 1
+	passwordOK := subtle.ConstantTimeCompare([]byte(password), []byte(m.password)) == 1
+	return userOK && passwordOK
+}
+
+// prometheusFamily is a metric family of the Prometheus text exposition
+// format
+type prometheusFamily struct {
+	name    string
+	help    string
+	typ     string
+	samples []prometheusSample
+}
+
+// prometheusSample is a sample of a metric family. Its labels are pairs of
+// names and values. The suffix is appended to the name of the family, for the
+// buckets, sum and count of a histogram.
+type prometheusSample struct {
+	suffix string
+	labels []string
+	value  float64
+}
+
+func (f *prometheusFamily) add(value float64, labels ...string) {
+	f.samples = append(f.samples, prometheusSample{labels: labels, value: value})
+}
+
+// addHistogram adds the samples of a latency histogram, in seconds
+func (f *prometheusFamily) addHistogram(h core.LatencySnapshot, labels ...string) {
+	for _, b := range h.Buckets {
+		le := "+Inf"
+		if b.UpperBound > 0 {
+			le = strconv.FormatFloat(b.UpperBound.Seconds(), 'g', -1, 64)
+		}
+		bl := append(append([]string{}, labels...), "le", le)
+		f.samples = append(f.samples, prometheusSample{suffix: "_bucket", labels: bl, value: float64(b.Count)})
+	}
+	f.samples = append(f.samples,
+		prometheusSample{suffix: "_sum", labels: labels, value: h.Sum.Seconds()},
+		prometheusSample{suffix: "_count", labels: labels, value: float64(h.Count)},
+	)
+}
+
+func (f *prometheusFamily) write(w io.Writer) {
+	if len(f.samples) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, f.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.typ)
+	for _, s := range f.samples {
+		io.WriteString(w, f.name+s.suffix)
+		if len(s.labels) > 0 {
+			pairs := []string{}
+			for i := 0; i+1 < len(s.labels); i += 2 {
+				pairs = append(pairs, s.labels[i]+"=\""+escapeLabelValue(s.labels[i+1])+"\"")
+			}
+			io.WriteString(w, "{"+strings.Join(pairs, ",")+"}")
+		}
+		io.WriteString(w, " "+strconv.FormatFloat(s.value, 'g', -1, 64)+"\n")
+	}
+}
+
+var labelValueEscaper = strings.NewReplacer("\\", `\\`, "\n", `\n`, "\"", `\"`)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
+
+// prometheusFamilies returns the internal metrics of snapd, gathered from
+// the modules bound to the server.
+func (s *Server) prometheusFamilies() []*prometheusFamily {
+	families := []*prometheusFamily{}
+	newFamily := func(name, typ, help string) *prometheusFamily {
+		f := &prometheusFamily{name: name, typ: typ, help: help}
+		families = append(families, f)
+		return f
+	}
+
+	requests, errors := s.logger.Counts()
+	newFamily("snap_rest_requests_total", "counter", "Requests served by the REST API.").add(float64(requests))
+	newFamily("snap_rest_request_errors_total", "counter", "Requests answered by the REST API with an error.").add(float64(errors))
+	newFamily("snap_rest_request_duration_seconds_total", "counter", "Time taken to answer the requests served by the REST API.").
+		add(s.logger.Duration().Seconds())
+
+	if s.mt != nil {
+		depth := newFamily("snap_scheduler_queue_depth", "gauge", "Jobs waiting in the scheduler work queue.")
+		limit := newFamily("snap_scheduler_queue_limit", "gauge", "Jobs the scheduler work queue holds at most, 0 if unbounded.")
+		workers := newFamily("snap_scheduler_queue_workers", "gauge", "Workers working the scheduler work queue.")
+		busy := newFamily("snap_scheduler_queue_busy_workers", "gauge", "Workers of the scheduler work queue running a job.")
+		stats := s.mt.QueueStats()
+		queues := []string{}
+		for q := range stats {
+			queues = append(queues, q)
+		}
+		sort.Strings(queues)
+		for _, q := range queues {
+			depth.add(float64(stats[q].Depth), "queue", q)
+			limit.add(float64(stats[q].Limit), "queue", q)
+			workers.add(float64(stats[q].Workers), "queue", q)
+			busy.add(float64(stats[q].BusyWorkers), "queue", q)
+		}
+
+		hits := newFamily("snap_task_hits_total", "counter", "Times the task ran.")
+		misses := newFamily("snap_task_misses_total", "counter", "Intervals the task missed.")
+		failures := newFamily("snap_task_failures_total", "counter", "Runs of the task which failed.")
+		overflows := newFamily("snap_task_overflows_total", "counter", "Runs of the task which failed on a full scheduler work queue.")
+		deadlines := newFamily("snap_task_deadline_exceeded_total", "counter", "Runs of the task which failed on a plugin call cancelled at the deadline of its job.")
+		busySkips := newFamily("snap_task_busy_skips_total", "counter", "Fires of the task skipped while its workflow was running.")
+		duration := newFamily("snap_task_last_run_duration_seconds", "gauge", "Time taken by the most recent run of the task.")
+		sloBreaches := newFamily("snap_task_slo_breaches_total", "counter", "Runs of the task which took longer than its latency objective.")
+		runLatency := newFamily("snap_task_run_duration_seconds", "histogram", "Time taken by the runs of the task.")
+		jobLatency := newFamily("snap_task_job_duration_seconds", "histogram", "Time taken by the jobs run for a node of the workflow of the task.")
+		tasks := s.mt.GetTasks()
+		ids := []string{}
+		for id := range tasks {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			t := tasks[id]
+			labels := []string{"task_id", id, "task_name", t.GetName()}
+			hits.add(float64(t.HitCount()), labels...)
+			misses.add(float64(t.MissedCount()), labels...)
+			failures.add(float64(t.FailedCount()), labels...)
+			overflows.add(float64(t.OverflowCount()), labels...)
+			deadlines.add(float64(t.DeadlineExceededCount()), labels...)
+			busySkips.add(float64(t.BusySkipCount()), labels...)
+			if d := t.LastRunDuration(); d > 0 {
+				duration.add(d.Seconds(), labels...)
+			}
+			sloBreaches.add(float64(t.SLOBreachCount()), labels...)
+			if l, err := s.mt.GetTaskLatencies(id); err == nil {
+				runLatency.addHistogram(l.Workflow, labels...)
+				for _, j := range l.Jobs {
+					jl := append(append([]string{}, labels...),
+						"node_type", j.Type, "plugin_name", j.PluginName, "plugin_version", strconv.Itoa(j.PluginVersion))
+					jobLatency.addHistogram(j.Latency, jl...)
+				}
+			}
+		}
+	}
+
+	if s.mm != nil {
+		running := newFamily("snap_plugin_pool_available_plugins", "gauge", "Running instances of the plugin.")
+		subscriptions := newFamily("snap_plugin_pool_subscriptions", "gauge", "Tasks subscribed to the plugin.")
+		restarts := newFamily("snap_plugin_pool_restarts_total", "counter", "Times an instance of the plugin was restarted after it died.")
+		cacheHits := newFamily("snap_plugin_pool_cache_hits_total", "counter", "Metrics of the plugin served from the cache.")
+		cacheMisses := newFamily("snap_plugin_pool_cache_misses_total", "counter", "Metrics of the plugin missing from the cache.")
+		deadlines := newFamily("snap_plugin_pool_deadline_exceeded_total", "counter", "Calls to the plugin cancelled at the deadline of their job.")
+		cacheRatio := newFamily("snap_plugin_pool_cache_hit_ratio", "gauge", "Share of the metrics of the plugin served from the cache.")
+		callLatency := newFamily("snap_plugin_pool_call_duration_seconds", "histogram", "Time taken by the calls to the plugin collecting, processing or publishing metrics.")
+		pools := s.mm.PluginPoolStats()
+		sort.Sort(poolStatsByKey(pools))
+		for _, p := range pools {
+			labels := []string{"plugin_type", p.TypeName, "plugin_name", p.Name, "plugin_version", strconv.Itoa(p.Version)}
+			running.add(float64(p.Running), labels...)
+			subscriptions.add(float64(p.Subscriptions), labels...)
+			restarts.add(float64(p.Restarts), labels...)
+			cacheHits.add(float64(p.CacheHits), labels...)
+			cacheMisses.add(float64(p.CacheMisses), labels...)
+			deadlines.add(float64(p.DeadlineExceeded), labels...)
+			callLatency.addHistogram(p.Latency, labels...)
+			if total := p.CacheHits + p.CacheMisses; total > 0 {
+				cacheRatio.add(float64(p.CacheHits)/float64(total), labels...)
+			}
+		}
+	}
+
+	if s.tr != nil {
+		newFamily("snap_tribe_members", "gauge", "Members of the tribe.").add(float64(len(s.tr.GetMembers())))
+	}
+	return families
+}
+
+// poolStatsByKey sorts the pools by plugin type, name and version
+type poolStatsByKey []core.PluginPoolStats
+
+func (p poolStatsByKey) Len() int      { return len(p) }
+func (p poolStatsByKey) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p poolStatsByKey) Less(i, j int) bool {
+	if p[i].TypeName != p[j].TypeName {
+		return p[i].TypeName < p[j].TypeName
+	}
+	if p[i].Name != p[j].Name {
+		return p[i].Name < p[j].Name
+	}
+	return p[i].Version < p[j].Version
+}
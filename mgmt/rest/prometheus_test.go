@@ -0,0 +1,109 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMetricsListener(t *testing.T) {
+	Convey("Metrics listener", t, func() {
+		cfg := GetDefaultConfig()
+		cfg.MetricsAddress = "127.0.0.1:0"
+		s, err := New(cfg)
+		So(err, ShouldBeNil)
+		s.BindMetricManager(MockManagesMetrics{})
+
+		Convey("serves the internal metrics in the Prometheus text format", func() {
+			w := httptest.NewRecorder()
+			s.metrics.serveMetrics(w, httptest.NewRequest("GET", "/metrics", nil))
+			So(w.Code, ShouldEqual, 200)
+			So(w.Header().Get("Content-Type"), ShouldEqual, prometheusContentType)
+			body := w.Body.String()
+			So(body, ShouldContainSubstring, "# TYPE snap_rest_requests_total counter\nsnap_rest_requests_total 0\n")
+			So(body, ShouldContainSubstring, `snap_plugin_pool_available_plugins{plugin_type="collector",plugin_name="foo",plugin_version="1"} 2`)
+			So(body, ShouldContainSubstring, `snap_plugin_pool_cache_hit_ratio{plugin_type="collector",plugin_name="foo",plugin_version="1"} 0.75`)
+			So(body, ShouldNotContainSubstring, "snap_tribe_members")
+		})
+		Convey("requires the password once authentication is enabled", func() {
+			s.metrics.auth = true
+			s.metrics.password = "secret"
+			w := httptest.NewRecorder()
+			s.metrics.serveMetrics(w, httptest.NewRequest("GET", "/metrics", nil))
+			So(w.Code, ShouldEqual, 401)
+
+			w = httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/metrics", nil)
+			r.SetBasicAuth("snap", "secret")
+			s.metrics.serveMetrics(w, r)
+			So(w.Code, ShouldEqual, 200)
+
+			w = httptest.NewRecorder()
+			r = httptest.NewRequest("GET", "/metrics", nil)
+			r.SetBasicAuth("admin", "secret")
+			s.metrics.serveMetrics(w, r)
+			So(w.Code, ShouldEqual, 401)
+
+			w = httptest.NewRecorder()
+			r = httptest.NewRequest("GET", "/metrics", nil)
+			r.SetBasicAuth("snap", "wrong")
+			s.metrics.serveMetrics(w, r)
+			So(w.Code, ShouldEqual, 401)
+		})
+		Convey("listens apart from the REST API", func() {
+			So(s.metrics.start(), ShouldBeNil)
+			defer s.metrics.stop()
+			resp, err := http.Get("http://" + s.metrics.listener.Addr().String() + "/metrics")
+			So(err, ShouldBeNil)
+			defer resp.Body.Close()
+			So(resp.StatusCode, ShouldEqual, 200)
+		})
+	})
+	Convey("Metrics authentication without a password is refused", t, func() {
+		cfg := GetDefaultConfig()
+		cfg.MetricsAddress = "127.0.0.1:0"
+		cfg.MetricsAuth = true
+		_, err := New(cfg)
+		So(err, ShouldEqual, ErrMetricsAuthPassword)
+	})
+}
+
+func TestPrometheusFamily(t *testing.T) {
+	Convey("Prometheus metric family", t, func() {
+		f := &prometheusFamily{name: "foo", typ: "gauge", help: "Foo."}
+		Convey("writes nothing without samples", func() {
+			w := httptest.NewRecorder()
+			f.write(w)
+			So(w.Body.String(), ShouldBeEmpty)
+		})
+		Convey("escapes label values", func() {
+			f.add(1, "name", "a\"b\\c\nd")
+			w := httptest.NewRecorder()
+			f.write(w)
+			So(w.Body.String(), ShouldEqual, "# HELP foo Foo.\n# TYPE foo gauge\nfoo{name=\"a\\\"b\\\\c\\nd\"} 1\n")
+		})
+	})
+}
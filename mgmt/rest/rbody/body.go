@@ -100,6 +100,22 @@ func UnmarshalBody(t string, b []byte) (Body, error) {
 		return unmarshalAndHandleError(b, &ScheduledTaskRemoved{})
 	case ScheduledTaskEnabledType:
 		return unmarshalAndHandleError(b, &ScheduledTaskEnabled{})
+	case ScheduledTaskUpdatedType:
+		return unmarshalAndHandleError(b, &ScheduledTaskUpdated{})
+	case ScheduledTaskHistoryType:
+		return unmarshalAndHandleError(b, &ScheduledTaskHistory{})
+	case ScheduledTaskLatenciesType:
+		return unmarshalAndHandleError(b, &ScheduledTaskLatencies{})
+	case ScheduledTaskFiredType:
+		return unmarshalAndHandleError(b, &ScheduledTaskFired{})
+	case SchedulerQueuesReturnedType:
+		return unmarshalAndHandleError(b, &SchedulerQueuesReturned{})
+	case SchedulerQueueResizedType:
+		return unmarshalAndHandleError(b, &SchedulerQueueResized{})
+	case SchedulerSpoolReturnedType:
+		return unmarshalAndHandleError(b, &SchedulerSpoolReturned{})
+	case SchedulerSpoolPurgedType:
+		return unmarshalAndHandleError(b, &SchedulerSpoolPurged{})
 	case MetricReturnedType:
 		return unmarshalAndHandleError(b, &MetricReturned{})
 	case MetricsReturnedType:
@@ -0,0 +1,163 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbody
+
+import (
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
+)
+
+const (
+	SchedulerQueuesReturnedType = "scheduler_queues_returned"
+	SchedulerQueueResizedType   = "scheduler_queue_resized"
+	SchedulerSpoolReturnedType  = "scheduler_spool_returned"
+	SchedulerSpoolPurgedType    = "scheduler_spool_purged"
+)
+
+// SchedulerQueuesReturned holds the depth of the collect, process and publish
+// queues of the scheduler work manager
+type SchedulerQueuesReturned struct {
+	Queues map[string]WorkQueue `json:"queues"`
+}
+
+// WorkQueue holds the depth of a work manager queue, in total and for each
+// task priority, and the utilization of the worker pool working it
+type WorkQueue struct {
+	Limit           uint           `json:"limit"`
+	Depth           int            `json:"depth"`
+	DepthByPriority map[string]int `json:"depth_by_priority"`
+	Workers         uint           `json:"workers"`
+	BusyWorkers     uint           `json:"busy_workers"`
+}
+
+func SchedulerQueuesFromStats(stats map[string]core.WorkQueueStats) *SchedulerQueuesReturned {
+	q := &SchedulerQueuesReturned{Queues: make(map[string]WorkQueue, len(stats))}
+	for name, st := range stats {
+		q.Queues[name] = workQueueFromStats(st)
+	}
+	return q
+}
+
+func workQueueFromStats(st core.WorkQueueStats) WorkQueue {
+	wq := WorkQueue{
+		Limit:           st.Limit,
+		Depth:           st.Depth,
+		DepthByPriority: map[string]int{},
+		Workers:         st.Workers,
+		BusyWorkers:     st.BusyWorkers,
+	}
+	for p := range core.TaskPriorityLookup {
+		wq.DepthByPriority[p.String()] = st.DepthByPriority[p]
+	}
+	return wq
+}
+
+func (s *SchedulerQueuesReturned) ResponseBodyMessage() string {
+	return "Scheduler queues returned"
+}
+
+func (s *SchedulerQueuesReturned) ResponseBodyType() string {
+	return SchedulerQueuesReturnedType
+}
+
+// SchedulerQueueResized holds a scheduler queue once its limit and/or the
+// size of its worker pool changed
+type SchedulerQueueResized struct {
+	Name  string    `json:"name"`
+	Queue WorkQueue `json:"queue"`
+}
+
+func SchedulerQueueResizedFromStats(name string, st core.WorkQueueStats) *SchedulerQueueResized {
+	return &SchedulerQueueResized{Name: name, Queue: workQueueFromStats(st)}
+}
+
+func (s *SchedulerQueueResized) ResponseBodyMessage() string {
+	return "Scheduler queue resized"
+}
+
+func (s *SchedulerQueueResized) ResponseBodyType() string {
+	return SchedulerQueueResizedType
+}
+
+// SchedulerSpoolReturned describes the spool holding the metrics the publish
+// nodes of the tasks failed to publish, and the entries it holds
+type SchedulerSpoolReturned struct {
+	Path    string       `json:"path"`
+	Size    int64        `json:"size"`
+	MaxSize int64        `json:"max_size"`
+	Entries []SpoolEntry `json:"entries"`
+}
+
+// SpoolEntry is a payload of metrics held in the spool
+type SpoolEntry struct {
+	ID            string    `json:"id"`
+	TaskID        string    `json:"task_id"`
+	PluginName    string    `json:"plugin_name"`
+	PluginVersion int       `json:"plugin_version"`
+	ContentType   string    `json:"content_type"`
+	MetricCount   int       `json:"metric_count"`
+	Size          int64     `json:"size"`
+	Created       time.Time `json:"created"`
+}
+
+func SchedulerSpoolFromStats(st core.SpoolStats) *SchedulerSpoolReturned {
+	s := &SchedulerSpoolReturned{
+		Path:    st.Path,
+		Size:    st.Size,
+		MaxSize: st.MaxSize,
+		Entries: make([]SpoolEntry, len(st.Entries)),
+	}
+	for i, e := range st.Entries {
+		s.Entries[i] = SpoolEntry{
+			ID:            e.ID,
+			TaskID:        e.TaskID,
+			PluginName:    e.PluginName,
+			PluginVersion: e.PluginVersion,
+			ContentType:   e.ContentType,
+			MetricCount:   e.MetricCount,
+			Size:          e.Size,
+			Created:       e.Created,
+		}
+	}
+	return s
+}
+
+func (s *SchedulerSpoolReturned) ResponseBodyMessage() string {
+	return "Scheduler spool returned"
+}
+
+func (s *SchedulerSpoolReturned) ResponseBodyType() string {
+	return SchedulerSpoolReturnedType
+}
+
+// SchedulerSpoolPurged holds the number of entries removed from the spool
+type SchedulerSpoolPurged struct {
+	TaskID string `json:"task_id,omitempty"`
+	Purged int    `json:"purged"`
+}
+
+func (s *SchedulerSpoolPurged) ResponseBodyMessage() string {
+	return "Scheduler spool purged"
+}
+
+func (s *SchedulerSpoolPurged) ResponseBodyType() string {
+	return SchedulerSpoolPurgedType
+}
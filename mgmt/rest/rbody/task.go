@@ -39,6 +39,10 @@ const (
 	ScheduledTaskRemovedType       = "scheduled_task_removed"
 	ScheduledTaskWatchingEndedType = "schedule_task_watch_ended"
 	ScheduledTaskEnabledType       = "scheduled_task_enabled"
+	ScheduledTaskUpdatedType       = "scheduled_task_updated"
+	ScheduledTaskHistoryType       = "scheduled_task_history"
+	ScheduledTaskLatenciesType     = "scheduled_task_latencies"
+	ScheduledTaskFiredType         = "scheduled_task_fired"
 
 	// Event types for task watcher streaming
 	TaskWatchStreamOpen   = "stream-open"
@@ -104,11 +108,30 @@ func AddSchedulerTaskFromTask(t core.Task) *AddScheduledTask {
 		HitCount:           int(t.HitCount()),
 		MissCount:          int(t.MissedCount()),
 		FailedCount:        int(t.FailedCount()),
+		OverflowCount:      int(t.OverflowCount()),
+		DeadlineExceeded:   int(t.DeadlineExceededCount()),
+		BusySkipCount:      int(t.BusySkipCount()),
+		SLOBreachCount:     int(t.SLOBreachCount()),
 		LastFailureMessage: t.LastFailureMessage(),
+		CollectPolicy:      t.GetCollectPolicy().String(),
+		BackoffLevel:       t.BackoffLevel(),
+		Priority:           t.GetPriority().String(),
 		State:              t.State().String(),
 		Workflow:           t.WMap(),
 	}
 	assertSchedule(t.Schedule(), st)
+	if fp := t.GetFailurePolicy(); fp.Backoff {
+		st.FailurePolicy = &fp
+	}
+	if cp := t.GetCatchupPolicy(); cp.Mode != "" && cp.Mode != core.CatchupSkip {
+		st.Catchup = &cp
+	}
+	if op := t.GetOverlapPolicy(); op.Mode != "" && op.Mode != core.OverlapQueueOne {
+		st.Overlap = &op
+	}
+	if slo := t.LatencySLO(); slo > 0 {
+		st.LatencySLO = slo.String()
+	}
 	if st.LastRunTimestamp < 0 {
 		st.LastRunTimestamp = -1
 	}
@@ -116,19 +139,30 @@ func AddSchedulerTaskFromTask(t core.Task) *AddScheduledTask {
 }
 
 type ScheduledTask struct {
-	ID                 string            `json:"id"`
-	Name               string            `json:"name"`
-	Deadline           string            `json:"deadline"`
-	Workflow           *wmap.WorkflowMap `json:"workflow,omitempty"`
-	Schedule           *core.Schedule    `json:"schedule,omitempty"`
-	CreationTimestamp  int64             `json:"creation_timestamp,omitempty"`
-	LastRunTimestamp   int64             `json:"last_run_timestamp,omitempty"`
-	HitCount           int               `json:"hit_count,omitempty"`
-	MissCount          int               `json:"miss_count,omitempty"`
-	FailedCount        int               `json:"failed_count,omitempty"`
-	LastFailureMessage string            `json:"last_failure_message,omitempty"`
-	State              string            `json:"task_state"`
-	Href               string            `json:"href"`
+	ID                 string              `json:"id"`
+	Name               string              `json:"name"`
+	Deadline           string              `json:"deadline"`
+	Workflow           *wmap.WorkflowMap   `json:"workflow,omitempty"`
+	Schedule           *core.Schedule      `json:"schedule,omitempty"`
+	CreationTimestamp  int64               `json:"creation_timestamp,omitempty"`
+	LastRunTimestamp   int64               `json:"last_run_timestamp,omitempty"`
+	HitCount           int                 `json:"hit_count,omitempty"`
+	MissCount          int                 `json:"miss_count,omitempty"`
+	FailedCount        int                 `json:"failed_count,omitempty"`
+	OverflowCount      int                 `json:"overflow_count,omitempty"`
+	DeadlineExceeded   int                 `json:"deadline_exceeded_count,omitempty"`
+	BusySkipCount      int                 `json:"busy_skip_count,omitempty"`
+	SLOBreachCount     int                 `json:"slo_breach_count,omitempty"`
+	LastFailureMessage string              `json:"last_failure_message,omitempty"`
+	CollectPolicy      string              `json:"collect_policy,omitempty"`
+	FailurePolicy      *core.FailurePolicy `json:"failure_policy,omitempty"`
+	BackoffLevel       uint                `json:"backoff_level,omitempty"`
+	Catchup            *core.CatchupPolicy `json:"catchup,omitempty"`
+	Overlap            *core.OverlapPolicy `json:"overlap,omitempty"`
+	Priority           string              `json:"priority,omitempty"`
+	LatencySLO         string              `json:"latency_slo,omitempty"`
+	State              string              `json:"task_state"`
+	Href               string              `json:"href"`
 }
 
 func (s *ScheduledTask) CreationTime() time.Time {
@@ -153,8 +187,19 @@ func SchedulerTaskFromTask(t core.Task) *ScheduledTask {
 		HitCount:           int(t.HitCount()),
 		MissCount:          int(t.MissedCount()),
 		FailedCount:        int(t.FailedCount()),
+		OverflowCount:      int(t.OverflowCount()),
+		DeadlineExceeded:   int(t.DeadlineExceededCount()),
+		BusySkipCount:      int(t.BusySkipCount()),
+		SLOBreachCount:     int(t.SLOBreachCount()),
 		LastFailureMessage: t.LastFailureMessage(),
+		CollectPolicy:      t.GetCollectPolicy().String(),
+		BackoffLevel:       t.BackoffLevel(),
+		Priority:           t.GetPriority().String(),
 		State:              t.State().String(),
+		Schedule:           core.ScheduleFromSchedule(t.Schedule()),
+	}
+	if slo := t.LatencySLO(); slo > 0 {
+		st.LatencySLO = slo.String()
 	}
 	if st.LastRunTimestamp < 0 {
 		st.LastRunTimestamp = -1
@@ -213,31 +258,187 @@ func (s *ScheduledTaskEnabled) ResponseBodyType() string {
 	return ScheduledTaskEnabledType
 }
 
-func assertSchedule(s schedule.Schedule, t *AddScheduledTask) {
-	switch v := s.(type) {
-	case *schedule.SimpleSchedule:
-		t.Schedule = &core.Schedule{
-			Type:     "simple",
-			Interval: v.Interval.String(),
+type ScheduledTaskUpdated struct {
+	AddScheduledTask
+}
+
+func (s *ScheduledTaskUpdated) ResponseBodyMessage() string {
+	return fmt.Sprintf("Scheduled task (%s) updated", s.AddScheduledTask.ID)
+}
+
+func (s *ScheduledTaskUpdated) ResponseBodyType() string {
+	return ScheduledTaskUpdatedType
+}
+
+type ScheduledTaskHistory struct {
+	ID   string    `json:"id"`
+	Runs []TaskRun `json:"runs"`
+}
+
+func (s *ScheduledTaskHistory) ResponseBodyMessage() string {
+	return fmt.Sprintf("Scheduled task (%s) history returned", s.ID)
+}
+
+func (s *ScheduledTaskHistory) ResponseBodyType() string {
+	return ScheduledTaskHistoryType
+}
+
+type TaskRun struct {
+	FireTime time.Time    `json:"fire_time"`
+	Duration string       `json:"duration"`
+	Failed   bool         `json:"failed"`
+	CatchUp  bool         `json:"catch_up,omitempty"`
+	Jobs     []TaskRunJob `json:"jobs"`
+}
+
+type TaskRunJob struct {
+	Type          string   `json:"type"`
+	PluginName    string   `json:"plugin_name,omitempty"`
+	PluginVersion int      `json:"plugin_version,omitempty"`
+	Duration      string   `json:"duration"`
+	MetricCount   int      `json:"metric_count"`
+	Errors        []string `json:"errors,omitempty"`
+}
+
+func ScheduledTaskHistoryFromRuns(id string, runs []core.TaskRun) *ScheduledTaskHistory {
+	h := &ScheduledTaskHistory{
+		ID:   id,
+		Runs: make([]TaskRun, len(runs)),
+	}
+	for i, r := range runs {
+		h.Runs[i] = taskRunFromRun(r)
+	}
+	return h
+}
+
+func taskRunFromRun(r core.TaskRun) TaskRun {
+	run := TaskRun{
+		FireTime: r.FireTime,
+		Duration: r.Duration.String(),
+		Failed:   r.Failed,
+		CatchUp:  r.CatchUp,
+		Jobs:     make([]TaskRunJob, len(r.Jobs)),
+	}
+	for k, j := range r.Jobs {
+		run.Jobs[k] = TaskRunJob{
+			Type:          j.Type,
+			PluginName:    j.PluginName,
+			PluginVersion: j.PluginVersion,
+			Duration:      j.Duration.String(),
+			MetricCount:   j.MetricCount,
+			Errors:        j.Errors,
 		}
-		return
-	case *schedule.WindowedSchedule:
-		startTime := v.StartTime.Unix()
-		stopTime := v.StopTime.Unix()
-		t.Schedule = &core.Schedule{
-			Type:           "windowed",
-			Interval:       v.Interval.String(),
-			StartTimestamp: &startTime,
-			StopTimestamp:  &stopTime,
+	}
+	return run
+}
+
+type ScheduledTaskFired struct {
+	ID  string  `json:"id"`
+	Run TaskRun `json:"run"`
+}
+
+func (s *ScheduledTaskFired) ResponseBodyMessage() string {
+	if s.Run.Failed {
+		return fmt.Sprintf("Scheduled task (%s) fired with errors", s.ID)
+	}
+	return fmt.Sprintf("Scheduled task (%s) fired", s.ID)
+}
+
+func (s *ScheduledTaskFired) ResponseBodyType() string {
+	return ScheduledTaskFiredType
+}
+
+func ScheduledTaskFiredFromRun(id string, run core.TaskRun) *ScheduledTaskFired {
+	return &ScheduledTaskFired{
+		ID:  id,
+		Run: taskRunFromRun(run),
+	}
+}
+
+type ScheduledTaskLatencies struct {
+	ID             string           `json:"id"`
+	LatencySLO     string           `json:"latency_slo,omitempty"`
+	SLOBreachCount uint             `json:"slo_breach_count"`
+	Workflow       LatencyHistogram `json:"workflow"`
+	Jobs           []JobLatency     `json:"jobs"`
+}
+
+func (s *ScheduledTaskLatencies) ResponseBodyMessage() string {
+	return fmt.Sprintf("Scheduled task (%s) latencies returned", s.ID)
+}
+
+func (s *ScheduledTaskLatencies) ResponseBodyType() string {
+	return ScheduledTaskLatenciesType
+}
+
+type JobLatency struct {
+	Type          string           `json:"type"`
+	PluginName    string           `json:"plugin_name,omitempty"`
+	PluginVersion int              `json:"plugin_version,omitempty"`
+	Latency       LatencyHistogram `json:"latency"`
+}
+
+// LatencyHistogram is a latency distribution. Its quantiles are estimated
+// from the upper bounds of its buckets.
+type LatencyHistogram struct {
+	Count   uint64          `json:"count"`
+	Mean    string          `json:"mean"`
+	P50     string          `json:"p50"`
+	P90     string          `json:"p90"`
+	P99     string          `json:"p99"`
+	Max     string          `json:"max"`
+	Buckets []LatencyBucket `json:"buckets"`
+}
+
+// LatencyBucket holds the number of latencies at or below the upper bound
+// (le) of the bucket, "+Inf" for the unbounded bucket.
+type LatencyBucket struct {
+	LE    string `json:"le"`
+	Count uint64 `json:"count"`
+}
+
+func LatencyHistogramFromSnapshot(s core.LatencySnapshot) LatencyHistogram {
+	h := LatencyHistogram{
+		Count:   s.Count,
+		Mean:    s.Mean().String(),
+		P50:     s.Quantile(0.5).String(),
+		P90:     s.Quantile(0.9).String(),
+		P99:     s.Quantile(0.99).String(),
+		Max:     s.Max.String(),
+		Buckets: make([]LatencyBucket, len(s.Buckets)),
+	}
+	for i, b := range s.Buckets {
+		h.Buckets[i] = LatencyBucket{LE: "+Inf", Count: b.Count}
+		if b.UpperBound > 0 {
+			h.Buckets[i].LE = b.UpperBound.String()
 		}
-		return
-	case *schedule.CronSchedule:
-		t.Schedule = &core.Schedule{
-			Type:     "cron",
-			Interval: v.Entry(),
+	}
+	return h
+}
+
+func ScheduledTaskLatenciesFromLatencies(id string, l core.TaskLatencies) *ScheduledTaskLatencies {
+	tl := &ScheduledTaskLatencies{
+		ID:             id,
+		SLOBreachCount: l.SLOBreaches,
+		Workflow:       LatencyHistogramFromSnapshot(l.Workflow),
+		Jobs:           make([]JobLatency, len(l.Jobs)),
+	}
+	if l.SLO > 0 {
+		tl.LatencySLO = l.SLO.String()
+	}
+	for i, j := range l.Jobs {
+		tl.Jobs[i] = JobLatency{
+			Type:          j.Type,
+			PluginName:    j.PluginName,
+			PluginVersion: j.PluginVersion,
+			Latency:       LatencyHistogramFromSnapshot(j.Latency),
 		}
-		return
 	}
+	return tl
+}
+
+func assertSchedule(s schedule.Schedule, t *AddScheduledTask) {
+	t.Schedule = core.ScheduleFromSchedule(s)
 }
 
 type ScheduledTaskWatchingEnded struct {
@@ -0,0 +1,110 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/mgmt/rest/rbody"
+	"github.com/intelsdi-x/snap/scheduler"
+)
+
+var (
+	ErrWorkQueueNotFound    = errors.New("Work queue not found")
+	ErrWorkQueueResizeEmpty = errors.New("Work queue resize must include a limit or a number of workers")
+)
+
+func (s *Server) getSchedulerQueues(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	respond(200, rbody.SchedulerQueuesFromStats(s.mt.QueueStats()), w)
+}
+
+// resizeSchedulerQueue changes the limit of a scheduler queue and/or the size
+// of its worker pool
+func (s *Server) resizeSchedulerQueue(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	name := p.ByName("name")
+	rr := &core.WorkQueueResizeRequest{}
+	errCode, err := core.UnmarshalBody(rr, r.Body)
+	if errCode != 0 && err != nil {
+		respond(errCode, rbody.FromError(err), w)
+		return
+	}
+	if rr.Limit == nil && rr.Workers == nil {
+		respond(400, rbody.FromError(ErrWorkQueueResizeEmpty), w)
+		return
+	}
+	current, ok := s.mt.QueueStats()[name]
+	if !ok {
+		respond(404, rbody.FromError(ErrWorkQueueNotFound), w)
+		return
+	}
+	limit, workers := current.Limit, current.Workers
+	if rr.Limit != nil {
+		limit = *rr.Limit
+	}
+	if rr.Workers != nil {
+		workers = *rr.Workers
+	}
+	st, err := s.mt.ResizeWorkQueue(name, limit, workers)
+	if err != nil {
+		if strings.Contains(err.Error(), ErrWorkQueueNotFound.Error()) {
+			respond(404, rbody.FromError(err), w)
+			return
+		}
+		respond(400, rbody.FromError(err), w)
+		return
+	}
+	respond(200, rbody.SchedulerQueueResizedFromStats(name, st), w)
+}
+
+// getSchedulerSpool describes the spool and the entries it holds, those of
+// a single task if the task query parameter is set
+func (s *Server) getSchedulerSpool(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	st, err := s.mt.GetSpool(r.URL.Query().Get("task"))
+	if err != nil {
+		respondSpoolError(err, w)
+		return
+	}
+	respond(200, rbody.SchedulerSpoolFromStats(st), w)
+}
+
+// purgeSchedulerSpool removes the entries of the spool, those of a single
+// task if the task query parameter is set
+func (s *Server) purgeSchedulerSpool(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	taskID := r.URL.Query().Get("task")
+	n, err := s.mt.PurgeSpool(taskID)
+	if err != nil {
+		respondSpoolError(err, w)
+		return
+	}
+	respond(200, &rbody.SchedulerSpoolPurged{TaskID: taskID, Purged: n}, w)
+}
+
+func respondSpoolError(err error, w http.ResponseWriter) {
+	if err == scheduler.ErrSpoolDisabled {
+		respond(404, rbody.FromError(err), w)
+		return
+	}
+	respond(500, rbody.FromError(err), w)
+}
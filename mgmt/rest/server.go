@@ -59,10 +59,15 @@ const (
 	defaultAuth            bool   = false
 	defaultAuthPassword    string = ""
 	defaultPortSetByConfig bool   = false
+	defaultMetricsAddress  string = ""
+	defaultMetricsAuth     bool   = false
 )
 
 var (
 	ErrBadCert = errors.New("Invalid certificate given")
+	// ErrMetricsAuthPassword - error message when authentication is enabled
+	// for the metrics listener without a password
+	ErrMetricsAuthPassword = errors.New("Metrics listener authentication requires a password")
 
 	restLogger     = log.WithField("_module", "_mgmt-rest")
 	protocolPrefix = "http"
@@ -81,7 +86,13 @@ type Config struct {
 	RestKey          string `json:"rest_key"yaml:"rest_key"`
 	RestAuth         bool   `json:"rest_auth"yaml:"rest_auth"`
 	RestAuthPassword string `json:"rest_auth_password"yaml:"rest_auth_password"`
-	portSetByConfig  bool   ``
+	// MetricsAddress is the address[:port] the listener serving the
+	// internal metrics of snapd on /metrics binds to, the listener being
+	// disabled if it is empty
+	MetricsAddress      string `json:"metrics_addr"yaml:"metrics_addr"`
+	MetricsAuth         bool   `json:"metrics_auth"yaml:"metrics_auth"`
+	MetricsAuthPassword string `json:"metrics_auth_password"yaml:"metrics_auth_password"`
+	portSetByConfig     bool   ``
 }
 
 const (
@@ -114,6 +125,15 @@ const (
 					},
 					"addr" : {
 						"type": "string"
+					},
+					"metrics_addr" : {
+						"type": "string"
+					},
+					"metrics_auth": {
+						"type": "boolean"
+					},
+					"metrics_auth_password": {
+						"type": "string"
 					}
 				},
 				"additionalProperties": false
@@ -131,6 +151,7 @@ type managesMetrics interface {
 	PluginCatalog() core.PluginCatalog
 	AvailablePlugins() []core.AvailablePlugin
 	GetAutodiscoverPaths() []string
+	PluginPoolStats() []core.PluginPoolStats
 }
 
 type managesTasks interface {
@@ -142,6 +163,14 @@ type managesTasks interface {
 	RemoveTask(string) error
 	WatchTask(string, core.TaskWatcherHandler) (core.TaskWatcherCloser, error)
 	EnableTask(string) (core.Task, error)
+	UpdateTask(string, cschedule.Schedule, *wmap.WorkflowMap) (core.Task, []serror.SnapError)
+	GetTaskHistory(string) ([]core.TaskRun, error)
+	GetTaskLatencies(string) (core.TaskLatencies, error)
+	FireTask(string, bool) (core.TaskRun, []serror.SnapError)
+	QueueStats() map[string]core.WorkQueueStats
+	ResizeWorkQueue(name string, limit, workers uint) (core.WorkQueueStats, error)
+	GetSpool(taskID string) (core.SpoolStats, error)
+	PurgeSpool(taskID string) (int, error)
 }
 
 type managesTribe interface {
@@ -171,6 +200,7 @@ type Server struct {
 	mc         managesConfig
 	n          *negroni.Negroni
 	r          *httprouter.Router
+	logger     *Logger
 	snapTLS    *snapTLS
 	auth       bool
 	authpwd    string
@@ -182,6 +212,8 @@ type Server struct {
 	// the following instance variables are used to cleanly shutdown the server
 	serverListener net.Listener
 	closingChan    chan bool
+	// the listener serving the internal metrics of snapd
+	metrics *metricsListener
 }
 
 // New creates a REST API server with a given config
@@ -205,14 +237,22 @@ func New(cfg *Config) (*Server, error) {
 	}
 
 	restLogger.Info(fmt.Sprintf("Configuring REST API with HTTPS set to: %v", https))
+	s.logger = NewLogger()
 	s.n = negroni.New(
-		NewLogger(),
+		s.logger,
 		negroni.NewRecovery(),
 		negroni.HandlerFunc(s.authMiddleware),
 	)
 	s.r = httprouter.New()
 	// Use negroni to handle routes
 	s.n.UseHandler(s.r)
+
+	if cfg.MetricsAddress != "" {
+		if cfg.MetricsAuth && cfg.MetricsAuthPassword == "" {
+			return nil, ErrMetricsAuthPassword
+		}
+		s.metrics = newMetricsListener(s, cfg.MetricsAddress, cfg.MetricsAuth, cfg.MetricsAuthPassword)
+	}
 	return s, nil
 }
 
@@ -227,6 +267,8 @@ func GetDefaultConfig() *Config {
 		RestKey:          defaultRestKey,
 		RestAuth:         defaultAuth,
 		RestAuthPassword: defaultAuthPassword,
+		MetricsAddress:   defaultMetricsAddress,
+		MetricsAuth:      defaultMetricsAuth,
 		portSetByConfig:  defaultPortSetByConfig,
 	}
 }
@@ -284,6 +326,18 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 			if err := json.Unmarshal(v, &(c.RestAuthPassword)); err != nil {
 				return fmt.Errorf("%v (while parsing 'restapi::rest_auth_password')", err)
 			}
+		case "metrics_addr":
+			if err := json.Unmarshal(v, &(c.MetricsAddress)); err != nil {
+				return fmt.Errorf("%v (while parsing 'restapi::metrics_addr')", err)
+			}
+		case "metrics_auth":
+			if err := json.Unmarshal(v, &(c.MetricsAuth)); err != nil {
+				return fmt.Errorf("%v (while parsing 'restapi::metrics_auth')", err)
+			}
+		case "metrics_auth_password":
+			if err := json.Unmarshal(v, &(c.MetricsAuthPassword)); err != nil {
+				return fmt.Errorf("%v (while parsing 'restapi::metrics_auth_password')", err)
+			}
 		default:
 			return fmt.Errorf("Unrecognized key '%v' in global config file while parsing 'restapi'", k)
 		}
@@ -319,6 +373,12 @@ func (s *Server) authMiddleware(rw http.ResponseWriter, r *http.Request, next ht
 	}
 }
 
+// RequestCounts returns the number of requests served by the REST API and of
+// the ones answered with an error
+func (s *Server) RequestCounts() (requests, errors uint64) {
+	return s.logger.Counts()
+}
+
 func (s *Server) SetAddress(addrString string) {
 	s.addrString = addrString
 	restLogger.Info(fmt.Sprintf("Address used for binding: [%v]", s.addrString))
@@ -332,6 +392,11 @@ func (s *Server) Start() error {
 	s.closingChan = make(chan bool, 1)
 	s.addRoutes()
 	s.run(s.addrString)
+	if s.metrics != nil {
+		if err := s.metrics.start(); err != nil {
+			return err
+		}
+	}
 	restLogger.WithFields(log.Fields{
 		"_block": "start",
 	}).Info("REST started")
@@ -346,6 +411,9 @@ func (s *Server) Stop() {
 	close(s.killChan)
 	// close the server listener
 	s.serverListener.Close()
+	if s.metrics != nil {
+		s.metrics.stop()
+	}
 	// wait for the server goroutines to complete (serve and watch)
 	s.wg.Wait()
 	// finally log the result
@@ -474,11 +542,21 @@ func (s *Server) addRoutes() {
 	s.r.GET("/v1/tasks", s.getTasks)
 	s.r.GET("/v1/tasks/:id", s.getTask)
 	s.r.GET("/v1/tasks/:id/watch", s.watchTask)
+	s.r.GET("/v1/tasks/:id/history", s.getTaskHistory)
+	s.r.GET("/v1/tasks/:id/latency", s.getTaskLatencies)
 	s.r.POST("/v1/tasks", s.addTask)
 	s.r.PUT("/v1/tasks/:id/start", s.startTask)
 	s.r.PUT("/v1/tasks/:id/stop", s.stopTask)
 	s.r.DELETE("/v1/tasks/:id", s.removeTask)
 	s.r.PUT("/v1/tasks/:id/enable", s.enableTask)
+	s.r.PATCH("/v1/tasks/:id", s.updateTask)
+	s.r.POST("/v1/tasks/:id/fire", s.fireTask)
+
+	// scheduler routes
+	s.r.GET("/v1/scheduler/queues", s.getSchedulerQueues)
+	s.r.PUT("/v1/scheduler/queues/:name", s.resizeSchedulerQueue)
+	s.r.GET("/v1/scheduler/spool", s.getSchedulerSpool)
+	s.r.DELETE("/v1/scheduler/spool", s.purgeSchedulerSpool)
 
 	// tribe routes
 	if s.tr != nil {
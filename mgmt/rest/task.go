@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/mgmt/rest/rbody"
+	"github.com/intelsdi-x/snap/pkg/schedule"
 )
 
 var (
@@ -41,6 +43,11 @@ var (
 	ErrStreamingUnsupported    = errors.New("Streaming unsupported")
 	ErrTaskNotFound            = errors.New("Task not found")
 	ErrTaskDisabledNotRunnable = errors.New("Task is disabled. Cannot be started")
+	ErrTaskUpdateEmpty         = errors.New("Task update must include a schedule or a workflow")
+	ErrTaskNotRunning          = errors.New("Task is not running")
+	ErrTaskDisabledNotFireable = errors.New("Task is disabled. Cannot be fired")
+	ErrTaskBusy                = errors.New("Task is busy. Cannot be fired")
+	ErrTaskClientsUnavailable  = errors.New("Task clients unavailable")
 )
 
 func (s *Server) addTask(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -83,6 +90,26 @@ func (s *Server) getTask(w http.ResponseWriter, r *http.Request, p httprouter.Pa
 	respond(200, task, w)
 }
 
+func (s *Server) getTaskHistory(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	id := p.ByName("id")
+	runs, err := s.mt.GetTaskHistory(id)
+	if err != nil {
+		respond(404, rbody.FromError(err), w)
+		return
+	}
+	respond(200, rbody.ScheduledTaskHistoryFromRuns(id, runs), w)
+}
+
+func (s *Server) getTaskLatencies(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	id := p.ByName("id")
+	l, err := s.mt.GetTaskLatencies(id)
+	if err != nil {
+		respond(404, rbody.FromError(err), w)
+		return
+	}
+	respond(200, rbody.ScheduledTaskLatenciesFromLatencies(id, l), w)
+}
+
 func (s *Server) watchTask(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	s.wg.Add(1)
 	defer s.wg.Done()
@@ -253,6 +280,78 @@ func (s *Server) enableTask(w http.ResponseWriter, r *http.Request, p httprouter
 	respond(200, task, w)
 }
 
+// updateTask replaces the schedule and/or the workflow of a task
+func (s *Server) updateTask(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	id := p.ByName("id")
+	tr := &core.TaskUpdateRequest{}
+	errCode, err := core.UnmarshalBody(tr, r.Body)
+	if errCode != 0 && err != nil {
+		respond(errCode, rbody.FromError(err), w)
+		return
+	}
+	var sch schedule.Schedule
+	if tr.Schedule != nil {
+		sch, err = core.MakeSchedule(*tr.Schedule)
+		if err != nil {
+			respond(400, rbody.FromError(err), w)
+			return
+		}
+	}
+	if sch == nil && tr.Workflow == nil {
+		respond(400, rbody.FromError(ErrTaskUpdateEmpty), w)
+		return
+	}
+	tsk, errs := s.mt.UpdateTask(id, sch, tr.Workflow)
+	if errs != nil {
+		if strings.Contains(errs[0].Error(), ErrTaskNotFound.Error()) {
+			respond(404, rbody.FromSnapErrors(errs), w)
+			return
+		}
+		if strings.Contains(errs[0].Error(), ErrTaskClientsUnavailable.Error()) {
+			respond(500, rbody.FromSnapErrors(errs), w)
+			return
+		}
+		// the schedule, the workflow or its dependencies were rejected
+		respond(400, rbody.FromSnapErrors(errs), w)
+		return
+	}
+	task := &rbody.ScheduledTaskUpdated{}
+	task.AddScheduledTask = *rbody.AddSchedulerTaskFromTask(tsk)
+	task.Href = taskURI(r.Host, tsk)
+	respond(200, task, w)
+}
+
+// fireTask runs the workflow of a task once and responds with the record of
+// the run. A task which is not running is only fired with ?force=true.
+func (s *Server) fireTask(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	id := p.ByName("id")
+	force := false
+	if v := r.URL.Query().Get("force"); v != "" {
+		var err error
+		force, err = strconv.ParseBool(v)
+		if err != nil {
+			respond(400, rbody.FromError(fmt.Errorf("invalid value for force: %v", v)), w)
+			return
+		}
+	}
+	run, errs := s.mt.FireTask(id, force)
+	if errs != nil {
+		if strings.Contains(errs[0].Error(), ErrTaskNotFound.Error()) {
+			respond(404, rbody.FromSnapErrors(errs), w)
+			return
+		}
+		if strings.Contains(errs[0].Error(), ErrTaskNotRunning.Error()) ||
+			strings.Contains(errs[0].Error(), ErrTaskDisabledNotFireable.Error()) ||
+			strings.Contains(errs[0].Error(), ErrTaskBusy.Error()) {
+			respond(409, rbody.FromSnapErrors(errs), w)
+			return
+		}
+		respond(500, rbody.FromSnapErrors(errs), w)
+		return
+	}
+	respond(200, rbody.ScheduledTaskFiredFromRun(id, run), w)
+}
+
 type TaskWatchHandler struct {
 	streamCount int
 	alive       bool
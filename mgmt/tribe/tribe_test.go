@@ -61,6 +61,9 @@ func (t *mockTask) SetName(string)                            { return }
 func (t *mockTask) SetID(string)                              { return }
 func (t *mockTask) MissedCount() uint                         { return 0 }
 func (t *mockTask) FailedCount() uint                         { return 0 }
+func (t *mockTask) OverflowCount() uint                       { return 0 }
+func (t *mockTask) DeadlineExceededCount() uint               { return 0 }
+func (t *mockTask) BusySkipCount() uint                       { return 0 }
 func (t *mockTask) LastFailureMessage() string                { return "" }
 func (t *mockTask) LastRunTime() *time.Time                   { return nil }
 func (t *mockTask) CreationTime() *time.Time                  { return nil }
@@ -69,6 +72,21 @@ func (t *mockTask) SetDeadlineDuration(time.Duration)         { return }
 func (t *mockTask) SetTaskID(id string)                       { return }
 func (t *mockTask) SetStopOnFailure(int)                      { return }
 func (t *mockTask) GetStopOnFailure() int                     { return 0 }
+func (t *mockTask) SetCollectPolicy(core.CollectPolicy)       { return }
+func (t *mockTask) GetCollectPolicy() core.CollectPolicy      { return core.CollectAllOrNothing }
+func (t *mockTask) SetFailurePolicy(core.FailurePolicy)       { return }
+func (t *mockTask) GetFailurePolicy() core.FailurePolicy      { return core.FailurePolicy{} }
+func (t *mockTask) BackoffLevel() uint                        { return 0 }
+func (t *mockTask) SetCatchupPolicy(core.CatchupPolicy)       {}
+func (t *mockTask) GetCatchupPolicy() core.CatchupPolicy      { return core.CatchupPolicy{} }
+func (t *mockTask) SetPriority(core.TaskPriority)             {}
+func (t *mockTask) GetPriority() core.TaskPriority            { return core.PriorityNormal }
+func (t *mockTask) SetOverlapPolicy(core.OverlapPolicy)       {}
+func (t *mockTask) GetOverlapPolicy() core.OverlapPolicy      { return core.OverlapPolicy{} }
+func (t *mockTask) SetLatencySLO(time.Duration)               {}
+func (t *mockTask) LatencySLO() time.Duration                 { return 0 }
+func (t *mockTask) SLOBreachCount() uint                      { return 0 }
+func (t *mockTask) LastRunDuration() time.Duration            { return 0 }
 func (t *mockTask) Option(...core.TaskOption) core.TaskOption { return core.TaskDeadlineDuration(0) }
 func (t *mockTask) WMap() *wmap.WorkflowMap                   { return nil }
 func (t *mockTask) Schedule() schedule.Schedule               { return nil }
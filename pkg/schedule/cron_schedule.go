@@ -69,7 +69,7 @@ func (c *CronSchedule) Validate() error {
 }
 
 // Wait waits as long as specified in cron entry
-func (c *CronSchedule) Wait(last time.Time) Response {
+func (c *CronSchedule) Wait(last time.Time, stop <-chan struct{}) Response {
 	var err error
 	now := time.Now()
 
@@ -102,7 +102,7 @@ func (c *CronSchedule) Wait(last time.Time) Response {
 
 		// wait
 		waitTime := s.Next(now)
-		time.Sleep(waitTime.Sub(now))
+		err = sleep(waitTime.Sub(now), stop)
 	}
 
 	return &CronScheduleResponse{
@@ -113,6 +113,20 @@ func (c *CronSchedule) Wait(last time.Time) Response {
 	}
 }
 
+// MissedTimes returns the times the entries missed after last were due at
+func (c *CronSchedule) MissedTimes(last time.Time, missed uint) []time.Time {
+	s, err := cron.Parse(c.entry)
+	if err != nil {
+		return nil
+	}
+	times := make([]time.Time, 0, missed)
+	for next := last; uint(len(times)) < missed; {
+		next = s.Next(next)
+		times = append(times, next)
+	}
+	return times
+}
+
 // CronScheduleResponse is the response from CronSchedule
 type CronScheduleResponse struct {
 	state    ScheduleState
@@ -51,7 +51,7 @@ func TestCronSchedule(t *testing.T) {
 			i := "@every 1s"
 			c := NewCronSchedule(i)
 			now := time.Now()
-			r := c.Wait(now)
+			r := c.Wait(now, nil)
 			So(r, ShouldNotBeNil)
 			So(r.State(), ShouldEqual, Active)
 			So(r.Error(), ShouldBeNil)
@@ -64,9 +64,9 @@ func TestCronSchedule(t *testing.T) {
 			i := "@every 1s"
 			c := NewCronSchedule(i)
 			now := time.Now()
-			r := c.Wait(now)
+			r := c.Wait(now, nil)
 			then := now.Add(-time.Duration(10) * time.Second)
-			r = c.Wait(then)
+			r = c.Wait(then, nil)
 			So(r, ShouldNotBeNil)
 			So(r.State(), ShouldEqual, Active)
 			So(r.Error(), ShouldBeNil)
@@ -75,5 +75,24 @@ func TestCronSchedule(t *testing.T) {
 			l := lastTime.After(now) && lastTime.Before(time.Now())
 			So(l, ShouldBeTrue)
 		})
+		Convey("times of the missed entries", func() {
+			c := NewCronSchedule("0 30 * * * *")
+			last := time.Date(2016, 1, 1, 10, 45, 0, 0, time.UTC)
+			times := c.MissedTimes(last, 2)
+			So(len(times), ShouldEqual, 2)
+			So(times[0].Equal(time.Date(2016, 1, 1, 11, 30, 0, 0, time.UTC)), ShouldBeTrue)
+			So(times[1].Equal(time.Date(2016, 1, 1, 12, 30, 0, 0, time.UTC)), ShouldBeTrue)
+		})
+		Convey("cancelled Wait()", func() {
+			i := "@every 1h"
+			c := NewCronSchedule(i)
+			stop := make(chan struct{})
+			time.AfterFunc(time.Millisecond*10, func() { close(stop) })
+
+			before := time.Now()
+			r := c.Wait(time.Now(), stop)
+			So(time.Since(before), ShouldBeLessThan, time.Second)
+			So(r.Error(), ShouldEqual, ErrWaitCancelled)
+		})
 	})
 }
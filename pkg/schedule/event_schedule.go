@@ -0,0 +1,254 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultEventMinInterval is the shortest time between two fires of an event
+// schedule, unless it is set otherwise
+const DefaultEventMinInterval = time.Second
+
+var (
+	// ErrMissingEvents - Error message for an event schedule without the events firing it
+	ErrMissingEvents = errors.New("Event schedule is missing the events firing it")
+	// ErrInvalidMinInterval - Error message for a negative minimum interval between the fires of an event schedule
+	ErrInvalidMinInterval = errors.New("Minimum interval must be positive")
+)
+
+// EventSchedule is a schedule which fires when the events it is notified of
+// match one of its event namespaces and its filter. The fires are rate
+// limited: the events received within the minimum interval of the last fire
+// are held back and fire the schedule once the interval has passed.
+type EventSchedule struct {
+	// Events are the namespaces of the events firing the schedule, like
+	// Control.PluginLoaded, which may contain wildcards like Control.*
+	Events []string
+	// Filter is an optional expression the fields of the events must match,
+	// like Name == "mock" && Version != 1
+	Filter string
+	// MinInterval is the shortest time between two fires
+	MinInterval time.Duration
+
+	state      ScheduleState
+	conditions []eventCondition
+	triggers   *triggers
+}
+
+// NewEventSchedule returns a schedule fired by the events with the given
+// namespaces
+func NewEventSchedule(events []string, filter string) *EventSchedule {
+	return &EventSchedule{
+		Events:      events,
+		Filter:      filter,
+		MinInterval: DefaultEventMinInterval,
+		triggers:    newTriggers(),
+	}
+}
+
+// GetState returns the state of the schedule
+func (e *EventSchedule) GetState() ScheduleState {
+	return e.state
+}
+
+// Validate returns an error if the schedule has no events, an event
+// namespace is not a valid pattern or the filter cannot be parsed
+func (e *EventSchedule) Validate() error {
+	if len(e.Events) == 0 {
+		return ErrMissingEvents
+	}
+	for _, ns := range e.Events {
+		if _, err := path.Match(ns, ""); err != nil {
+			return fmt.Errorf("bad event namespace %s: %v", ns, err)
+		}
+	}
+	if e.MinInterval < 0 {
+		return ErrInvalidMinInterval
+	}
+	conditions, err := parseEventFilter(e.Filter)
+	if err != nil {
+		return err
+	}
+	e.conditions = conditions
+	return nil
+}
+
+// Notify fires the schedule if the event with the given namespace and body
+// matches it. It never blocks.
+func (e *EventSchedule) Notify(namespace string, body interface{}) {
+	if e.Matches(namespace, body) {
+		e.triggers.fire()
+	}
+}
+
+// Matches returns whether the event with the given namespace and body fires
+// the schedule. The filter is only applied once the schedule is validated.
+func (e *EventSchedule) Matches(namespace string, body interface{}) bool {
+	matched := false
+	for _, ns := range e.Events {
+		if ok, _ := path.Match(ns, namespace); ok {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return false
+	}
+	for _, c := range e.conditions {
+		if !c.matches(body) {
+			return false
+		}
+	}
+	return true
+}
+
+// Wait blocks until the minimum interval since last has passed and an event
+// fired the schedule. The events held back are fired by the same response, so
+// none is missed.
+func (e *EventSchedule) Wait(last time.Time, stop <-chan struct{}) Response {
+	var held uint
+	var err error
+	if (last != time.Time{}) {
+		if d := e.MinInterval - time.Since(last); d > 0 {
+			err = sleep(d, stop)
+		}
+	}
+	if err == nil {
+		held, err = e.triggers.wait(stop)
+	}
+	return &EventScheduleResponse{
+		state:    e.GetState(),
+		err:      err,
+		held:     held,
+		lastTime: time.Now(),
+	}
+}
+
+// EventField returns the value of the field of an event body with the given
+// name, matched regardless of case
+func EventField(body interface{}, name string) (interface{}, bool) {
+	v := reflect.ValueOf(body)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+	f := v.FieldByNameFunc(func(n string) bool {
+		return strings.EqualFold(n, name)
+	})
+	if !f.IsValid() || !f.CanInterface() {
+		return nil, false
+	}
+	return f.Interface(), true
+}
+
+// eventCondition is a comparison of a field of an event with a value
+type eventCondition struct {
+	field string
+	equal bool
+	value string
+}
+
+func (c eventCondition) matches(body interface{}) bool {
+	v, ok := EventField(body, c.field)
+	if !ok {
+		return false
+	}
+	return (fmt.Sprint(v) == c.value) == c.equal
+}
+
+// parseEventFilter parses a filter made of comparisons joined by &&. Each
+// comparison is a field name, == or !=, and a value which may be quoted.
+func parseEventFilter(filter string) ([]eventCondition, error) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	}
+	conditions := []eventCondition{}
+	for _, term := range strings.Split(filter, "&&") {
+		c := eventCondition{}
+		var parts []string
+		if parts = strings.SplitN(term, "!=", 2); len(parts) == 2 {
+			c.equal = false
+		} else if parts = strings.SplitN(term, "==", 2); len(parts) == 2 {
+			c.equal = true
+		} else {
+			return nil, fmt.Errorf("bad event filter %s: expected field == value or field != value", strings.TrimSpace(term))
+		}
+		c.field = strings.TrimSpace(parts[0])
+		if c.field == "" || strings.ContainsAny(c.field, " \t\"") {
+			return nil, fmt.Errorf("bad event filter %s: bad field name", strings.TrimSpace(term))
+		}
+		c.value = strings.TrimSpace(parts[1])
+		if strings.HasPrefix(c.value, "\"") {
+			v, err := strconv.Unquote(c.value)
+			if err != nil {
+				return nil, fmt.Errorf("bad event filter %s: %v", strings.TrimSpace(term), err)
+			}
+			c.value = v
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions, nil
+}
+
+// EventScheduleResponse is the response from EventSchedule
+type EventScheduleResponse struct {
+	state    ScheduleState
+	err      error
+	held     uint
+	lastTime time.Time
+}
+
+// State returns the state of the Schedule
+func (e *EventScheduleResponse) State() ScheduleState {
+	return e.state
+}
+
+// Error returns last error
+func (e *EventScheduleResponse) Error() error {
+	return e.err
+}
+
+// Missed always returns 0: the events received since the last fire are held
+// back rather than missed
+func (e *EventScheduleResponse) Missed() uint {
+	return 0
+}
+
+// Held returns the events held back since the last fire, besides the one
+// firing the schedule
+func (e *EventScheduleResponse) Held() uint {
+	return e.held
+}
+
+// LastTime returns the time the schedule fired
+func (e *EventScheduleResponse) LastTime() time.Time {
+	return e.lastTime
+}
@@ -0,0 +1,108 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type mockEvent struct {
+	Name    string
+	Version int
+}
+
+func TestEventSchedule(t *testing.T) {
+	Convey("Event Schedule", t, func() {
+		Convey("valid events and filter", func() {
+			s := NewEventSchedule([]string{"Control.*"}, `Name == "mock" && Version != 1`)
+			So(s.Validate(), ShouldBeNil)
+			So(s.MinInterval, ShouldEqual, DefaultEventMinInterval)
+		})
+		Convey("missing events", func() {
+			s := NewEventSchedule(nil, "")
+			So(s.Validate(), ShouldEqual, ErrMissingEvents)
+		})
+		Convey("bad event namespace", func() {
+			s := NewEventSchedule([]string{"Control.["}, "")
+			So(s.Validate(), ShouldNotBeNil)
+		})
+		Convey("bad filter", func() {
+			for _, f := range []string{"Name", "== mock", `Name == "mock`, "Name == mock &&"} {
+				s := NewEventSchedule([]string{"Control.PluginLoaded"}, f)
+				So(s.Validate(), ShouldNotBeNil)
+			}
+		})
+		Convey("negative minimum interval", func() {
+			s := NewEventSchedule([]string{"Control.PluginLoaded"}, "")
+			s.MinInterval = -time.Second
+			So(s.Validate(), ShouldEqual, ErrInvalidMinInterval)
+		})
+		Convey("matching events", func() {
+			s := NewEventSchedule([]string{"Control.PluginLoaded", "Control.Plugins*"}, `name == "mock" && Version != 1`)
+			So(s.Validate(), ShouldBeNil)
+			So(s.Matches("Control.PluginLoaded", &mockEvent{Name: "mock", Version: 2}), ShouldBeTrue)
+			So(s.Matches("Control.PluginsSwapped", mockEvent{Name: "mock", Version: 2}), ShouldBeTrue)
+			So(s.Matches("Control.PluginUnloaded", &mockEvent{Name: "mock", Version: 2}), ShouldBeFalse)
+			So(s.Matches("Control.PluginLoaded", &mockEvent{Name: "mock", Version: 1}), ShouldBeFalse)
+			So(s.Matches("Control.PluginLoaded", &mockEvent{Name: "file", Version: 2}), ShouldBeFalse)
+			So(s.Matches("Control.PluginLoaded", struct{}{}), ShouldBeFalse)
+		})
+		Convey("wait until notified", func() {
+			s := NewEventSchedule([]string{"Control.PluginLoaded"}, "")
+			So(s.Validate(), ShouldBeNil)
+			time.AfterFunc(time.Millisecond*10, func() {
+				s.Notify("Control.PluginUnloaded", &mockEvent{})
+				s.Notify("Control.PluginLoaded", &mockEvent{})
+			})
+			r := s.Wait(time.Time{}, nil)
+			So(r.State(), ShouldEqual, Active)
+			So(r.Error(), ShouldBeNil)
+			So(r.Missed(), ShouldEqual, 0)
+		})
+		Convey("hold back the events within the minimum interval", func() {
+			s := NewEventSchedule([]string{"Control.PluginLoaded"}, "")
+			s.MinInterval = time.Millisecond * 100
+			So(s.Validate(), ShouldBeNil)
+			for i := 0; i < 5; i++ {
+				s.Notify("Control.PluginLoaded", &mockEvent{})
+			}
+			last := time.Now()
+			r := s.Wait(last, nil)
+			So(r.Error(), ShouldBeNil)
+			So(r.Missed(), ShouldEqual, 0)
+			So(r.(*EventScheduleResponse).Held(), ShouldEqual, 4)
+			So(r.LastTime().Sub(last), ShouldBeGreaterThanOrEqualTo, s.MinInterval)
+		})
+		Convey("cancelled Wait()", func() {
+			s := NewEventSchedule([]string{"Control.PluginLoaded"}, "")
+			stop := make(chan struct{})
+			time.AfterFunc(time.Millisecond*10, func() { close(stop) })
+
+			before := time.Now()
+			r := s.Wait(time.Now(), stop)
+			So(time.Since(before), ShouldBeLessThan, time.Second)
+			So(r.Error(), ShouldEqual, ErrWaitCancelled)
+		})
+	})
+}
@@ -0,0 +1,92 @@
+package schedule
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidDailyTime - Error message for a daily window start or stop which is not a time of day
+	ErrInvalidDailyTime = errors.New("Daily window start and stop must be times of day from 00:00 to 23:59")
+	// ErrInvalidWeekday - Error message for a recurring window day which is not a day of the week
+	ErrInvalidWeekday = errors.New("Recurring window days must be days of the week")
+)
+
+// RecurringWindow is a window of time which opens every day, or on some days
+// of the week only, between the same times of day.
+type RecurringWindow struct {
+	// Days are the days of the week the window opens on, every day if empty
+	Days []time.Weekday
+	// Start and Stop are the times of day, as durations since midnight, the
+	// window opens and closes at. A window which stops before it starts
+	// closes the next day, and one which stops when it starts lasts all day.
+	Start time.Duration
+	Stop  time.Duration
+	// Location is the time zone of the times of day, the local one if nil
+	Location *time.Location
+}
+
+// Validate validates the days and times of day of the window
+func (r *RecurringWindow) Validate() error {
+	for _, d := range []time.Duration{r.Start, r.Stop} {
+		if d < 0 || d >= 24*time.Hour {
+			return ErrInvalidDailyTime
+		}
+	}
+	for _, d := range r.Days {
+		if d < time.Sunday || d > time.Saturday {
+			return ErrInvalidWeekday
+		}
+	}
+	return nil
+}
+
+// Next returns the times the window opens and closes at, for the window open
+// at t or, if the window is closed at t, the next one to open.
+func (r *RecurringWindow) Next(t time.Time) (time.Time, time.Time) {
+	loc := r.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	t = t.In(loc)
+	// starting the day before covers a window which opened yesterday and
+	// closes today
+	for day := -1; day <= 7; day++ {
+		midnight := time.Date(t.Year(), t.Month(), t.Day()+day, 0, 0, 0, 0, loc)
+		if !r.opensOn(midnight.Weekday()) {
+			continue
+		}
+		opens := atTimeOfDay(midnight, r.Start)
+		closes := atTimeOfDay(midnight, r.Stop)
+		if !closes.After(opens) {
+			closes = atTimeOfDay(midnight.AddDate(0, 0, 1), r.Stop)
+		}
+		if closes.After(t) {
+			return opens, closes
+		}
+	}
+	// only reached if none of the days are valid
+	return t, t
+}
+
+func (r *RecurringWindow) opensOn(d time.Weekday) bool {
+	if len(r.Days) == 0 {
+		return true
+	}
+	for _, day := range r.Days {
+		if day == d {
+			return true
+		}
+	}
+	return false
+}
+
+// atTimeOfDay returns the time of the given day which is d past its midnight
+// on the wall clock, which differs from adding d to midnight on the days
+// daylight saving time starts or ends.
+func atTimeOfDay(midnight time.Time, d time.Duration) time.Time {
+	h := d / time.Hour
+	m := (d % time.Hour) / time.Minute
+	s := (d % time.Minute) / time.Second
+	return time.Date(midnight.Year(), midnight.Month(), midnight.Day(), int(h), int(m), int(s), 0, midnight.Location())
+}
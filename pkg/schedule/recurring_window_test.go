@@ -0,0 +1,141 @@
+// +build legacy
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// sinceMidnight returns the time of day of t as a duration since midnight
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+func TestRecurringWindow(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+	Convey("Recurring window", t, func() {
+		r := &RecurringWindow{
+			Days:     weekdays,
+			Start:    8 * time.Hour,
+			Stop:     18 * time.Hour,
+			Location: berlin,
+		}
+		So(r.Validate(), ShouldBeNil)
+
+		Convey("returns the window open at the given time", func() {
+			// Wednesday
+			opens, closes := r.Next(time.Date(2016, 6, 15, 12, 0, 0, 0, berlin))
+			So(opens, ShouldResemble, time.Date(2016, 6, 15, 8, 0, 0, 0, berlin))
+			So(closes, ShouldResemble, time.Date(2016, 6, 15, 18, 0, 0, 0, berlin))
+		})
+		Convey("returns the next window when closed", func() {
+			opens, closes := r.Next(time.Date(2016, 6, 15, 18, 0, 0, 0, berlin))
+			So(opens, ShouldResemble, time.Date(2016, 6, 16, 8, 0, 0, 0, berlin))
+			So(closes, ShouldResemble, time.Date(2016, 6, 16, 18, 0, 0, 0, berlin))
+		})
+		Convey("skips the days it does not open on", func() {
+			// Friday evening
+			opens, _ := r.Next(time.Date(2016, 6, 17, 19, 0, 0, 0, berlin))
+			So(opens, ShouldResemble, time.Date(2016, 6, 20, 8, 0, 0, 0, berlin))
+		})
+		Convey("uses the time zone of the window", func() {
+			opens, _ := r.Next(time.Date(2016, 6, 15, 5, 0, 0, 0, time.UTC))
+			So(opens.Equal(time.Date(2016, 6, 15, 6, 0, 0, 0, time.UTC)), ShouldBeTrue)
+		})
+		Convey("keeps the time of day across daylight saving time changes", func() {
+			sundays := &RecurringWindow{Days: []time.Weekday{time.Sunday}, Start: 2 * time.Hour, Stop: 4 * time.Hour, Location: berlin}
+			// the Sunday before and the Sunday daylight saving time ends
+			opens, _ := sundays.Next(time.Date(2016, 10, 25, 0, 0, 0, 0, berlin))
+			So(opens, ShouldResemble, time.Date(2016, 10, 30, 2, 0, 0, 0, berlin))
+			So(opens.Add(-time.Hour).Hour(), ShouldEqual, 2)
+		})
+		Convey("closes the next day if it stops before it starts", func() {
+			nights := &RecurringWindow{Days: []time.Weekday{time.Friday}, Start: 22 * time.Hour, Stop: 2 * time.Hour, Location: berlin}
+			// Saturday after midnight is within the window opened on Friday
+			opens, closes := nights.Next(time.Date(2016, 6, 18, 1, 0, 0, 0, berlin))
+			So(opens, ShouldResemble, time.Date(2016, 6, 17, 22, 0, 0, 0, berlin))
+			So(closes, ShouldResemble, time.Date(2016, 6, 18, 2, 0, 0, 0, berlin))
+		})
+		Convey("lasts all day if it stops when it starts", func() {
+			allDay := &RecurringWindow{Days: []time.Weekday{time.Sunday}, Location: berlin}
+			opens, closes := allDay.Next(time.Date(2016, 6, 15, 12, 0, 0, 0, berlin))
+			So(opens, ShouldResemble, time.Date(2016, 6, 19, 0, 0, 0, 0, berlin))
+			So(closes, ShouldResemble, time.Date(2016, 6, 20, 0, 0, 0, 0, berlin))
+		})
+		Convey("is invalid with a time past the end of the day", func() {
+			r.Stop = 24 * time.Hour
+			So(r.Validate(), ShouldEqual, ErrInvalidDailyTime)
+		})
+		Convey("is invalid with a day which is not a day of the week", func() {
+			r.Days = []time.Weekday{7}
+			So(r.Validate(), ShouldEqual, ErrInvalidWeekday)
+		})
+	})
+
+	Convey("Windowed schedule with a recurring window", t, func() {
+		Convey("fires on the interval while the window is open", func() {
+			now := time.Now().UTC()
+			w := NewWindowedSchedule(time.Millisecond*20, nil, nil)
+			w.Recurring = &RecurringWindow{
+				Start:    sinceMidnight(now.Add(-time.Minute)),
+				Stop:     sinceMidnight(now.Add(time.Minute)),
+				Location: time.UTC,
+			}
+			So(w.Validate(), ShouldBeNil)
+
+			before := time.Now()
+			r := w.Wait(time.Now(), nil)
+			So(r.Error(), ShouldBeNil)
+			So(r.State(), ShouldEqual, Active)
+			So(r.Missed(), ShouldEqual, 0)
+			So(time.Since(before), ShouldBeLessThan, time.Second)
+		})
+		Convey("does not count the intervals between windows as missed", func() {
+			now := time.Now().UTC()
+			w := NewWindowedSchedule(time.Millisecond*20, nil, nil)
+			w.Recurring = &RecurringWindow{
+				Start:    sinceMidnight(now.Add(-time.Minute)),
+				Stop:     sinceMidnight(now.Add(time.Minute)),
+				Location: time.UTC,
+			}
+			r := w.Wait(time.Now().Add(-time.Hour), nil)
+			So(r.Error(), ShouldBeNil)
+			So(r.Missed(), ShouldEqual, 0)
+		})
+		Convey("sleeps until the window opens", func() {
+			now := time.Now().UTC()
+			w := NewWindowedSchedule(time.Millisecond*20, nil, nil)
+			w.Recurring = &RecurringWindow{
+				Start:    sinceMidnight(now.Add(time.Hour)),
+				Stop:     sinceMidnight(now.Add(2 * time.Hour)),
+				Location: time.UTC,
+			}
+			stop := make(chan struct{})
+			time.AfterFunc(time.Millisecond*50, func() { close(stop) })
+
+			r := w.Wait(time.Time{}, stop)
+			So(r.Error(), ShouldEqual, ErrWaitCancelled)
+			So(r.State(), ShouldEqual, Active)
+		})
+		Convey("ends when no window opens before the stop time", func() {
+			now := time.Now().UTC()
+			stopTime := now.Add(time.Minute)
+			w := NewWindowedSchedule(time.Millisecond*20, nil, &stopTime)
+			w.Recurring = &RecurringWindow{
+				Start:    sinceMidnight(now.Add(time.Hour)),
+				Stop:     sinceMidnight(now.Add(2 * time.Hour)),
+				Location: time.UTC,
+			}
+			r := w.Wait(time.Time{}, nil)
+			So(r.State(), ShouldEqual, Ended)
+		})
+	})
+}
@@ -2,6 +2,8 @@ package schedule
 
 import (
 	"errors"
+	"math/rand"
+	"sync"
 	"time"
 )
 
@@ -12,8 +14,19 @@ var (
 	ErrInvalidStopTime = errors.New("Stop time is in the past")
 	// ErrStopBeforeStart - Error message for the stop time cannot occur before start time
 	ErrStopBeforeStart = errors.New("Stop time cannot occur before start time")
+	// ErrInvalidSplay - Error message for a splay which is negative or not less than the interval
+	ErrInvalidSplay = errors.New("Splay must be positive and less than the interval")
+	// ErrInvalidAlignOffset - Error message for an alignment offset which is negative or not less than the interval
+	ErrInvalidAlignOffset = errors.New("Alignment offset must be positive and less than the interval")
+	// ErrWaitCancelled - Error message for a wait cancelled before the schedule fired
+	ErrWaitCancelled = errors.New("Wait was cancelled before the schedule fired")
 )
 
+func init() {
+	// seed the splay delays so they differ across snapd instances
+	rand.Seed(time.Now().UTC().UnixNano())
+}
+
 // ScheduleState int type
 type ScheduleState int
 
@@ -32,8 +45,19 @@ type Schedule interface {
 	GetState() ScheduleState
 	// Returns where a schedule is still valid for this point in time.
 	Validate() error
-	// Blocks until time to fire and returns a schedule.Response
-	Wait(time.Time) Response
+	// Blocks until time to fire, or until the stop channel is closed, and
+	// returns a schedule.Response. The response to a cancelled wait returns
+	// ErrWaitCancelled from Error(). A nil stop channel never cancels.
+	Wait(last time.Time, stop <-chan struct{}) Response
+}
+
+// IntervalSchedule is a Schedule firing on intervals, which can tell the
+// times the intervals it missed were due at
+type IntervalSchedule interface {
+	Schedule
+	// Returns the times the given number of intervals missed after last
+	// were due at, oldest first
+	MissedTimes(last time.Time, missed uint) []time.Time
 }
 
 // Response interface defines the behavior of schedule response
@@ -48,10 +72,101 @@ type Response interface {
 	LastTime() time.Time
 }
 
-func waitOnInterval(last time.Time, i time.Duration) (uint, time.Time) {
+// sleep blocks for the given duration or until stop is closed. It returns
+// ErrWaitCancelled if stop was closed first.
+func sleep(d time.Duration, stop <-chan struct{}) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-stop:
+		return ErrWaitCancelled
+	}
+}
+
+// validateSplay returns an error if the splay is negative or not less than
+// the interval.
+func validateSplay(splay, i time.Duration) error {
+	if splay < 0 || (splay > 0 && splay >= i) {
+		return ErrInvalidSplay
+	}
+	return nil
+}
+
+// validateAlignOffset returns an error if the alignment offset is negative or
+// not less than the interval.
+func validateAlignOffset(offset, i time.Duration) error {
+	if offset < 0 || (offset > 0 && offset >= i) {
+		return ErrInvalidAlignOffset
+	}
+	return nil
+}
+
+// intervalWaiter waits from last until the next interval boundary and returns
+// the number of intervals missed and the time it fired.
+type intervalWaiter func(last time.Time, stop <-chan struct{}) (uint, time.Time, error)
+
+// newIntervalWaiter returns the intervalWaiter for an interval which is
+// either relative to the last fire or aligned to the wall clock.
+func newIntervalWaiter(i time.Duration, aligned bool, offset time.Duration) intervalWaiter {
+	if aligned {
+		return func(last time.Time, stop <-chan struct{}) (uint, time.Time, error) {
+			return waitOnAlignedInterval(last, i, offset, stop)
+		}
+	}
+	return func(last time.Time, stop <-chan struct{}) (uint, time.Time, error) {
+		return waitOnInterval(last, i, stop)
+	}
+}
+
+// splayDelay holds the delay of the last fire of a splayed schedule. A
+// waiter cancelled on a schedule may still update it while the next one
+// reads it, so it is guarded by a mutex.
+type splayDelay struct {
+	mutex sync.Mutex
+	delay time.Duration
+}
+
+func (s *splayDelay) get() time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.delay
+}
+
+func (s *splayDelay) set(d time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.delay = d
+}
+
+// waitOnSplayedInterval waits on an interval and then delays the fire by a
+// random duration up to the splay. The delay of the previous fire is removed
+// from last, so the delays do not accumulate and the fires stay spread around
+// the interval boundaries. The delay of this fire is returned.
+func waitOnSplayedInterval(last time.Time, wait intervalWaiter, splay, prevDelay time.Duration, stop <-chan struct{}) (uint, time.Time, time.Duration, error) {
+	if splay <= 0 {
+		m, t, err := wait(last, stop)
+		return m, t, 0, err
+	}
+	if (last != time.Time{}) {
+		last = last.Add(-prevDelay)
+	}
+	m, t, err := wait(last, stop)
+	if err != nil {
+		return m, t, prevDelay, err
+	}
+	delay := time.Duration(rand.Int63n(int64(splay)))
+	if err := sleep(delay, stop); err != nil {
+		return m, time.Now(), prevDelay, err
+	}
+	return m, time.Now(), delay, nil
+}
+
+func waitOnInterval(last time.Time, i time.Duration, stop <-chan struct{}) (uint, time.Time, error) {
 	if (last == time.Time{}) {
-		time.Sleep(i)
-		return uint(0), time.Now()
+		err := sleep(i, stop)
+		return uint(0), time.Now(), err
 	}
 	// Get the difference in time.Duration since last in nanoseconds (int64)
 	timeDiff := time.Since(last).Nanoseconds()
@@ -63,6 +178,41 @@ func waitOnInterval(last time.Time, i time.Duration) (uint, time.Time) {
 	missed := (timeDiff - remainder) / nanoInterval // timeDiff.Nanoseconds() % s.Interval.Nanoseconds()
 	waitDuration := nanoInterval - remainder
 	// Wait until predicted interval fires
-	time.Sleep(time.Duration(waitDuration))
-	return uint(missed), time.Now()
+	err := sleep(time.Duration(waitDuration), stop)
+	return uint(missed), time.Now(), err
+}
+
+// missedIntervalTimes returns the times of the given number of interval
+// boundaries following last, which are aligned to the Unix epoch shifted by
+// offset if aligned is set.
+func missedIntervalTimes(last time.Time, i time.Duration, aligned bool, offset time.Duration, missed uint) []time.Time {
+	next := last.Add(i)
+	if aligned {
+		origin := time.Unix(0, 0).Add(offset)
+		next = origin.Add((last.Sub(origin)/i + 1) * i)
+	}
+	times := make([]time.Time, 0, missed)
+	for k := uint(0); k < missed; k++ {
+		times = append(times, next)
+		next = next.Add(i)
+	}
+	return times
+}
+
+// waitOnAlignedInterval waits until the next multiple of the interval since
+// the Unix epoch shifted by offset, so that schedules with the same interval
+// fire at the same wall clock times. The missed intervals are the boundaries
+// passed since last.
+func waitOnAlignedInterval(last time.Time, i, offset time.Duration, stop <-chan struct{}) (uint, time.Time, error) {
+	origin := time.Unix(0, 0).Add(offset)
+	now := time.Now()
+	var missed uint
+	if (last != time.Time{}) && last.Before(now) {
+		if passed := int64(now.Sub(origin)/i) - int64(last.Sub(origin)/i); passed > 0 {
+			missed = uint(passed)
+		}
+	}
+	// Wait until the next boundary
+	err := sleep(i-now.Sub(origin)%i, stop)
+	return missed, time.Now(), err
 }
@@ -7,7 +7,15 @@ import (
 // SimpleSchedule is a schedule that only implements an endless repeating interval
 type SimpleSchedule struct {
 	Interval time.Duration
-	state    ScheduleState
+	// Splay delays each fire by a random duration up to its value
+	Splay time.Duration
+	// Aligned makes the schedule fire at multiples of the interval since the
+	// Unix epoch shifted by AlignOffset, rather than relative to its last fire
+	Aligned     bool
+	AlignOffset time.Duration
+	state       ScheduleState
+	// splayDelay is the delay of the last fire
+	splayDelay splayDelay
 }
 
 // NewSimpleSchedule returns the SimpleSchedule given the time interval
@@ -23,23 +31,34 @@ func (s *SimpleSchedule) GetState() ScheduleState {
 }
 
 // Validate returns an error if the interval of schedule is less
-// or equals zero
+// or equals zero, or if the splay is not less than the interval
 func (s *SimpleSchedule) Validate() error {
 	if s.Interval <= 0 {
 		return ErrInvalidInterval
 	}
-	return nil
+	if err := validateAlignOffset(s.AlignOffset, s.Interval); err != nil {
+		return err
+	}
+	return validateSplay(s.Splay, s.Interval)
 }
 
 // Wait returns the SimpleSchedule state, misses and the last schedule ran
-func (s *SimpleSchedule) Wait(last time.Time) Response {
-	m, t := waitOnInterval(last, s.Interval)
-	return &SimpleScheduleResponse{state: s.GetState(), missed: m, lastTime: t}
+func (s *SimpleSchedule) Wait(last time.Time, stop <-chan struct{}) Response {
+	wait := newIntervalWaiter(s.Interval, s.Aligned, s.AlignOffset)
+	m, t, delay, err := waitOnSplayedInterval(last, wait, s.Splay, s.splayDelay.get(), stop)
+	s.splayDelay.set(delay)
+	return &SimpleScheduleResponse{state: s.GetState(), err: err, missed: m, lastTime: t}
+}
+
+// MissedTimes returns the times the intervals missed after last were due at
+func (s *SimpleSchedule) MissedTimes(last time.Time, missed uint) []time.Time {
+	return missedIntervalTimes(last, s.Interval, s.Aligned, s.AlignOffset, missed)
 }
 
 // SimpleScheduleResponse a response from SimpleSchedule conforming to ScheduleResponse interface
 type SimpleScheduleResponse struct {
 	state    ScheduleState
+	err      error
 	missed   uint
 	lastTime time.Time
 }
@@ -51,7 +70,7 @@ func (s *SimpleScheduleResponse) State() ScheduleState {
 
 // Error returns last error
 func (s *SimpleScheduleResponse) Error() error {
-	return nil
+	return s.err
 }
 
 // Missed returns any missed intervals
@@ -23,7 +23,7 @@ func TestSimpleSchedule(t *testing.T) {
 			So(err, ShouldBeNil)
 
 			before := time.Now()
-			r := s.Wait(last)
+			r := s.Wait(last, nil)
 			after := time.Since(before)
 
 			So(r.State(), ShouldEqual, Active)
@@ -41,5 +41,85 @@ func TestSimpleSchedule(t *testing.T) {
 			So(err, ShouldResemble, ErrInvalidInterval)
 		})
 
+		Convey("splay not less than the interval", func() {
+			s := NewSimpleSchedule(time.Second)
+			s.Splay = time.Second
+			So(s.Validate(), ShouldEqual, ErrInvalidSplay)
+			s.Splay = -time.Millisecond
+			So(s.Validate(), ShouldEqual, ErrInvalidSplay)
+		})
+
+		Convey("Wait() with splay", func() {
+			interval := time.Millisecond * 50
+			splay := time.Millisecond * 20
+			s := NewSimpleSchedule(interval)
+			s.Splay = splay
+			So(s.Validate(), ShouldBeNil)
+
+			start := time.Now()
+			last := start
+			for i := 1; i <= 5; i++ {
+				r := s.Wait(last, nil)
+				So(r.Error(), ShouldBeNil)
+				So(r.Missed(), ShouldEqual, 0)
+				last = r.LastTime()
+				// each fire is delayed from its interval boundary by less than the
+				// splay, and the delays do not accumulate
+				delay := last.Sub(start) - time.Duration(i)*interval
+				So(delay, ShouldBeGreaterThanOrEqualTo, 0)
+				So(delay, ShouldBeLessThan, splay+time.Millisecond*10)
+			}
+		})
+
+		Convey("aligned Wait()", func() {
+			interval := time.Millisecond * 100
+			s := NewSimpleSchedule(interval)
+			s.Aligned = true
+			So(s.Validate(), ShouldBeNil)
+
+			r := s.Wait(time.Now(), nil)
+			So(r.Error(), ShouldBeNil)
+			So(r.Missed(), ShouldEqual, 0)
+			// fires on a multiple of the interval since the epoch
+			So(time.Duration(r.LastTime().UnixNano())%interval, ShouldBeLessThan, time.Millisecond*10)
+
+			Convey("shifted by an offset", func() {
+				s.AlignOffset = time.Millisecond * 30
+				So(s.Validate(), ShouldBeNil)
+				r := s.Wait(time.Now(), nil)
+				So((time.Duration(r.LastTime().UnixNano())-s.AlignOffset)%interval, ShouldBeLessThan, time.Millisecond*10)
+			})
+			Convey("counting the boundaries missed since last", func() {
+				r := s.Wait(time.Now().Add(-interval*3), nil)
+				So(r.Missed(), ShouldBeBetweenOrEqual, 3, 4)
+			})
+			Convey("with an offset not less than the interval", func() {
+				s.AlignOffset = interval
+				So(s.Validate(), ShouldEqual, ErrInvalidAlignOffset)
+			})
+		})
+
+		Convey("times of the missed intervals", func() {
+			s := NewSimpleSchedule(time.Second * 10)
+			last := time.Unix(1000003, 0)
+			So(s.MissedTimes(last, 2), ShouldResemble, []time.Time{time.Unix(1000013, 0), time.Unix(1000023, 0)})
+			Convey("aligned", func() {
+				s.Aligned = true
+				s.AlignOffset = time.Second * 5
+				So(s.MissedTimes(last, 2), ShouldResemble, []time.Time{time.Unix(1000005, 0), time.Unix(1000015, 0)})
+			})
+		})
+
+		Convey("cancelled Wait()", func() {
+			s := NewSimpleSchedule(time.Hour)
+			stop := make(chan struct{})
+			time.AfterFunc(time.Millisecond*10, func() { close(stop) })
+
+			before := time.Now()
+			r := s.Wait(time.Now(), stop)
+			So(time.Since(before), ShouldBeLessThan, time.Second)
+			So(r.Error(), ShouldEqual, ErrWaitCancelled)
+		})
+
 	})
 }
@@ -0,0 +1,149 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrMissingTriggeringTask - Error message for a triggered schedule without the task triggering it
+var ErrMissingTriggeringTask = errors.New("Triggered schedule is missing the ID of the task triggering it")
+
+// TriggeredSchedule is a schedule which fires each time the workflow of
+// another task completes, or only when it succeeds.
+type TriggeredSchedule struct {
+	// TaskID is the ID of the task triggering the schedule
+	TaskID string
+	// SuccessOnly is set if the schedule fires only on the successful runs
+	// of the triggering task
+	SuccessOnly bool
+
+	state    ScheduleState
+	triggers *triggers
+}
+
+// NewTriggeredSchedule returns a schedule triggered by the task with the given ID
+func NewTriggeredSchedule(taskID string, successOnly bool) *TriggeredSchedule {
+	return &TriggeredSchedule{
+		TaskID:      taskID,
+		SuccessOnly: successOnly,
+		triggers:    newTriggers(),
+	}
+}
+
+// GetState returns the state of the schedule
+func (t *TriggeredSchedule) GetState() ScheduleState {
+	return t.state
+}
+
+// Validate returns an error if the triggering task is not set
+func (t *TriggeredSchedule) Validate() error {
+	if t.TaskID == "" {
+		return ErrMissingTriggeringTask
+	}
+	return nil
+}
+
+// Trigger fires the schedule. It never blocks: the triggers received while
+// one is pending are counted as missed, so a task triggered faster than it
+// runs fires once for all of them.
+func (t *TriggeredSchedule) Trigger() {
+	t.triggers.fire()
+}
+
+// Wait blocks until the schedule is triggered
+func (t *TriggeredSchedule) Wait(last time.Time, stop <-chan struct{}) Response {
+	missed, err := t.triggers.wait(stop)
+	return &TriggeredScheduleResponse{
+		state:    t.GetState(),
+		err:      err,
+		missed:   missed,
+		lastTime: time.Now(),
+	}
+}
+
+// triggers holds the trigger pending for a schedule fired by something other
+// than the clock.
+type triggers struct {
+	c     chan struct{}
+	mutex sync.Mutex
+	// missed counts the triggers received while a trigger was pending
+	missed uint
+}
+
+func newTriggers() *triggers {
+	return &triggers{c: make(chan struct{}, 1)}
+}
+
+// fire adds a pending trigger, or counts the trigger as missed if one is
+// already pending.
+func (t *triggers) fire() {
+	select {
+	case t.c <- struct{}{}:
+	default:
+		t.mutex.Lock()
+		t.missed++
+		t.mutex.Unlock()
+	}
+}
+
+// wait blocks until a trigger is pending, or until stop is closed, and
+// returns the number of triggers missed since the last one.
+func (t *triggers) wait(stop <-chan struct{}) (uint, error) {
+	select {
+	case <-t.c:
+	case <-stop:
+		return 0, ErrWaitCancelled
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	missed := t.missed
+	t.missed = 0
+	return missed, nil
+}
+
+// TriggeredScheduleResponse is the response from TriggeredSchedule
+type TriggeredScheduleResponse struct {
+	state    ScheduleState
+	err      error
+	missed   uint
+	lastTime time.Time
+}
+
+// State returns the state of the Schedule
+func (t *TriggeredScheduleResponse) State() ScheduleState {
+	return t.state
+}
+
+// Error returns last error
+func (t *TriggeredScheduleResponse) Error() error {
+	return t.err
+}
+
+// Missed returns the triggers missed since the last fire
+func (t *TriggeredScheduleResponse) Missed() uint {
+	return t.missed
+}
+
+// LastTime returns the time the schedule was triggered
+func (t *TriggeredScheduleResponse) LastTime() time.Time {
+	return t.lastTime
+}
@@ -0,0 +1,75 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTriggeredSchedule(t *testing.T) {
+	Convey("Triggered Schedule", t, func() {
+		Convey("valid triggering task", func() {
+			s := NewTriggeredSchedule("1234", false)
+			So(s.Validate(), ShouldBeNil)
+		})
+		Convey("missing triggering task", func() {
+			s := NewTriggeredSchedule("", true)
+			So(s.Validate(), ShouldEqual, ErrMissingTriggeringTask)
+		})
+		Convey("wait until triggered", func() {
+			s := NewTriggeredSchedule("1234", false)
+			time.AfterFunc(time.Millisecond*10, s.Trigger)
+			before := time.Now()
+			r := s.Wait(time.Time{}, nil)
+			So(r.State(), ShouldEqual, Active)
+			So(r.Error(), ShouldBeNil)
+			So(r.Missed(), ShouldEqual, 0)
+			So(r.LastTime(), ShouldHappenAfter, before)
+		})
+		Convey("count the triggers received while one is pending", func() {
+			s := NewTriggeredSchedule("1234", false)
+			s.Trigger()
+			s.Trigger()
+			s.Trigger()
+			r := s.Wait(time.Time{}, nil)
+			So(r.Error(), ShouldBeNil)
+			So(r.Missed(), ShouldEqual, 2)
+
+			stop := make(chan struct{})
+			time.AfterFunc(time.Millisecond*10, func() { close(stop) })
+			r = s.Wait(time.Time{}, stop)
+			So(r.Error(), ShouldEqual, ErrWaitCancelled)
+		})
+		Convey("cancelled Wait()", func() {
+			s := NewTriggeredSchedule("1234", false)
+			stop := make(chan struct{})
+			time.AfterFunc(time.Millisecond*10, func() { close(stop) })
+
+			before := time.Now()
+			r := s.Wait(time.Now(), stop)
+			So(time.Since(before), ShouldBeLessThan, time.Second)
+			So(r.Error(), ShouldEqual, ErrWaitCancelled)
+		})
+	})
+}
@@ -15,7 +15,18 @@ type WindowedSchedule struct {
 	Interval  time.Duration
 	StartTime *time.Time
 	StopTime  *time.Time
+	// Splay delays each fire by a random duration up to its value
+	Splay time.Duration
+	// Aligned makes the schedule fire at multiples of the interval since the
+	// Unix epoch shifted by AlignOffset, rather than relative to its last fire
+	Aligned     bool
+	AlignOffset time.Duration
+	// Recurring restricts the fires to a window recurring every day or on
+	// some days of the week, between the start and stop times if they are set
+	Recurring *RecurringWindow
 	state     ScheduleState
+	// splayDelay is the delay of the last fire
+	splayDelay splayDelay
 }
 
 // NewWindowedSchedule returns an instance of WindowedSchedule given duration,
@@ -49,12 +60,20 @@ func (w *WindowedSchedule) Validate() error {
 	if w.Interval <= 0 {
 		return ErrInvalidInterval
 	}
-	return nil
+	if err := validateAlignOffset(w.AlignOffset, w.Interval); err != nil {
+		return err
+	}
+	if w.Recurring != nil {
+		if err := w.Recurring.Validate(); err != nil {
+			return err
+		}
+	}
+	return validateSplay(w.Splay, w.Interval)
 }
 
 // Wait waits the window interval and return.
 // Otherwise, it exits with a completed state
-func (w *WindowedSchedule) Wait(last time.Time) Response {
+func (w *WindowedSchedule) Wait(last time.Time, stop <-chan struct{}) Response {
 	// Do we even have a specific start time?
 	if w.StartTime != nil {
 		// Wait till it is time to start if before the window start
@@ -64,7 +83,13 @@ func (w *WindowedSchedule) Wait(last time.Time) Response {
 				"_block":         "windowed-wait",
 				"sleep-duration": wait,
 			}).Debug("Waiting for window to start")
-			time.Sleep(wait)
+			if err := sleep(wait, stop); err != nil {
+				return &WindowedScheduleResponse{
+					state:    w.GetState(),
+					err:      err,
+					lastTime: time.Now(),
+				}
+			}
 		}
 		if (last == time.Time{}) {
 			logger.WithFields(log.Fields{
@@ -84,6 +109,11 @@ func (w *WindowedSchedule) Wait(last time.Time) Response {
 	// If within the window we wait our interval and return
 	// otherwise we exit with a compleled state.
 	var m uint
+	var err error
+	wait := newIntervalWaiter(w.Interval, w.Aligned, w.AlignOffset)
+	if w.Recurring != nil {
+		return w.waitRecurring(last, wait, stop)
+	}
 	// Do we even have a stop time?
 	if w.StopTime != nil {
 		if time.Now().Before(*w.StopTime) {
@@ -96,7 +126,7 @@ func (w *WindowedSchedule) Wait(last time.Time) Response {
 				"last":     last,
 				"interval": w.Interval,
 			}).Debug("waiting for interval")
-			m, _ = waitOnInterval(last, w.Interval)
+			m, err = w.waitSplayed(last, wait, stop)
 		} else {
 			w.state = Ended
 			m = 0
@@ -108,20 +138,87 @@ func (w *WindowedSchedule) Wait(last time.Time) Response {
 			"interval": w.Interval,
 		}).Debug("waiting for interval")
 		// This has no end like a simple schedule
-		m, _ = waitOnInterval(last, w.Interval)
+		m, err = w.waitSplayed(last, wait, stop)
 
 	}
 	return &WindowedScheduleResponse{
 		state:    w.GetState(),
+		err:      err,
 		missed:   m,
 		lastTime: time.Now(),
 	}
 }
 
+// waitSplayed waits on the interval of the schedule, delaying the fire by its
+// splay
+func (w *WindowedSchedule) waitSplayed(last time.Time, wait intervalWaiter, stop <-chan struct{}) (uint, error) {
+	m, _, delay, err := waitOnSplayedInterval(last, wait, w.Splay, w.splayDelay.get(), stop)
+	w.splayDelay.set(delay)
+	return m, err
+}
+
+// waitRecurring waits the interval within the recurring window, sleeping
+// until the next window opens whenever it is closed. The schedule ends once
+// the stop time is reached or no window opens before it.
+func (w *WindowedSchedule) waitRecurring(last time.Time, wait intervalWaiter, stop <-chan struct{}) Response {
+	for {
+		now := time.Now()
+		opens, closes := w.Recurring.Next(now)
+		if w.StopTime != nil && (!now.Before(*w.StopTime) || !opens.Before(*w.StopTime)) {
+			w.state = Ended
+			return &WindowedScheduleResponse{
+				state:    w.GetState(),
+				lastTime: time.Now(),
+			}
+		}
+		if now.Before(opens) {
+			logger.WithFields(log.Fields{
+				"_block":         "windowed-wait",
+				"sleep-duration": opens.Sub(now),
+			}).Debug("Waiting for recurring window to open")
+			if err := sleep(opens.Sub(now), stop); err != nil {
+				return &WindowedScheduleResponse{
+					state:    w.GetState(),
+					err:      err,
+					lastTime: time.Now(),
+				}
+			}
+		}
+		// the intervals while the window was closed are not missed, the
+		// first fire of a window is an interval after it starts waiting in it
+		if last.Before(opens) {
+			last = time.Now()
+		}
+		logger.WithFields(log.Fields{
+			"_block":   "windowed-wait",
+			"last":     last,
+			"interval": w.Interval,
+		}).Debug("waiting for interval")
+		m, err := w.waitSplayed(last, wait, stop)
+		fired := time.Now()
+		if err != nil || (fired.Before(closes) && (w.StopTime == nil || fired.Before(*w.StopTime))) {
+			return &WindowedScheduleResponse{
+				state:    w.GetState(),
+				err:      err,
+				missed:   m,
+				lastTime: fired,
+			}
+		}
+		// the window closed while waiting, so wait for the next one
+		last = fired
+	}
+}
+
+// MissedTimes returns the times the intervals missed after last were due at
+func (w *WindowedSchedule) MissedTimes(last time.Time, missed uint) []time.Time {
+	return missedIntervalTimes(last, w.Interval, w.Aligned, w.AlignOffset, missed)
+}
+
 // WindowedScheduleResponse is the response from SimpleSchedule
 // conforming to ScheduleResponse interface
 type WindowedScheduleResponse struct {
 	state    ScheduleState
+	err      error
 	missed   uint
 	lastTime time.Time
 }
@@ -133,7 +230,7 @@ func (w *WindowedScheduleResponse) State() ScheduleState {
 
 // Error returns last error
 func (w *WindowedScheduleResponse) Error() error {
-	return nil
+	return w.err
 }
 
 // Missed returns any missed intervals
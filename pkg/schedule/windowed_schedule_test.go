@@ -37,7 +37,7 @@ func TestWindowedSchedule(t *testing.T) {
 			state := Active
 			before := time.Now()
 			for state == Active {
-				r1 := w.Wait(last)
+				r1 := w.Wait(last, nil)
 				state = r1.State()
 				last = time.Now()
 				r = append(r, r1)
@@ -87,7 +87,7 @@ func TestWindowedSchedule(t *testing.T) {
 
 			state := Active
 			for state == Active {
-				r1 := w.Wait(last)
+				r1 := w.Wait(last, nil)
 				state = r1.State()
 				last = time.Now()
 				r = append(r, r1)
@@ -129,7 +129,7 @@ func TestWindowedSchedule(t *testing.T) {
 
 			before := time.Now()
 			for len(r) <= 10 {
-				r1 := w.Wait(last)
+				r1 := w.Wait(last, nil)
 				last = time.Now()
 				r = append(r, r1)
 			}
@@ -161,7 +161,7 @@ func TestWindowedSchedule(t *testing.T) {
 
 			before := time.Now()
 			for len(r) <= 10 {
-				r1 := w.Wait(last)
+				r1 := w.Wait(last, nil)
 				last = time.Now()
 				r = append(r, r1)
 			}
@@ -197,5 +197,28 @@ func TestWindowedSchedule(t *testing.T) {
 			So(err, ShouldEqual, ErrStopBeforeStart)
 		})
 
+		Convey("cancelled Wait() before the window starts", func() {
+			start := time.Now().Add(time.Hour)
+			w := NewWindowedSchedule(time.Millisecond*100, &start, nil)
+			stop := make(chan struct{})
+			time.AfterFunc(time.Millisecond*10, func() { close(stop) })
+
+			before := time.Now()
+			r := w.Wait(time.Time{}, stop)
+			So(time.Since(before), ShouldBeLessThan, time.Second)
+			So(r.Error(), ShouldEqual, ErrWaitCancelled)
+		})
+
+		Convey("cancelled Wait() within the window", func() {
+			w := NewWindowedSchedule(time.Hour, nil, nil)
+			stop := make(chan struct{})
+			time.AfterFunc(time.Millisecond*10, func() { close(stop) })
+
+			before := time.Now()
+			r := w.Wait(time.Now(), stop)
+			So(time.Since(before), ShouldBeLessThan, time.Second)
+			So(r.Error(), ShouldEqual, ErrWaitCancelled)
+		})
+
 	})
 }
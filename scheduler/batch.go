@@ -0,0 +1,176 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+// publishBatch accumulates the metrics a batching publish node is handed by
+// the runs of its task, until it holds enough of them or its oldest metric
+// waited long enough to be published.
+//
+// The batch is held in memory only. It is published when the task stops,
+// ends or is disabled, once its runs in progress complete, and when the
+// workflow of the task is replaced. The metrics it holds are lost if snapd
+// dies without being stopped.
+type publishBatch struct {
+	sync.Mutex
+	maxMetrics int
+	maxAge     time.Duration
+	metrics    []core.Metric
+	// timer publishes the batch once its oldest metric reaches the max age,
+	// nil while the batch is empty or has no max age
+	timer *time.Timer
+}
+
+func newPublishBatch(p *wmap.BatchPolicy) *publishBatch {
+	age, _ := p.MaxAgeDuration()
+	return &publishBatch{
+		maxMetrics: p.MaxMetrics,
+		maxAge:     age,
+	}
+}
+
+// add buffers metrics in the batch. Once the batch holds its max number of
+// metrics, it is emptied and its metrics are returned to be published.
+// Otherwise nil is returned, and flush is called once the oldest metric of
+// the batch reaches the max age.
+func (b *publishBatch) add(mts []core.Metric, flush func()) []core.Metric {
+	b.Lock()
+	defer b.Unlock()
+	b.metrics = append(b.metrics, mts...)
+	if b.maxMetrics > 0 && len(b.metrics) >= b.maxMetrics {
+		return b.take()
+	}
+	if b.maxAge > 0 && b.timer == nil && len(b.metrics) > 0 {
+		b.timer = time.AfterFunc(b.maxAge, flush)
+	}
+	return nil
+}
+
+// drain empties the batch and returns its metrics
+func (b *publishBatch) drain() []core.Metric {
+	b.Lock()
+	defer b.Unlock()
+	return b.take()
+}
+
+func (b *publishBatch) size() int {
+	b.Lock()
+	defer b.Unlock()
+	return len(b.metrics)
+}
+
+// take empties the batch and returns its metrics. The caller must hold the
+// lock of the batch.
+func (b *publishBatch) take() []core.Metric {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	mts := b.metrics
+	b.metrics = nil
+	return mts
+}
+
+// batchedJob stands for the parent job of the publish job publishing a
+// batch, handing it the metrics of the batch
+type batchedJob struct {
+	*coreJob
+	metrics []core.Metric
+}
+
+func newBatchedJob(t *task, mts []core.Metric) job {
+	cj := newCoreJob(batchJobType, time.Now().Add(t.deadlineDuration), t.id, "", 0)
+	cj.priority = t.priority
+	return &batchedJob{
+		coreJob: cj,
+		metrics: mts,
+	}
+}
+
+func (b *batchedJob) Metrics() []core.Metric {
+	return b.metrics
+}
+
+func (b *batchedJob) Run() {}
+
+// flushBatch publishes the metrics buffered by a batching publish node of
+// the task outside of a run, when the oldest of them reaches the max age of
+// the batch or the task stops.
+func (t *task) flushBatch(pu *publishNode) {
+	mts := pu.batch.drain()
+	if len(mts) == 0 {
+		return
+	}
+	mgr, err := t.RemoteManagers.Get(pu.Target)
+	if err != nil {
+		t.RecordFailure([]error{err})
+		workflowLogger.WithFields(log.Fields{
+			"_block":          "flush-batch",
+			"task-id":         t.id,
+			"task-name":       t.name,
+			"publish-name":    pu.Name(),
+			"publish-version": pu.Version(),
+			"metric-count":    len(mts),
+		}).Warn("Error getting control instance, dropping the batch")
+		return
+	}
+	publishMetrics(newBatchedJob(t, mts), t, nil, pu, mgr)
+}
+
+// flushBatches publishes the metrics buffered by the batching publish nodes
+// of the workflow of the task
+func (t *task) flushBatches() {
+	t.workflowMutex.RLock()
+	defer t.workflowMutex.RUnlock()
+	t.workflow.flushBatches(t)
+}
+
+// flushBatches publishes the metrics buffered by the batching publish nodes
+// of the workflow, one node after the other
+func (w *schedulerWorkflow) flushBatches(t *task) {
+	if w == nil {
+		return
+	}
+	for _, pu := range batchingNodes(w.processNodes, w.publishNodes) {
+		t.flushBatch(pu)
+	}
+}
+
+func batchingNodes(prnodes []*processNode, pbnodes []*publishNode) []*publishNode {
+	nodes := []*publishNode{}
+	for _, pr := range prnodes {
+		nodes = append(nodes, batchingNodes(pr.ProcessNodes, pr.PublishNodes)...)
+	}
+	for _, pb := range pbnodes {
+		if pb.batch != nil {
+			nodes = append(nodes, pb)
+		}
+	}
+	return nodes
+}
@@ -22,12 +22,19 @@ package scheduler
 import (
 	"encoding/json"
 	"fmt"
+
+	"github.com/vrischmann/jsonutil"
 )
 
 // default configuration values
 const (
 	defaultWorkManagerQueueSize uint = 25
 	defaultWorkManagerPoolSize  uint = 4
+	defaultTaskStorePath             = ""
+	defaultTaskHistorySize      uint = 10
+	defaultWorkManagerOverflow       = OverflowRejectNew
+	defaultSpoolPath                 = ""
+	defaultSpoolMaxSize         uint = 100 * 1024 * 1024
 )
 
 // holds the configuration passed in through the SNAP config file
@@ -35,8 +42,20 @@ const (
 //         UnmarshalJSON method in this same file needs to be modified to
 //         match the field mapping that is defined here
 type Config struct {
-	WorkManagerQueueSize uint `json:"work_manager_queue_size"yaml:"work_manager_queue_size"`
-	WorkManagerPoolSize  uint `json:"work_manager_pool_size"yaml:"work_manager_pool_size"`
+	WorkManagerQueueSize uint   `json:"work_manager_queue_size"yaml:"work_manager_queue_size"`
+	WorkManagerPoolSize  uint   `json:"work_manager_pool_size"yaml:"work_manager_pool_size"`
+	TaskStorePath        string `json:"task_store_path"yaml:"task_store_path"`
+	TaskHistorySize      uint   `json:"task_history_size"yaml:"task_history_size"`
+	// the overflow policies of the collect, process and publish queues, and
+	// the time a job waits for room in a full queue with the block policy
+	WorkManagerCollectOverflow string            `json:"work_manager_collect_overflow"yaml:"work_manager_collect_overflow"`
+	WorkManagerProcessOverflow string            `json:"work_manager_process_overflow"yaml:"work_manager_process_overflow"`
+	WorkManagerPublishOverflow string            `json:"work_manager_publish_overflow"yaml:"work_manager_publish_overflow"`
+	WorkManagerOverflowTimeout jsonutil.Duration `json:"work_manager_overflow_timeout"yaml:"work_manager_overflow_timeout"`
+	// the directory the publish nodes spool the metrics they failed to
+	// publish to, and the size in bytes the spool is limited to
+	SpoolPath    string `json:"spool_path"yaml:"spool_path"`
+	SpoolMaxSize uint   `json:"spool_max_size"yaml:"spool_max_size"`
 }
 
 const (
@@ -51,6 +70,35 @@ const (
 					"work_manager_pool_size" : {
 						"type": "integer",
 						"minimum": 1
+					},
+					"task_store_path" : {
+						"type": "string"
+					},
+					"task_history_size" : {
+						"type": "integer",
+						"minimum": 0
+					},
+					"work_manager_collect_overflow" : {
+						"type": "string",
+						"enum": ["reject-new", "drop-oldest", "block"]
+					},
+					"work_manager_process_overflow" : {
+						"type": "string",
+						"enum": ["reject-new", "drop-oldest", "block"]
+					},
+					"work_manager_publish_overflow" : {
+						"type": "string",
+						"enum": ["reject-new", "drop-oldest", "block"]
+					},
+					"work_manager_overflow_timeout" : {
+						"type": "string"
+					},
+					"spool_path" : {
+						"type": "string"
+					},
+					"spool_max_size" : {
+						"type": "integer",
+						"minimum": 0
 					}
 				},
 				"additionalProperties": false
@@ -63,6 +111,16 @@ func GetDefaultConfig() *Config {
 	return &Config{
 		WorkManagerQueueSize: defaultWorkManagerQueueSize,
 		WorkManagerPoolSize:  defaultWorkManagerPoolSize,
+		TaskStorePath:        defaultTaskStorePath,
+		TaskHistorySize:      defaultTaskHistorySize,
+
+		WorkManagerCollectOverflow: defaultWorkManagerOverflow,
+		WorkManagerProcessOverflow: defaultWorkManagerOverflow,
+		WorkManagerPublishOverflow: defaultWorkManagerOverflow,
+		WorkManagerOverflowTimeout: jsonutil.Duration{defaultOverflowTimeout},
+
+		SpoolPath:    defaultSpoolPath,
+		SpoolMaxSize: defaultSpoolMaxSize,
 	}
 }
 
@@ -88,6 +146,38 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 			if err := json.Unmarshal(v, &(c.WorkManagerPoolSize)); err != nil {
 				return fmt.Errorf("%v (while parsing 'scheduler::work_manager_pool_size')", err)
 			}
+		case "task_store_path":
+			if err := json.Unmarshal(v, &(c.TaskStorePath)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::task_store_path')", err)
+			}
+		case "task_history_size":
+			if err := json.Unmarshal(v, &(c.TaskHistorySize)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::task_history_size')", err)
+			}
+		case "work_manager_collect_overflow":
+			if err := json.Unmarshal(v, &(c.WorkManagerCollectOverflow)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::work_manager_collect_overflow')", err)
+			}
+		case "work_manager_process_overflow":
+			if err := json.Unmarshal(v, &(c.WorkManagerProcessOverflow)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::work_manager_process_overflow')", err)
+			}
+		case "work_manager_publish_overflow":
+			if err := json.Unmarshal(v, &(c.WorkManagerPublishOverflow)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::work_manager_publish_overflow')", err)
+			}
+		case "work_manager_overflow_timeout":
+			if err := json.Unmarshal(v, &(c.WorkManagerOverflowTimeout)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::work_manager_overflow_timeout')", err)
+			}
+		case "spool_path":
+			if err := json.Unmarshal(v, &(c.SpoolPath)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::spool_path')", err)
+			}
+		case "spool_max_size":
+			if err := json.Unmarshal(v, &(c.SpoolMaxSize)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::spool_max_size')", err)
+			}
 		default:
 			return fmt.Errorf("Unrecognized key '%v' in global config file while parsing 'scheduler'", k)
 		}
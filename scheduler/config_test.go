@@ -23,6 +23,7 @@ package scheduler
 
 import (
 	"testing"
+	"time"
 
 	"github.com/intelsdi-x/snap/pkg/cfgfile"
 	. "github.com/smartystreets/goconvey/convey"
@@ -70,6 +71,21 @@ func TestSchedulerConfigJSON(t *testing.T) {
 		Convey("WorkManagerPoolSize should equal 2", func() {
 			So(cfg.WorkManagerPoolSize, ShouldEqual, 2)
 		})
+		Convey("TaskStorePath should equal /var/lib/snap/tasks", func() {
+			So(cfg.TaskStorePath, ShouldEqual, "/var/lib/snap/tasks")
+		})
+		Convey("TaskHistorySize should equal 20", func() {
+			So(cfg.TaskHistorySize, ShouldEqual, 20)
+		})
+		Convey("WorkManagerCollectOverflow should equal drop-oldest", func() {
+			So(cfg.WorkManagerCollectOverflow, ShouldEqual, OverflowDropOldest)
+		})
+		Convey("WorkManagerPublishOverflow should equal block", func() {
+			So(cfg.WorkManagerPublishOverflow, ShouldEqual, OverflowBlock)
+		})
+		Convey("WorkManagerOverflowTimeout should equal 2s", func() {
+			So(cfg.WorkManagerOverflowTimeout.Duration, ShouldEqual, 2*time.Second)
+		})
 	})
 
 }
@@ -94,6 +110,21 @@ func TestSchedulerConfigYaml(t *testing.T) {
 		Convey("WorkManagerPoolSize should equal 2", func() {
 			So(cfg.WorkManagerPoolSize, ShouldEqual, 2)
 		})
+		Convey("TaskStorePath should equal /var/lib/snap/tasks", func() {
+			So(cfg.TaskStorePath, ShouldEqual, "/var/lib/snap/tasks")
+		})
+		Convey("TaskHistorySize should equal 20", func() {
+			So(cfg.TaskHistorySize, ShouldEqual, 20)
+		})
+		Convey("WorkManagerCollectOverflow should equal drop-oldest", func() {
+			So(cfg.WorkManagerCollectOverflow, ShouldEqual, OverflowDropOldest)
+		})
+		Convey("WorkManagerPublishOverflow should equal block", func() {
+			So(cfg.WorkManagerPublishOverflow, ShouldEqual, OverflowBlock)
+		})
+		Convey("WorkManagerOverflowTimeout should equal 2s", func() {
+			So(cfg.WorkManagerOverflowTimeout.Duration, ShouldEqual, 2*time.Second)
+		})
 	})
 
 }
@@ -107,5 +138,17 @@ func TestSchedulerDefaultConfig(t *testing.T) {
 		Convey("WorkManagerPoolSize should equal 4", func() {
 			So(cfg.WorkManagerPoolSize, ShouldEqual, 4)
 		})
+		Convey("TaskStorePath should be empty", func() {
+			So(cfg.TaskStorePath, ShouldEqual, "")
+		})
+		Convey("TaskHistorySize should equal 10", func() {
+			So(cfg.TaskHistorySize, ShouldEqual, 10)
+		})
+		Convey("WorkManagerCollectOverflow should equal reject-new", func() {
+			So(cfg.WorkManagerCollectOverflow, ShouldEqual, OverflowRejectNew)
+		})
+		Convey("WorkManagerOverflowTimeout should equal 1s", func() {
+			So(cfg.WorkManagerOverflowTimeout.Duration, ShouldEqual, time.Second)
+		})
 	})
 }
@@ -38,6 +38,56 @@ var (
 		EnvVar: "WORK_MANAGER_POOL_SIZE",
 	}
 
+	flSchedulerTaskStorePath = cli.StringFlag{
+		Name:   "task-store-path",
+		Usage:  "Path to the directory where tasks are persisted across restarts (disabled if empty)",
+		EnvVar: "SNAP_TASK_STORE_PATH",
+	}
+
+	flSchedulerTaskHistorySize = cli.StringFlag{
+		Name:   "task-history-size",
+		Usage:  fmt.Sprintf("Number of recent runs kept in the history of each task (default: %v)", defaultTaskHistorySize),
+		EnvVar: "SNAP_TASK_HISTORY_SIZE",
+	}
+
+	flSchedulerCollectOverflow = cli.StringFlag{
+		Name:   "work-manager-collect-overflow",
+		Usage:  fmt.Sprintf("What happens to a job arriving at the full collect queue, '%v', '%v' or '%v' (default: %v)", OverflowRejectNew, OverflowDropOldest, OverflowBlock, defaultWorkManagerOverflow),
+		EnvVar: "WORK_MANAGER_COLLECT_OVERFLOW",
+	}
+
+	flSchedulerProcessOverflow = cli.StringFlag{
+		Name:   "work-manager-process-overflow",
+		Usage:  fmt.Sprintf("What happens to a job arriving at the full process queue, '%v', '%v' or '%v' (default: %v)", OverflowRejectNew, OverflowDropOldest, OverflowBlock, defaultWorkManagerOverflow),
+		EnvVar: "WORK_MANAGER_PROCESS_OVERFLOW",
+	}
+
+	flSchedulerPublishOverflow = cli.StringFlag{
+		Name:   "work-manager-publish-overflow",
+		Usage:  fmt.Sprintf("What happens to a job arriving at the full publish queue, '%v', '%v' or '%v' (default: %v)", OverflowRejectNew, OverflowDropOldest, OverflowBlock, defaultWorkManagerOverflow),
+		EnvVar: "WORK_MANAGER_PUBLISH_OVERFLOW",
+	}
+
+	flSchedulerOverflowTimeout = cli.StringFlag{
+		Name:   "work-manager-overflow-timeout",
+		Usage:  fmt.Sprintf("The time a job waits for room in a full queue with the '%v' overflow policy (default: %v)", OverflowBlock, defaultOverflowTimeout),
+		EnvVar: "WORK_MANAGER_OVERFLOW_TIMEOUT",
+	}
+
+	flSchedulerSpoolPath = cli.StringFlag{
+		Name:   "spool-path",
+		Usage:  "Path to the directory where the metrics publish nodes failed to publish are spooled (disabled if empty)",
+		EnvVar: "SNAP_SPOOL_PATH",
+	}
+
+	flSchedulerSpoolMaxSize = cli.StringFlag{
+		Name:   "spool-max-size",
+		Usage:  fmt.Sprintf("Size in bytes the spool is limited to, 0 for unlimited (default: %v)", defaultSpoolMaxSize),
+		EnvVar: "SNAP_SPOOL_MAX_SIZE",
+	}
+
 	// Flags consumed by snapd
-	Flags = []cli.Flag{flSchedulerQueueSize, flSchedulerPoolSize}
+	Flags = []cli.Flag{flSchedulerQueueSize, flSchedulerPoolSize, flSchedulerTaskStorePath, flSchedulerTaskHistorySize,
+		flSchedulerCollectOverflow, flSchedulerProcessOverflow, flSchedulerPublishOverflow, flSchedulerOverflowTimeout,
+		flSchedulerSpoolPath, flSchedulerSpoolMaxSize}
 )
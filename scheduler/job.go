@@ -29,12 +29,15 @@ import (
 	"github.com/intelsdi-x/snap/core/cdata"
 	"github.com/intelsdi-x/snap/core/ctypes"
 	. "github.com/intelsdi-x/snap/pkg/promise"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
 )
 
 const (
 	collectJobType jobType = iota
 	publishJobType
 	processJobType
+	transformJobType
+	batchJobType
 )
 
 const (
@@ -49,17 +52,26 @@ const (
 type queuedJob interface {
 	Job() job
 	Promise() Promise
+	Priority() core.TaskPriority
 }
 
 type qj struct {
-	job     job
-	promise Promise
+	job      job
+	promise  Promise
+	priority core.TaskPriority
 }
 
 func newQueuedJob(job job) queuedJob {
+	return newPrioritizedJob(job, core.PriorityNormal)
+}
+
+// newPrioritizedJob returns a queued job worked according to the given
+// priority
+func newPrioritizedJob(job job, priority core.TaskPriority) queuedJob {
 	return &qj{
-		job:     job,
-		promise: NewPromise(),
+		job:      job,
+		promise:  NewPromise(),
+		priority: priority,
 	}
 }
 
@@ -73,6 +85,11 @@ func (j *qj) Promise() Promise {
 	return j.promise
 }
 
+// Returns the priority the job is queued with.
+func (j *qj) Priority() core.TaskPriority {
+	return j.priority
+}
+
 // Primary type for job inside
 // the scheduler.  Job encompasses all
 // all job types -- collect, process, and publish.
@@ -86,6 +103,7 @@ type job interface {
 	Type() jobType
 	TypeString() string
 	TaskID() string
+	Priority() core.TaskPriority
 	Run()
 	Metrics() []core.Metric
 }
@@ -101,6 +119,7 @@ type coreJob struct {
 	deadline  time.Time
 	starttime time.Time
 	errors    []error
+	priority  core.TaskPriority
 }
 
 func newCoreJob(t jobType, deadline time.Time, taskID string, name string, version int) *coreJob {
@@ -145,6 +164,12 @@ func (c *coreJob) TypeString() string {
 
 	case publishJobType:
 		return "publisher"
+
+	case transformJobType:
+		return "transform"
+
+	case batchJobType:
+		return "batch"
 	}
 	return "unknown"
 }
@@ -163,6 +188,11 @@ func (c *coreJob) TaskID() string {
 	return c.taskID
 }
 
+// Priority returns the priority of the task the job runs for
+func (c *coreJob) Priority() core.TaskPriority {
+	return c.priority
+}
+
 type collectorJob struct {
 	*coreJob
 	collector      collectsMetrics
@@ -170,6 +200,9 @@ type collectorJob struct {
 	metrics        []core.Metric
 	configDataTree *cdata.ConfigDataTree
 	tags           map[string]map[string]string
+	// catchupTime is the time the interval a run catching up a missed
+	// interval was due at, zero for the runs which are on time
+	catchupTime time.Time
 }
 
 func newCollectorJob(
@@ -240,7 +273,16 @@ func (c *collectorJob) Run() {
 		}
 	}
 
-	ret, errs := c.collector.CollectMetrics(c.TaskID(), c.tags)
+	tags := c.tags
+	if !c.catchupTime.IsZero() {
+		tags = catchupTags(c.tags, c.catchupTime)
+	}
+	ret, errs := c.collector.CollectMetrics(c.TaskID(), tags, c.Deadline())
+	if !c.catchupTime.IsZero() {
+		for i, m := range ret {
+			ret[i] = catchupMetric{Metric: m, timestamp: c.catchupTime}
+		}
+	}
 
 	log.WithFields(log.Fields{
 		"_module":      "scheduler-job",
@@ -263,12 +305,41 @@ func (c *collectorJob) Run() {
 	}
 }
 
+// catchupTags returns the workflow tags with the time a caught up interval
+// was due at added for all the metrics.
+func catchupTags(tags map[string]map[string]string, dueTime time.Time) map[string]map[string]string {
+	all := make(map[string]map[string]string, len(tags)+1)
+	for ns, nsTags := range tags {
+		all[ns] = nsTags
+	}
+	rootTags := map[string]string{}
+	for k, v := range tags["/"] {
+		rootTags[k] = v
+	}
+	rootTags[core.STD_TAG_CATCHUP_TIME] = dueTime.Format(time.RFC3339Nano)
+	all["/"] = rootTags
+	return all
+}
+
+// catchupMetric is a metric collected by a run catching up a missed
+// interval, timestamped with the time the interval was due at.
+type catchupMetric struct {
+	core.Metric
+	timestamp time.Time
+}
+
+func (m catchupMetric) Timestamp() time.Time {
+	return m.timestamp
+}
+
 type processJob struct {
 	*coreJob
 	processor processesMetrics
 	parentJob job
 	metrics   []core.Metric
 	config    map[string]ctypes.ConfigValue
+	// filter selects the metrics of the parent job handed to the plugin
+	filter *wmap.Filter
 }
 
 func (pr *processJob) Metrics() []core.Metric {
@@ -276,10 +347,12 @@ func (pr *processJob) Metrics() []core.Metric {
 }
 
 func newProcessJob(parentJob job, pluginName string, pluginVersion int, contentType string, config map[string]ctypes.ConfigValue, processor processesMetrics, taskID string) job {
+	cj := newCoreJob(processJobType, parentJob.Deadline(), taskID, pluginName, pluginVersion)
+	cj.priority = parentJob.Priority()
 	return &processJob{
 		parentJob: parentJob,
 		metrics:   []core.Metric{},
-		coreJob:   newCoreJob(processJobType, parentJob.Deadline(), taskID, pluginName, pluginVersion),
+		coreJob:   cj,
 		config:    config,
 		processor: processor,
 	}
@@ -295,7 +368,19 @@ func (p *processJob) Run() {
 		"plugin-config":  p.config,
 	}).Debug("starting processor job")
 
-	mts, errs := p.processor.ProcessMetrics(p.parentJob.Metrics(), p.config, p.taskID, p.name, p.version)
+	in := filterMetrics(p.filter, p.parentJob.Metrics())
+	if p.filter != nil && len(in) == 0 {
+		log.WithFields(log.Fields{
+			"_module":        "scheduler-job",
+			"block":          "run",
+			"job-type":       "processor",
+			"plugin-name":    p.name,
+			"plugin-version": p.version,
+		}).Debug("no metrics passed the filter, skipping processor job")
+		p.metrics = in
+		return
+	}
+	mts, errs := p.processor.ProcessMetrics(in, p.config, p.taskID, p.name, p.version, p.Deadline())
 	if errs != nil {
 		for _, e := range errs {
 			log.WithFields(log.Fields{
@@ -318,6 +403,8 @@ type publisherJob struct {
 	parentJob job
 	publisher publishesMetrics
 	config    map[string]ctypes.ConfigValue
+	// filter selects the metrics of the parent job handed to the plugin
+	filter *wmap.Filter
 }
 
 func (pu *publisherJob) Metrics() []core.Metric {
@@ -325,10 +412,12 @@ func (pu *publisherJob) Metrics() []core.Metric {
 }
 
 func newPublishJob(parentJob job, pluginName string, pluginVersion int, contentType string, config map[string]ctypes.ConfigValue, publisher publishesMetrics, taskID string) job {
+	cj := newCoreJob(publishJobType, parentJob.Deadline(), taskID, pluginName, pluginVersion)
+	cj.priority = parentJob.Priority()
 	return &publisherJob{
 		parentJob: parentJob,
 		publisher: publisher,
-		coreJob:   newCoreJob(publishJobType, parentJob.Deadline(), taskID, pluginName, pluginVersion),
+		coreJob:   cj,
 		config:    config,
 	}
 }
@@ -343,7 +432,18 @@ func (p *publisherJob) Run() {
 		"plugin-config":  p.config,
 	}).Debug("starting publisher job")
 
-	errs := p.publisher.PublishMetrics(p.parentJob.Metrics(), p.config, p.taskID, p.name, p.version)
+	mts := filterMetrics(p.filter, p.parentJob.Metrics())
+	if p.filter != nil && len(mts) == 0 {
+		log.WithFields(log.Fields{
+			"_module":        "scheduler-job",
+			"block":          "run",
+			"job-type":       "publisher",
+			"plugin-name":    p.name,
+			"plugin-version": p.version,
+		}).Debug("no metrics passed the filter, skipping publisher job")
+		return
+	}
+	errs := p.publisher.PublishMetrics(mts, p.config, p.taskID, p.name, p.version, p.Deadline())
 	if errs != nil {
 		for _, e := range errs {
 			log.WithFields(log.Fields{
@@ -359,3 +459,18 @@ func (p *publisherJob) Run() {
 		p.AddErrors(errs...)
 	}
 }
+
+// filterMetrics returns the metrics passing the filter of a node of the
+// workflow, all of them if the node has no filter
+func filterMetrics(f *wmap.Filter, mts []core.Metric) []core.Metric {
+	if f == nil {
+		return mts
+	}
+	filtered := make([]core.Metric, 0, len(mts))
+	for _, m := range mts {
+		if f.Matches(m.Namespace().Strings(), m.Tags()) {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
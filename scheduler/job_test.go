@@ -34,9 +34,13 @@ import (
 	. "github.com/smartystreets/goconvey/convey"
 )
 
-type mockCollector struct{}
+type mockCollector struct {
+	// deadline is the deadline of the last call to CollectMetrics
+	deadline time.Time
+}
 
-func (m *mockCollector) CollectMetrics(string, map[string]map[string]string) ([]core.Metric, []error) {
+func (m *mockCollector) CollectMetrics(_ string, _ map[string]map[string]string, deadline time.Time) ([]core.Metric, []error) {
+	m.deadline = deadline
 	return nil, nil
 }
 
@@ -100,6 +104,12 @@ func TestCollectorJob(t *testing.T) {
 			cj.(*collectorJob).Run()
 			So(cj.Errors(), ShouldResemble, []error{})
 		})
+		Convey("it should carry its deadline into the call collecting the metrics", func() {
+			mc := &mockCollector{}
+			cj := newCollectorJob([]core.RequestedMetric{}, defaultDeadline, mc, cdt, "taskid", tags)
+			cj.(*collectorJob).Run()
+			So(mc.deadline, ShouldResemble, cj.Deadline())
+		})
 	})
 }
 
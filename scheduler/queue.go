@@ -22,13 +22,38 @@ package scheduler
 import (
 	"errors"
 	"sync"
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
 )
 
 var (
-	errQueueEmpty    = errors.New("queue empty")
-	errLimitExceeded = errors.New("limit exceeded")
+	errQueueEmpty      = errors.New("queue empty")
+	errLimitExceeded   = errors.New("limit exceeded")
+	errJobDropped      = errors.New("dropped from a full queue")
+	errOverflowTimeout = errors.New("timed out waiting for room in a full queue")
+	errOverflowBlocked = errors.New("waiting for room in a full queue")
+)
+
+// The overflow policies decide what happens to a job arriving at a full queue
+const (
+	// OverflowRejectNew rejects the job
+	OverflowRejectNew = "reject-new"
+	// OverflowDropOldest drops the job which waited the longest in the queue
+	// to make room for the job
+	OverflowDropOldest = "drop-oldest"
+	// OverflowBlock waits for room in the queue, up to a timeout after which
+	// the job is rejected
+	OverflowBlock = "block"
+
+	defaultOverflowTimeout = time.Second
 )
 
+// defaultPriorityAging is the time a job waits in a queue for each level its
+// priority is raised by, so that the jobs of low priority tasks are not
+// starved by a steady flow of higher priority jobs.
+const defaultPriorityAging = time.Second
+
 type jobHandler func(queuedJob)
 
 type queue struct {
@@ -37,10 +62,35 @@ type queue struct {
 
 	handler jobHandler
 	limit   uint
+	aging   time.Duration
 	kill    chan struct{}
-	items   []queuedJob
+	items   []queueItem
 	mutex   *sync.Mutex
 	status  queueStatus
+	// overflow is the policy applied to the jobs arriving at the queue
+	// when it is full, and overflowTimeout the time the block policy
+	// waits for room in the queue
+	overflow        string
+	overflowTimeout time.Duration
+	// waiters are the jobs blocked waiting for room in the full queue,
+	// woken in turn as jobs leave it
+	waiters []chan struct{}
+}
+
+// queueItem is a job waiting in a queue
+type queueItem struct {
+	job      queuedJob
+	enqueued time.Time
+}
+
+// priority returns the priority of the job raised by one level for each
+// aging period it waited in the queue
+func (i queueItem) priority(now time.Time, aging time.Duration) core.TaskPriority {
+	p := i.job.Priority()
+	if aging > 0 {
+		p += core.TaskPriority(now.Sub(i.enqueued) / aging)
+	}
+	return p
 }
 
 type queueStatus int
@@ -67,10 +117,14 @@ func newQueue(limit uint, handler jobHandler) *queue {
 
 		handler: handler,
 		limit:   limit,
+		aging:   defaultPriorityAging,
 		kill:    make(chan struct{}),
-		items:   []queuedJob{},
+		items:   []queueItem{},
 		mutex:   &sync.Mutex{},
 		status:  queueStopped,
+
+		overflow:        OverflowRejectNew,
+		overflowTimeout: defaultOverflowTimeout,
 	}
 }
 
@@ -111,24 +165,18 @@ func (q *queue) start() {
 	for {
 		select {
 		case e := <-q.Event:
-			if err := q.push(e); err != nil {
-				qe := &queuingError{
-					Err: err,
-					Job: e.Job(),
-				}
-				q.Err <- qe
-				e.Promise().Complete([]error{qe}) // Signal job termination.
+			err := q.push(e)
+			if err == errLimitExceeded {
+				err = q.overflowPush(e)
+			}
+			if err == errOverflowBlocked {
 				continue
 			}
-
-			q.mutex.Lock()
-			if q.status == queueRunning {
-				q.status = queueWorking
-				q.mutex.Unlock()
-				go q.handle()
+			if err != nil {
+				q.reject(e, err)
 				continue
 			}
-			q.mutex.Unlock()
+			q.work()
 
 		case <-q.kill:
 			// this "officially" closes the Event channel.
@@ -143,6 +191,110 @@ func (q *queue) start() {
 
 }
 
+// work starts handling the queued jobs unless the queue is already worked
+func (q *queue) work() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.status == queueRunning {
+		q.status = queueWorking
+		go q.handle()
+	}
+}
+
+// reject reports the error of a job which is not worked and completes it
+func (q *queue) reject(j queuedJob, err error) {
+	qe := &queuingError{
+		Err: err,
+		Job: j.Job(),
+	}
+	q.Err <- qe
+	j.Promise().Complete([]error{qe}) // Signal job termination.
+}
+
+// overflowPush pushes a job arriving at a full queue according to the
+// overflow policy of the queue. A job blocked by the block policy waits for
+// room in its own goroutine, so that the queue keeps taking jobs meanwhile,
+// and errOverflowBlocked is returned.
+func (q *queue) overflowPush(j queuedJob) error {
+	switch q.overflow {
+	case OverflowDropOldest:
+		if dropped, err := q.dropOldest(); err == nil {
+			q.reject(dropped, errJobDropped)
+		}
+		return q.push(j)
+	case OverflowBlock:
+		go q.blockPush(j)
+		return errOverflowBlocked
+	}
+	return errLimitExceeded
+}
+
+// blockPush waits for room in the full queue to push the job, up to the
+// overflow timeout of the queue, and rejects the job once it times out or
+// the queue stops.
+func (q *queue) blockPush(j queuedJob) {
+	timeout := time.NewTimer(q.overflowTimeout)
+	defer timeout.Stop()
+	for {
+		wait, err := q.pushOrWait(j)
+		if err == nil {
+			q.work()
+			return
+		}
+		select {
+		case <-wait:
+		case <-timeout.C:
+			q.cancelWait(wait)
+			q.reject(j, errOverflowTimeout)
+			return
+		case <-q.kill:
+			q.cancelWait(wait)
+			q.reject(j, errLimitExceeded)
+			return
+		}
+	}
+}
+
+// pushOrWait pushes the job if there is room in the queue, or else returns
+// the channel signalled when the job may try again.
+func (q *queue) pushOrWait(j queuedJob) (chan struct{}, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.hasRoom() {
+		q.items = append(q.items, queueItem{job: j, enqueued: time.Now()})
+		return nil, nil
+	}
+	wait := make(chan struct{})
+	q.waiters = append(q.waiters, wait)
+	return wait, errLimitExceeded
+}
+
+// cancelWait removes a job giving up on waiting for room from the waiters.
+// The wake up of a job signalled meanwhile is passed on to the next waiter.
+func (q *queue) cancelWait(wait chan struct{}) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, w := range q.waiters {
+		if w == wait {
+			q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+			return
+		}
+	}
+	q.wake(1)
+}
+
+// wake signals the given number of jobs waiting for room in the queue, the
+// longest waiting first. The queue must be locked.
+func (q *queue) wake(n int) {
+	for ; n > 0 && len(q.waiters) > 0; n-- {
+		close(q.waiters[0])
+		q.waiters = q.waiters[1:]
+	}
+}
+
 func (q *queue) handle() {
 	for {
 		item, err := q.pop()
@@ -160,13 +312,19 @@ func (q *queue) length() int {
 	return len(q.items)
 }
 
+// hasRoom returns whether the queue takes one more job. The queue must be
+// locked.
+func (q *queue) hasRoom() bool {
+	return q.limit == 0 || uint(q.length())+1 <= q.limit
+}
+
 func (q *queue) push(j queuedJob) error {
 
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
-	if q.limit == 0 || uint(q.length())+1 <= q.limit {
-		q.items = append(q.items, j)
+	if q.hasRoom() {
+		q.items = append(q.items, queueItem{job: j, enqueued: time.Now()})
 		return nil
 	}
 	return errLimitExceeded
@@ -183,8 +341,65 @@ func (q *queue) pop() (queuedJob, error) {
 		return j, errQueueEmpty
 	}
 
-	j = q.items[0]
+	// the job with the highest priority, once aged, is worked first and the
+	// jobs with the same priority in the order they were queued
+	now := time.Now()
+	next := 0
+	for i := 1; i < len(q.items); i++ {
+		if q.items[i].priority(now, q.aging) > q.items[next].priority(now, q.aging) {
+			next = i
+		}
+	}
+	j = q.items[next].job
+	q.items = append(q.items[:next], q.items[next+1:]...)
+	q.wake(1)
+
+	return j, nil
+}
+
+// dropOldest removes the job which waited the longest in the queue.
+func (q *queue) dropOldest() (queuedJob, error) {
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var j queuedJob
+
+	if q.length() == 0 {
+		return j, errQueueEmpty
+	}
+
+	j = q.items[0].job
 	q.items = q.items[1:]
 
 	return j, nil
 }
+
+// setLimit changes the number of jobs the queue holds at most. The jobs
+// already waiting in a queue which is shrunk below its depth are kept, and
+// the queue rejects new jobs until it has drained below its new limit.
+func (q *queue) setLimit(limit uint) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.limit = limit
+	// wake up the jobs blocked waiting for room in the queue
+	q.wake(len(q.waiters))
+}
+
+// stats returns the depth of the queue, in total and for each priority the
+// jobs were queued with.
+func (q *queue) stats() core.WorkQueueStats {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	st := core.WorkQueueStats{
+		Limit:           q.limit,
+		Depth:           q.length(),
+		DepthByPriority: map[core.TaskPriority]int{},
+	}
+	for _, i := range q.items {
+		st.DepthByPriority[i.job.Priority()]++
+	}
+	return st
+}
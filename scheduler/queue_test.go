@@ -28,6 +28,8 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/core"
 )
 
 func TestQueue(t *testing.T) {
@@ -80,6 +82,40 @@ func TestQueue(t *testing.T) {
 		q.Stop()
 	})
 
+	Convey("it works the jobs of higher priority first", t, func() {
+		q := newQueue(5, func(queuedJob) {})
+		q.aging = 0
+		for _, p := range []core.TaskPriority{core.PriorityLow, core.PriorityNormal, core.PriorityCritical, core.PriorityHigh, core.PriorityNormal} {
+			So(q.push(newPrioritizedJob(&collectorJob{}, p)), ShouldBeNil)
+		}
+
+		st := q.stats()
+		So(st.Limit, ShouldEqual, 5)
+		So(st.Depth, ShouldEqual, 5)
+		So(st.DepthByPriority[core.PriorityNormal], ShouldEqual, 2)
+		So(st.DepthByPriority[core.PriorityCritical], ShouldEqual, 1)
+
+		for _, p := range []core.TaskPriority{core.PriorityCritical, core.PriorityHigh, core.PriorityNormal, core.PriorityNormal, core.PriorityLow} {
+			j, err := q.pop()
+			So(err, ShouldBeNil)
+			So(j.Priority(), ShouldEqual, p)
+		}
+		_, err := q.pop()
+		So(err, ShouldEqual, errQueueEmpty)
+	})
+
+	Convey("it raises the priority of the jobs waiting in the queue", t, func() {
+		q := newQueue(5, func(queuedJob) {})
+		q.aging = 20 * time.Millisecond
+		So(q.push(newPrioritizedJob(&collectorJob{}, core.PriorityLow)), ShouldBeNil)
+		time.Sleep(50 * time.Millisecond)
+		So(q.push(newPrioritizedJob(&collectorJob{}, core.PriorityHigh)), ShouldBeNil)
+
+		j, err := q.pop()
+		So(err, ShouldBeNil)
+		So(j.Priority(), ShouldEqual, core.PriorityLow)
+	})
+
 	Convey("it sends an error if the queue bound is exceeded", t, func() {
 		q := newQueue(3, func(queuedJob) { time.Sleep(1 * time.Second) })
 		q.Start()
@@ -92,6 +128,78 @@ func TestQueue(t *testing.T) {
 		q.Stop()
 	})
 
+	Convey("with a full queue", t, func() {
+		started := make(chan struct{}, 3)
+		release := make(chan struct{})
+		q := newQueue(1, func(j queuedJob) {
+			started <- struct{}{}
+			<-release
+			j.Promise().Complete([]error{})
+		})
+		j1 := newQueuedJob(&collectorJob{})
+		j2 := newQueuedJob(&collectorJob{})
+		j3 := newQueuedJob(&collectorJob{})
+		fill := func() {
+			q.Start()
+			q.Event <- j1
+			<-started
+			q.Event <- j2
+		}
+
+		Convey("it drops the oldest job with the drop-oldest policy", func() {
+			q.overflow = OverflowDropOldest
+			fill()
+			q.Event <- j3
+			err := <-q.Err
+			So(err.Err, ShouldEqual, errJobDropped)
+			So(j2.Promise().Await(), ShouldNotBeEmpty)
+			close(release)
+			So(j1.Promise().Await(), ShouldBeEmpty)
+			So(j3.Promise().Await(), ShouldBeEmpty)
+			q.Stop()
+		})
+		Convey("it waits for room with the block policy", func() {
+			q.overflow = OverflowBlock
+			fill()
+			q.Event <- j3
+			time.AfterFunc(20*time.Millisecond, func() { close(release) })
+			So(j1.Promise().Await(), ShouldBeEmpty)
+			So(j2.Promise().Await(), ShouldBeEmpty)
+			So(j3.Promise().Await(), ShouldBeEmpty)
+			q.Stop()
+		})
+		Convey("it takes jobs while a job waits for room with the block policy", func() {
+			q.overflow = OverflowBlock
+			fill()
+			q.Event <- j3
+			j4 := newQueuedJob(&collectorJob{})
+			taken := false
+			select {
+			case q.Event <- j4:
+				taken = true
+			case <-time.After(100 * time.Millisecond):
+			}
+			So(taken, ShouldBeTrue)
+			So(q.stats().Depth, ShouldEqual, 1)
+			close(release)
+			So(j3.Promise().Await(), ShouldBeEmpty)
+			So(j4.Promise().Await(), ShouldBeEmpty)
+			q.Stop()
+		})
+		Convey("it rejects the job once the block policy times out", func() {
+			q.overflow = OverflowBlock
+			q.overflowTimeout = 10 * time.Millisecond
+			fill()
+			q.Event <- j3
+			err := <-q.Err
+			So(err.Err, ShouldEqual, errOverflowTimeout)
+			So(j3.Promise().Await(), ShouldNotBeEmpty)
+			close(release)
+			So(j2.Promise().Await(), ShouldBeEmpty)
+			q.Stop()
+		})
+	})
+
 	Convey("stop closes the queue", t, func() {
 		q := newQueue(3, func(queuedJob) { time.Sleep(1 * time.Second) })
 		q.Start()
@@ -27,6 +27,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 
@@ -64,8 +65,34 @@ var (
 	ErrTaskDisabledNotRunnable = errors.New("Task is disabled. Cannot be started.")
 	// ErrTaskDisabledNotStoppable - The error message for when a task is disabled and cannot be stopped
 	ErrTaskDisabledNotStoppable = errors.New("Task is disabled. Only running tasks can be stopped.")
+	// ErrTaskEndedNotUpdatable - The error message for an update of a task whose schedule ended
+	ErrTaskEndedNotUpdatable = errors.New("Task has ended. Cannot be updated.")
+	// ErrTaskUpdateEmpty - The error message for a task update without a schedule or a workflow
+	ErrTaskUpdateEmpty = errors.New("Task update must include a schedule or a workflow.")
+	// ErrTriggeringTaskNotFound - The error message for a triggered-by schedule whose triggering task does not exist
+	ErrTriggeringTaskNotFound = errors.New("Task triggering the schedule not found.")
+	// ErrTriggerCycle - The error message for a triggered-by schedule which would trigger its own task
+	ErrTriggerCycle = errors.New("Task cannot be triggered by itself or a task it triggers.")
+	// ErrTaskClientsUnavailable - The error message for a task update whose workflow clients cannot be set up
+	ErrTaskClientsUnavailable = errors.New("Task clients unavailable.")
+	// ErrWorkQueueNotFound - The error message for a work queue which does not exist
+	ErrWorkQueueNotFound = errors.New("Work queue not found.")
+	// ErrWorkQueueNoWorkers - The error message for resizing the worker pool of a work queue to no workers
+	ErrWorkQueueNoWorkers = errors.New("Work queue must have at least one worker.")
 )
 
+// workQueueTypes are the types of the jobs worked by each queue of the work
+// manager, keyed by the name of the queue.
+var workQueueTypes = map[string]jobType{
+	"collect": collectJobType,
+	"process": processJobType,
+	"publish": publishJobType,
+}
+
+// taskUpdateSuffix is appended to the ID of a task to subscribe the
+// dependencies of its new workflow while the current ones are still in use.
+const taskUpdateSuffix = "-update"
+
 type schedulerState int
 
 const (
@@ -94,16 +121,19 @@ type managesMetrics interface {
 	UnsubscribeDeps(string) []serror.SnapError
 }
 
+// The calls collecting, processing and publishing metrics take the deadline
+// of the job they are made for. The calls to the plugins still in progress
+// once it passed are cancelled, failing with core.ErrDeadlineExceeded.
 type collectsMetrics interface {
-	CollectMetrics(string, map[string]map[string]string) ([]core.Metric, []error)
+	CollectMetrics(string, map[string]map[string]string, time.Time) ([]core.Metric, []error)
 }
 
 type publishesMetrics interface {
-	PublishMetrics([]core.Metric, map[string]ctypes.ConfigValue, string, string, int) []error
+	PublishMetrics([]core.Metric, map[string]ctypes.ConfigValue, string, string, int, time.Time) []error
 }
 
 type processesMetrics interface {
-	ProcessMetrics([]core.Metric, map[string]ctypes.ConfigValue, string, string, int) ([]core.Metric, []error)
+	ProcessMetrics([]core.Metric, map[string]ctypes.ConfigValue, string, string, int, time.Time) ([]core.Metric, []error)
 }
 
 type scheduler struct {
@@ -113,6 +143,11 @@ type scheduler struct {
 	state           schedulerState
 	eventManager    *gomit.EventController
 	taskWatcherColl *taskWatcherCollection
+	taskStore       TaskStore
+	taskHistorySize uint
+	// spool holds the metrics the publish nodes failed to publish, nil if
+	// no spool path is set
+	spool *spool
 }
 
 type managesWork interface {
@@ -243,11 +278,34 @@ func New(cfg *Config) *scheduler {
 		PublishWkrSizeOption(cfg.WorkManagerPoolSize),
 		ProcessQSizeOption(cfg.WorkManagerQueueSize),
 		ProcessWkrSizeOption(cfg.WorkManagerPoolSize),
+		OverflowTimeoutOption(cfg.WorkManagerOverflowTimeout.Duration),
+	}
+	for _, o := range []struct {
+		queue  string
+		policy string
+		opt    func(string) workManagerOption
+	}{
+		{"collect", cfg.WorkManagerCollectOverflow, CollectOverflowOption},
+		{"process", cfg.WorkManagerProcessOverflow, ProcessOverflowOption},
+		{"publish", cfg.WorkManagerPublishOverflow, PublishOverflowOption},
+	} {
+		switch o.policy {
+		case OverflowRejectNew, OverflowDropOldest, OverflowBlock:
+			opts = append(opts, o.opt(o.policy))
+		case "":
+		default:
+			schedulerLogger.WithFields(log.Fields{
+				"_block": "New",
+				"queue":  o.queue,
+				"value":  o.policy,
+			}).Warn("Unknown work manager overflow policy, rejecting the jobs arriving at the full queue")
+		}
 	}
 	s := &scheduler{
 		tasks:           newTaskCollection(),
 		eventManager:    gomit.NewEventController(),
 		taskWatcherColl: newTaskWatcherCollection(),
+		taskHistorySize: cfg.TaskHistorySize,
 	}
 
 	// we are setting the size of the queue and number of workers for
@@ -256,6 +314,31 @@ func New(cfg *Config) *scheduler {
 	s.workManager.Start()
 	s.eventManager.RegisterHandler(HandlerRegistrationName, s)
 
+	if cfg.TaskStorePath != "" {
+		schedulerLogger.WithFields(log.Fields{
+			"_block": "New",
+			"value":  cfg.TaskStorePath,
+		}).Info("Setting task store path")
+		s.taskStore = NewFileTaskStore(cfg.TaskStorePath)
+	}
+
+	if cfg.SpoolPath != "" {
+		sp, err := newSpool(cfg.SpoolPath, int64(cfg.SpoolMaxSize))
+		if err != nil {
+			schedulerLogger.WithFields(log.Fields{
+				"_block": "New",
+				"_error": err.Error(),
+				"value":  cfg.SpoolPath,
+			}).Error("Unable to create the spool, disabling it")
+		} else {
+			schedulerLogger.WithFields(log.Fields{
+				"_block": "New",
+				"value":  cfg.SpoolPath,
+			}).Info("Setting spool path")
+			s.spool = sp
+		}
+	}
+
 	return s
 }
 
@@ -320,6 +403,13 @@ func (s *scheduler) createTask(sch schedule.Schedule, wfMap *wmap.WorkflowMap, s
 		return nil, te
 	}
 
+	if err := s.checkSpool(wf); err != nil {
+		te.errs = append(te.errs, serror.New(err))
+		f := buildErrorsLog(te.Errors(), logger)
+		f.Error("Unable to generate workflow from workflow map")
+		return nil, te
+	}
+
 	// Create the task object
 	task, err := newTask(sch, wf, s.workManager, s.metricManager, s.eventManager, opts...)
 	if err != nil {
@@ -328,6 +418,17 @@ func (s *scheduler) createTask(sch schedule.Schedule, wfMap *wmap.WorkflowMap, s
 		f.Error("Unable to create task")
 		return nil, te
 	}
+	task.history = newTaskHistory(s.taskHistorySize)
+	task.spool = s.spool
+
+	// Tasks are restored in no particular order, so the task triggering a
+	// restored task may not be restored yet.
+	if err := s.checkTriggers(task.id, sch, source == "store"); err != nil {
+		te.errs = append(te.errs, serror.New(err))
+		f := buildErrorsLog(te.Errors(), logger)
+		f.Error("schedule passed not valid")
+		return nil, te
+	}
 
 	// Group dependencies by the node they live on
 	// and validate them.
@@ -347,6 +448,10 @@ func (s *scheduler) createTask(sch schedule.Schedule, wfMap *wmap.WorkflowMap, s
 		}
 	}
 
+	// Tasks owned by tribe or loaded from an autodiscover path are
+	// recreated by those sources, so they are not persisted.
+	task.persistent = source != "tribe" && source != "autodiscover"
+
 	// Add task to taskCollection
 	if err := s.tasks.add(task); err != nil {
 		te.errs = append(te.errs, serror.New(err))
@@ -354,6 +459,7 @@ func (s *scheduler) createTask(sch schedule.Schedule, wfMap *wmap.WorkflowMap, s
 		f.Error("errors during task creation")
 		return nil, te
 	}
+	s.saveTask(task)
 
 	logger.WithFields(log.Fields{
 		"task-id":    task.ID(),
@@ -410,7 +516,20 @@ func (s *scheduler) removeTask(id, source string) error {
 	}
 
 	defer s.eventManager.Emit(event)
-	return s.tasks.remove(t)
+	if err := s.tasks.remove(t); err != nil {
+		return err
+	}
+	s.removeTaskRecord(t.ID())
+	// the spooled metrics of the task cannot be replayed anymore
+	if s.spool != nil {
+		if _, err := s.spool.purge(t.ID()); err != nil {
+			logger.WithFields(log.Fields{
+				"_error":  err.Error(),
+				"task id": id,
+			}).Error("error purging the spooled metrics of the task")
+		}
+	}
+	return nil
 }
 
 // GetTasks returns a copy of the tasks in a map where the task id is the key
@@ -436,6 +555,160 @@ func (s *scheduler) GetTask(id string) (core.Task, error) {
 	return t, nil
 }
 
+// GetTaskHistory returns the records of the most recent runs of a task,
+// oldest first.
+func (s *scheduler) GetTaskHistory(id string) ([]core.TaskRun, error) {
+	t, err := s.getTask(id)
+	if err != nil {
+		schedulerLogger.WithFields(log.Fields{
+			"_block":  "get-task-history",
+			"_error":  ErrTaskNotFound,
+			"task-id": id,
+		}).Error("error getting task history")
+		return nil, err
+	}
+	return t.History(), nil
+}
+
+// GetSpool describes the spool and the entries of the task with the given
+// ID, or of all the tasks if the ID is empty.
+func (s *scheduler) GetSpool(taskID string) (core.SpoolStats, error) {
+	if s.spool == nil {
+		return core.SpoolStats{}, ErrSpoolDisabled
+	}
+	return s.spool.stats(taskID)
+}
+
+// PurgeSpool removes the entries of the task with the given ID, or of all
+// the tasks if the ID is empty, from the spool and returns the number of
+// entries removed.
+func (s *scheduler) PurgeSpool(taskID string) (int, error) {
+	if s.spool == nil {
+		return 0, ErrSpoolDisabled
+	}
+	n, err := s.spool.purge(taskID)
+	if err != nil {
+		return n, err
+	}
+	schedulerLogger.WithFields(log.Fields{
+		"_block":  "purge-spool",
+		"task-id": taskID,
+		"count":   n,
+	}).Info("spool purged")
+	return n, nil
+}
+
+// checkSpool returns an error if a publish node of the workflow spools the
+// metrics it fails to publish while the spool is disabled
+func (s *scheduler) checkSpool(wf *schedulerWorkflow) error {
+	if s.spool != nil || !spoolsMetrics(wf.processNodes, wf.publishNodes) {
+		return nil
+	}
+	return fmt.Errorf("%v: a publish node of the workflow spools its metrics", ErrSpoolDisabled)
+}
+
+func spoolsMetrics(prnodes []*processNode, pbnodes []*publishNode) bool {
+	for _, pr := range prnodes {
+		if spoolsMetrics(pr.ProcessNodes, pr.PublishNodes) {
+			return true
+		}
+	}
+	for _, pb := range pbnodes {
+		if pb.retry != nil && pb.retry.Spool {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTaskLatencies returns the latency distributions of the runs of a task
+// and of the jobs run for each node of its workflow.
+func (s *scheduler) GetTaskLatencies(id string) (core.TaskLatencies, error) {
+	t, err := s.getTask(id)
+	if err != nil {
+		schedulerLogger.WithFields(log.Fields{
+			"_block":  "get-task-latencies",
+			"_error":  ErrTaskNotFound,
+			"task-id": id,
+		}).Error("error getting task latencies")
+		return core.TaskLatencies{}, err
+	}
+	return t.Latencies(), nil
+}
+
+// FireTask runs the workflow of a task once, right away and out of its
+// schedule, and returns the record of the run. The run is recorded in the
+// history of the task and counts as a hit. A task which is not running is
+// only fired if force is set.
+func (s *scheduler) FireTask(id string, force bool) (core.TaskRun, []serror.SnapError) {
+	logger := schedulerLogger.WithFields(log.Fields{
+		"_block":  "fire-task",
+		"task-id": id,
+		"force":   force,
+	})
+	t, err := s.getTask(id)
+	if err != nil {
+		logger.WithFields(log.Fields{
+			"_error": err.Error(),
+		}).Error("error firing task")
+		return core.TaskRun{}, []serror.SnapError{serror.New(err)}
+	}
+	run, errs := t.fireOnDemand(force)
+	if len(errs) > 0 {
+		f := buildErrorsLog(errs, logger)
+		f.Error("error firing task")
+		return core.TaskRun{}, errs
+	}
+	logger.WithFields(log.Fields{
+		"task-state": t.State(),
+		"failed":     run.Failed,
+	}).Info("task fired on demand")
+	return run, nil
+}
+
+// QueueStats returns the depth of the queues of the work manager, in total
+// and for each task priority, and the utilization of their worker pools,
+// keyed by the type of their jobs.
+func (s *scheduler) QueueStats() map[string]core.WorkQueueStats {
+	return s.workManager.QueueStats()
+}
+
+// ResizeWorkQueue sets the limit of the work manager queue with the given
+// name and the size of its worker pool without restarting the scheduler,
+// and returns the stats of the resized queue.
+func (s *scheduler) ResizeWorkQueue(name string, limit, workers uint) (core.WorkQueueStats, error) {
+	logger := schedulerLogger.WithFields(log.Fields{
+		"_block":  "resize-work-queue",
+		"queue":   name,
+		"limit":   limit,
+		"workers": workers,
+	})
+	t, ok := workQueueTypes[name]
+	if !ok {
+		logger.Error(ErrWorkQueueNotFound)
+		return core.WorkQueueStats{}, fmt.Errorf("%v: %s", ErrWorkQueueNotFound, name)
+	}
+	if workers == 0 {
+		logger.Error(ErrWorkQueueNoWorkers)
+		return core.WorkQueueStats{}, ErrWorkQueueNoWorkers
+	}
+	s.workManager.Resize(t, limit, workers)
+	logger.Info("work queue resized")
+	return s.workManager.QueueStats()[name], nil
+}
+
+// ResizeWorkQueues sets the limit of all the work manager queues and the
+// size of their worker pools, as set by WorkManagerQueueSize and
+// WorkManagerPoolSize when the scheduler is created.
+func (s *scheduler) ResizeWorkQueues(queueSize, poolSize uint) error {
+	for name := range workQueueTypes {
+		if _, err := s.ResizeWorkQueue(name, queueSize, poolSize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // StartTask provided a task id a task is started
 func (s *scheduler) StartTask(id string) []serror.SnapError {
 	return s.startTask(id, "user")
@@ -519,6 +792,7 @@ func (s *scheduler) startTask(id, source string) []serror.SnapError {
 	}
 	defer s.eventManager.Emit(event)
 	t.Spin()
+	s.saveTask(t)
 	logger.WithFields(log.Fields{
 		"task-id":    t.ID(),
 		"task-state": t.State(),
@@ -569,6 +843,12 @@ func (s *scheduler) stopTask(id, source string) []serror.SnapError {
 			serror.New(ErrTaskDisabledNotStoppable),
 		}
 	default:
+		// The task is stopped before its plugins are unsubscribed, so its
+		// runs in progress complete and the batches of its workflow are
+		// published while they are still subscribed.
+		t.Stop()
+		t.awaitStop()
+		s.saveTask(t)
 		// Group dependencies by the host they live on and
 		// unsubscribe them since task is stopping.
 		depGroups := getWorkflowPlugins(t.workflow.processNodes, t.workflow.publishNodes, t.workflow.metrics)
@@ -583,27 +863,293 @@ func (s *scheduler) stopTask(id, source string) []serror.SnapError {
 					errs = append(errs, uerrs...)
 				}
 			}
-			if len(errs) > 0 {
-				return errs
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+
+		event := &scheduler_event.TaskStoppedEvent{
+			TaskID: t.ID(),
+			Source: source,
+		}
+		defer s.eventManager.Emit(event)
+		logger.WithFields(log.Fields{
+			"task-id":    t.ID(),
+			"task-state": t.State(),
+		}).Info("task stopped")
+	}
+
+	return nil
+}
+
+// UpdateTask replaces the schedule and/or the workflow of a task in place, so
+// the task keeps its ID and counters. A nil schedule or workflow map keeps the
+// current one. The dependencies of the new workflow are validated, and for a
+// running task subscribed, before the current ones are unsubscribed; if any of
+// this fails the task is left untouched. A running task waits on a new
+// schedule right away.
+func (s *scheduler) UpdateTask(id string, sch schedule.Schedule, wfMap *wmap.WorkflowMap) (core.Task, []serror.SnapError) {
+	return s.updateTask(id, sch, wfMap, "user")
+}
+
+func (s *scheduler) updateTask(id string, sch schedule.Schedule, wfMap *wmap.WorkflowMap, source string) (core.Task, []serror.SnapError) {
+	logger := schedulerLogger.WithFields(log.Fields{
+		"_block":  "update-task",
+		"source":  source,
+		"task-id": id,
+	})
+	t, err := s.getTask(id)
+	if err != nil {
+		logger.WithFields(log.Fields{
+			"_error": err.Error(),
+		}).Error("error updating task")
+		return nil, []serror.SnapError{serror.New(err)}
+	}
+	if sch == nil && wfMap == nil {
+		logger.Error(ErrTaskUpdateEmpty)
+		return nil, []serror.SnapError{serror.New(ErrTaskUpdateEmpty)}
+	}
+
+	// Ensure the new schedule is valid at this point and time.
+	if sch != nil {
+		if err := sch.Validate(); err != nil {
+			logger.WithFields(log.Fields{
+				"_error": err.Error(),
+			}).Error("schedule passed not valid")
+			return nil, []serror.SnapError{serror.New(err)}
+		}
+		if err := s.checkTriggers(id, sch, false); err != nil {
+			logger.WithFields(log.Fields{
+				"_error": err.Error(),
+			}).Error("schedule passed not valid")
+			return nil, []serror.SnapError{serror.New(err)}
+		}
+	}
+
+	// Generate the new workflow and validate its dependencies
+	var wf *schedulerWorkflow
+	var mgrs managers
+	if wfMap != nil {
+		wf, err = wmapToWorkflow(wfMap)
+		if err == nil {
+			err = s.checkSpool(wf)
+		}
+		if err != nil {
+			logger.WithFields(log.Fields{
+				"_error": err.Error(),
+			}).Error("Unable to generate workflow from workflow map")
+			return nil, []serror.SnapError{serror.New(err)}
+		}
+		wf.eventEmitter = s.eventManager
+		mgrs = newManagers(s.metricManager)
+		if err := createTaskClients(&mgrs, wf); err != nil {
+			logger.WithFields(log.Fields{
+				"_error": err.Error(),
+			}).Error("Unable to create task clients")
+			return nil, []serror.SnapError{serror.New(fmt.Errorf("%v: %v", ErrTaskClientsUnavailable, err))}
+		}
+		depGroups := getWorkflowPlugins(wf.processNodes, wf.publishNodes, wf.metrics)
+		for k, group := range depGroups {
+			manager, err := mgrs.Get(k)
+			if err != nil {
+				return nil, []serror.SnapError{serror.New(fmt.Errorf("%v: %v", ErrTaskClientsUnavailable, err))}
+			}
+			if errs := manager.ValidateDeps(group.requestedMetrics, group.subscribedPlugins, wf.configTree); len(errs) > 0 {
+				f := buildErrorsLog(errs, logger)
+				f.Error("errors validating the task dependencies")
+				return nil, errs
 			}
+		}
+	}
 
-			event := &scheduler_event.TaskStoppedEvent{
-				TaskID: t.ID(),
-				Source: source,
+	// Holding the workflow lock of the task waits for its runs in progress
+	// to complete, and along with the lock of the task ensures it does not
+	// fire while its workflow is swapped.
+	if wf != nil {
+		t.workflowMutex.Lock()
+		defer t.workflowMutex.Unlock()
+		// the nodes of the current workflow are dropped, so their batches
+		// are published while their plugins are still subscribed
+		t.workflow.flushBatches(t)
+	}
+	t.Lock()
+	// an ended task keeps the subscriptions of its workflow until it is
+	// stopped, so its workflow is not swapped
+	if t.state == core.TaskEnded {
+		t.Unlock()
+		logger.Error(ErrTaskEndedNotUpdatable)
+		return nil, []serror.SnapError{serror.New(ErrTaskEndedNotUpdatable)}
+	}
+	if wf != nil {
+		if t.state == core.TaskSpinning || t.state == core.TaskFiring {
+			if errs := swapWorkflowDeps(t.id, t.workflow, t.RemoteManagers, wf, mgrs); len(errs) > 0 {
+				t.Unlock()
+				f := buildErrorsLog(errs, logger)
+				f.Error("errors subscribing the task dependencies")
+				return nil, errs
 			}
-			defer s.eventManager.Emit(event)
-			t.Stop()
-			logger.WithFields(log.Fields{
-				"task-id":    t.ID(),
-				"task-state": t.State(),
-			}).Info("task stopped")
+		}
+		t.workflow = wf
+		t.RemoteManagers = mgrs
+	}
+	if sch != nil {
+		t.schedule = sch
+	}
+	t.Unlock()
+	if sch != nil {
+		s.tasks.reindex(t)
+		t.reschedule()
+	}
+	s.saveTask(t)
 
+	event := &scheduler_event.TaskUpdatedEvent{
+		TaskID: t.ID(),
+		Source: source,
+	}
+	defer s.eventManager.Emit(event)
+	logger.WithFields(log.Fields{
+		"task-state":       t.State(),
+		"schedule-updated": sch != nil,
+		"workflow-updated": wf != nil,
+	}).Info("task updated")
+	return t, nil
+}
+
+// checkTriggers follows the chain of tasks triggering a task with a
+// triggered-by schedule and returns ErrTriggerCycle if it leads back to the
+// task. A missing triggering task ends the chain, which is an error unless
+// allowMissing is set.
+func (s *scheduler) checkTriggers(id string, sch schedule.Schedule, allowMissing bool) error {
+	visited := map[string]bool{}
+	for {
+		tsch, ok := sch.(*schedule.TriggeredSchedule)
+		if !ok {
+			return nil
+		}
+		if tsch.TaskID == id {
+			return ErrTriggerCycle
+		}
+		// a cycle which does not include the task cannot be created, but
+		// guards the walk against one anyway
+		if visited[tsch.TaskID] {
+			return nil
+		}
+		visited[tsch.TaskID] = true
+		upstream := s.tasks.Get(tsch.TaskID)
+		if upstream == nil {
+			if allowMissing {
+				return nil
+			}
+			return ErrTriggeringTaskNotFound
+		}
+		sch = upstream.Schedule()
+	}
+}
+
+// triggerTasks fires the running tasks with a triggered-by schedule on the
+// task whose workflow completed.
+func (s *scheduler) triggerTasks(id string, failed bool) {
+	s.tasks.Lock()
+	defer s.tasks.Unlock()
+	for _, t := range s.tasks.table {
+		sch, ok := t.Schedule().(*schedule.TriggeredSchedule)
+		if !ok || sch.TaskID != id || (failed && sch.SuccessOnly) {
+			continue
+		}
+		if state := t.State(); state != core.TaskSpinning && state != core.TaskFiring {
+			continue
+		}
+		sch.Trigger()
+	}
+}
+
+// notifyEventTasks passes an event to the running tasks with an event
+// schedule. A task is not fired by the events about itself, which would
+// otherwise fire it after each of its own runs.
+func (s *scheduler) notifyEventTasks(e gomit.Event) {
+	tasks := s.tasks.EventTasks()
+	if len(tasks) == 0 {
+		return
+	}
+	id, hasID := schedule.EventField(e.Body, "TaskID")
+	for _, t := range tasks {
+		sch, ok := t.Schedule().(*schedule.EventSchedule)
+		if !ok || (hasID && id == t.id) {
+			continue
+		}
+		if state := t.State(); state != core.TaskSpinning && state != core.TaskFiring {
+			continue
 		}
+		sch.Notify(e.Namespace(), e.Body)
 	}
+}
 
+// swapWorkflowDeps moves the subscriptions of a running task from the
+// dependencies of its current workflow to the ones of a new workflow. The new
+// dependencies are first subscribed under a staging ID, so the plugins both
+// workflows depend on keep running while the subscriptions of the task are
+// replaced. If the new dependencies cannot be subscribed the current
+// subscriptions are left untouched.
+func swapWorkflowDeps(id string, oldWf *schedulerWorkflow, oldMgrs managers, newWf *schedulerWorkflow, newMgrs managers) []serror.SnapError {
+	oldGroups := getWorkflowPlugins(oldWf.processNodes, oldWf.publishNodes, oldWf.metrics)
+	newGroups := getWorkflowPlugins(newWf.processNodes, newWf.publishNodes, newWf.metrics)
+	stagingID := id + taskUpdateSuffix
+
+	// subscribe the new dependencies under the staging ID
+	if errs := subscribeDepGroups(stagingID, newGroups, newMgrs, newWf.configTree); len(errs) > 0 {
+		return errs
+	}
+	defer unsubscribeDepGroups(stagingID, newGroups, newMgrs)
+
+	// replace the subscriptions of the task
+	unsubscribeDepGroups(id, oldGroups, oldMgrs)
+	if errs := subscribeDepGroups(id, newGroups, newMgrs, newWf.configTree); len(errs) > 0 {
+		// restore the subscriptions of the current workflow
+		subscribeDepGroups(id, oldGroups, oldMgrs, oldWf.configTree)
+		return errs
+	}
+	return nil
+}
+
+// subscribeDepGroups subscribes the given dependency groups under an ID. If
+// any of the groups fails to subscribe, all of them are unsubscribed.
+func subscribeDepGroups(id string, depGroups depGroupMap, mgrs managers, cfgTree *cdata.ConfigDataTree) []serror.SnapError {
+	var subbedDeps []string
+	for k, group := range depGroups {
+		var errs []serror.SnapError
+		mgr, err := mgrs.Get(k)
+		if err != nil {
+			errs = append(errs, serror.New(err))
+		} else {
+			errs = mgr.SubscribeDeps(id, group.requestedMetrics, group.subscribedPlugins, cfgTree)
+			subbedDeps = append(subbedDeps, k)
+		}
+		if len(errs) > 0 {
+			for _, key := range subbedDeps {
+				if mgr, err := mgrs.Get(key); err == nil {
+					errs = append(errs, mgr.UnsubscribeDeps(id)...)
+				}
+			}
+			return errs
+		}
+	}
 	return nil
 }
 
+// unsubscribeDepGroups unsubscribes the given dependency groups from an ID
+func unsubscribeDepGroups(id string, depGroups depGroupMap, mgrs managers) []serror.SnapError {
+	var errs []serror.SnapError
+	for k := range depGroups {
+		mgr, err := mgrs.Get(k)
+		if err != nil {
+			errs = append(errs, serror.New(err))
+			continue
+		}
+		errs = append(errs, mgr.UnsubscribeDeps(id)...)
+	}
+	return errs
+}
+
 //EnableTask changes state from disabled to stopped
 func (s *scheduler) EnableTask(id string) (core.Task, error) {
 	t, e := s.getTask(id)
@@ -625,6 +1171,7 @@ func (s *scheduler) EnableTask(id string) (core.Task, error) {
 		}).Error("error enabling task")
 		return nil, err
 	}
+	s.saveTask(t)
 	schedulerLogger.WithFields(log.Fields{
 		"_block":     "enable-task",
 		"task-id":    t.ID(),
@@ -647,6 +1194,14 @@ func (s *scheduler) Start() error {
 		"_block": "start-scheduler",
 	}).Info("scheduler started")
 
+	if err := s.restoreTasks(); err != nil {
+		schedulerLogger.WithFields(log.Fields{
+			"_block": "start-scheduler",
+			"_error": err.Error(),
+		}).Error("error restoring tasks")
+		return err
+	}
+
 	//Autodiscover
 	autoDiscoverPaths := s.metricManager.GetAutodiscoverPaths()
 	if autoDiscoverPaths != nil && len(autoDiscoverPaths) != 0 {
@@ -687,7 +1242,7 @@ func (s *scheduler) Start() error {
 				}
 				taskFiles = append(taskFiles, file)
 			}
-			autoDiscoverTasks(taskFiles, fullPath, s.CreateTask)
+			autoDiscoverTasks(taskFiles, fullPath, s.createAutodiscoveredTask)
 		}
 	} else {
 		schedulerLogger.WithFields(log.Fields{
@@ -710,6 +1265,163 @@ func (s *scheduler) Stop() {
 	}).Info("scheduler stopped")
 }
 
+// windowEnded returns whether the recorded schedule is a window which stopped
+// while the task was not running, so the task would end once restored.
+func windowEnded(sch *core.Schedule) bool {
+	return sch.Type == "windowed" && sch.StopTimestamp != nil && time.Now().After(time.Unix(*sch.StopTimestamp, 0))
+}
+
+// restoreTasks recreates the tasks held by the task store. Tasks which were
+// running when snapd stopped are started again and disabled tasks stay
+// disabled. The records of ended tasks are removed, and records which can no
+// longer be turned into a task are logged and left in the store.
+func (s *scheduler) restoreTasks() error {
+	if s.taskStore == nil {
+		return nil
+	}
+	records, err := s.taskStore.Load()
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		logger := schedulerLogger.WithFields(log.Fields{
+			"_block":  "restore-tasks",
+			"task-id": r.ID,
+		})
+		if r.Schedule == nil || r.Workflow == nil {
+			logger.Error("task record is missing a schedule or a workflow")
+			continue
+		}
+		if r.State == core.TaskEnded || windowEnded(r.Schedule) {
+			logger.Debug("task ended, removing its record")
+			s.removeTaskRecord(r.ID)
+			continue
+		}
+		sch, err := core.MakeSchedule(*r.Schedule)
+		if err != nil {
+			logger.WithFields(log.Fields{
+				"_error": err.Error(),
+			}).Error("unable to restore the task schedule")
+			continue
+		}
+		opts := []core.TaskOption{
+			core.SetTaskID(r.ID),
+			core.SetTaskName(r.Name),
+			core.OptionStopOnFailure(r.StopOnFailure),
+		}
+		cp, err := core.ParseCollectPolicy(r.CollectPolicy)
+		if err != nil {
+			logger.WithFields(log.Fields{
+				"_error": err.Error(),
+			}).Error("unable to restore the task collect policy")
+			continue
+		}
+		pr, err := core.ParseTaskPriority(r.Priority)
+		if err != nil {
+			logger.WithFields(log.Fields{
+				"_error": err.Error(),
+			}).Error("unable to restore the task priority")
+			continue
+		}
+		opts = append(opts, core.OptionCollectPolicy(cp), core.OptionFailurePolicy(r.FailurePolicy), core.OptionCatchupPolicy(r.Catchup),
+			core.OptionPriority(pr), core.OptionOverlapPolicy(r.Overlap))
+		if r.Deadline != "" {
+			dl, err := time.ParseDuration(r.Deadline)
+			if err != nil {
+				logger.WithFields(log.Fields{
+					"_error": err.Error(),
+				}).Error("unable to restore the task deadline")
+				continue
+			}
+			opts = append(opts, core.TaskDeadlineDuration(dl))
+		}
+		if r.LatencySLO != "" {
+			slo, err := time.ParseDuration(r.LatencySLO)
+			if err != nil {
+				logger.WithFields(log.Fields{
+					"_error": err.Error(),
+				}).Error("unable to restore the task latency objective")
+				continue
+			}
+			opts = append(opts, core.OptionLatencySLO(slo))
+		}
+		t, te := s.createTask(sch, r.Workflow, false, "store", opts...)
+		if len(te.Errors()) > 0 {
+			f := buildErrorsLog(te.Errors(), logger)
+			f.Error("unable to restore task")
+			continue
+		}
+		switch r.State {
+		case core.TaskSpinning:
+			if errs := s.startTask(t.ID(), "store"); errs != nil {
+				f := buildErrorsLog(errs, logger)
+				f.Error("unable to start restored task")
+			}
+		case core.TaskDisabled:
+			tsk := t.(*task)
+			tsk.Lock()
+			tsk.state = core.TaskDisabled
+			tsk.Unlock()
+			s.saveTask(tsk)
+		}
+		logger.WithFields(log.Fields{
+			"task-state": t.State(),
+		}).Info("task restored")
+	}
+	return nil
+}
+
+// createAutodiscoveredTask creates a task loaded from an autodiscover path
+func (s *scheduler) createAutodiscoveredTask(sch schedule.Schedule, wfMap *wmap.WorkflowMap, startOnCreate bool, opts ...core.TaskOption) (core.Task, core.TaskErrors) {
+	return s.createTask(sch, wfMap, startOnCreate, "autodiscover", opts...)
+}
+
+// saveTask records a task in the task store, if one is set
+func (s *scheduler) saveTask(t *task) {
+	if s.taskStore == nil || !t.persistent {
+		return
+	}
+	if err := s.taskStore.Save(newTaskRecord(t)); err != nil {
+		schedulerLogger.WithFields(log.Fields{
+			"_block":  "save-task",
+			"_error":  err.Error(),
+			"task-id": t.ID(),
+		}).Error("error saving task")
+	}
+}
+
+// removeTaskRecord removes the record of a task from the task store, if one
+// is set
+func (s *scheduler) removeTaskRecord(id string) {
+	if s.taskStore == nil {
+		return
+	}
+	if err := s.taskStore.Remove(id); err != nil {
+		schedulerLogger.WithFields(log.Fields{
+			"_block":  "remove-task-record",
+			"_error":  err.Error(),
+			"task-id": id,
+		}).Error("error removing task record")
+	}
+}
+
+// SetTaskStore sets the store used to persist tasks. It must be set before the
+// scheduler is started.
+func (s *scheduler) SetTaskStore(ts TaskStore) {
+	s.taskStore = ts
+	schedulerLogger.WithFields(log.Fields{
+		"_block": "set-task-store",
+	}).Debug("task store linked")
+}
+
+// FlushBatches publishes the metrics buffered by the batching publish nodes
+// of the tasks, so they are published before snapd stops the plugins.
+func (s *scheduler) FlushBatches() {
+	for _, t := range s.tasks.Table() {
+		t.flushBatches()
+	}
+}
+
 // Set metricManager for scheduler
 func (s *scheduler) SetMetricManager(mm managesMetrics) {
 	s.metricManager = mm
@@ -734,6 +1446,7 @@ func (s *scheduler) WatchTask(id string, tw core.TaskWatcherHandler) (core.TaskW
 
 // Central handling for all async events in scheduler
 func (s *scheduler) HandleGomitEvent(e gomit.Event) {
+	s.notifyEventTasks(e)
 
 	switch v := e.Body.(type) {
 	case *scheduler_event.MetricCollectedEvent:
@@ -745,6 +1458,24 @@ func (s *scheduler) HandleGomitEvent(e gomit.Event) {
 			"metric-count":    len(v.Metrics),
 		}).Debug("event received")
 		s.taskWatcherColl.handleMetricCollected(v.TaskID, v.Metrics)
+	case *scheduler_event.WorkflowCompletedEvent:
+		log.WithFields(log.Fields{
+			"_module":         "scheduler-events",
+			"_block":          "handle-events",
+			"event-namespace": e.Namespace(),
+			"task-id":         v.TaskID,
+			"failed":          v.Failed,
+		}).Debug("event received")
+		s.triggerTasks(v.TaskID, v.Failed)
+	case *scheduler_event.LatencySLOBreachedEvent:
+		log.WithFields(log.Fields{
+			"_module":         "scheduler-events",
+			"_block":          "handle-events",
+			"event-namespace": e.Namespace(),
+			"task-id":         v.TaskID,
+			"latency":         v.Latency.String(),
+			"latency-slo":     v.SLO.String(),
+		}).Debug("event received")
 	case *scheduler_event.MetricCollectionFailedEvent:
 		log.WithFields(log.Fields{
 			"_module":         "scheduler-events",
@@ -786,7 +1517,17 @@ func (s *scheduler) HandleGomitEvent(e gomit.Event) {
 				mgr.UnsubscribeDeps(task.ID())
 			}
 		}
+		s.saveTask(task)
 		s.taskWatcherColl.handleTaskDisabled(v.TaskID, v.Why)
+	case *scheduler_event.TaskEndedEvent:
+		log.WithFields(log.Fields{
+			"_module":         "scheduler-events",
+			"_block":          "handle-events",
+			"event-namespace": e.Namespace(),
+			"task-id":         v.TaskID,
+		}).Debug("event received")
+		// An ended task is not restored, so its record is removed
+		s.removeTaskRecord(v.TaskID)
 	default:
 		log.WithFields(log.Fields{
 			"_module":         "scheduler-events",
@@ -818,6 +1559,11 @@ func getWorkflowPlugins(prnodes []*processNode, pbnodes []*publishNode, requeste
 
 func walkWorkflowForDeps(prnodes []*processNode, pbnodes []*publishNode, requestedMetrics []core.RequestedMetric, depGroup depGroupMap) depGroupMap {
 	for _, pr := range prnodes {
+		// transform nodes need no plugin, only their child nodes do
+		if pr.transform != nil {
+			walkWorkflowForDeps(pr.ProcessNodes, pr.PublishNodes, requestedMetrics, depGroup)
+			continue
+		}
 		processors := depGroup[pr.Target]
 		if _, ok := depGroup[pr.Target]; ok {
 			processors.subscribedPlugins = append(processors.subscribedPlugins, pr)
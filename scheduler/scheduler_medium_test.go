@@ -44,15 +44,15 @@ type mockMetricManager struct {
 	autodiscoverPaths          []string
 }
 
-func (m *mockMetricManager) CollectMetrics(string, map[string]map[string]string) ([]core.Metric, []error) {
+func (m *mockMetricManager) CollectMetrics(string, map[string]map[string]string, time.Time) ([]core.Metric, []error) {
 	return nil, nil
 }
 
-func (m *mockMetricManager) PublishMetrics([]core.Metric, map[string]ctypes.ConfigValue, string, string, int) []error {
+func (m *mockMetricManager) PublishMetrics([]core.Metric, map[string]ctypes.ConfigValue, string, string, int, time.Time) []error {
 	return nil
 }
 
-func (m *mockMetricManager) ProcessMetrics([]core.Metric, map[string]ctypes.ConfigValue, string, string, int) ([]core.Metric, []error) {
+func (m *mockMetricManager) ProcessMetrics([]core.Metric, map[string]ctypes.ConfigValue, string, string, int, time.Time) ([]core.Metric, []error) {
 	return nil, nil
 }
 
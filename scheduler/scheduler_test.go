@@ -24,6 +24,8 @@ package scheduler
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
 	"time"
 
@@ -45,17 +47,19 @@ type mockMetricManager struct {
 	failValidatingMetricsAfter int
 	failuredSoFar              int
 	autodiscoverPaths          []string
+	// onUnsubscribe is called as the dependencies of a task are unsubscribed
+	onUnsubscribe func(taskID string)
 }
 
-func (m *mockMetricManager) CollectMetrics(string, map[string]map[string]string) ([]core.Metric, []error) {
+func (m *mockMetricManager) CollectMetrics(string, map[string]map[string]string, time.Time) ([]core.Metric, []error) {
 	return nil, nil
 }
 
-func (m *mockMetricManager) PublishMetrics([]core.Metric, map[string]ctypes.ConfigValue, string, string, int) []error {
+func (m *mockMetricManager) PublishMetrics([]core.Metric, map[string]ctypes.ConfigValue, string, string, int, time.Time) []error {
 	return nil
 }
 
-func (m *mockMetricManager) ProcessMetrics([]core.Metric, map[string]ctypes.ConfigValue, string, string, int) ([]core.Metric, []error) {
+func (m *mockMetricManager) ProcessMetrics([]core.Metric, map[string]ctypes.ConfigValue, string, string, int, time.Time) ([]core.Metric, []error) {
 	return nil, nil
 }
 func (m *mockMetricManager) ValidateDeps(mts []core.RequestedMetric, prs []core.SubscribedPlugin, cdt *cdata.ConfigDataTree) []serror.SnapError {
@@ -73,6 +77,9 @@ func (m *mockMetricManager) SubscribeDeps(taskID string, req []core.RequestedMet
 }
 
 func (m *mockMetricManager) UnsubscribeDeps(taskID string) []serror.SnapError {
+	if m.onUnsubscribe != nil {
+		m.onUnsubscribe(taskID)
+	}
 	return nil
 }
 
@@ -268,6 +275,20 @@ func TestScheduler(t *testing.T) {
 				So(len(err), ShouldEqual, 1)
 				So(err[0].Error(), ShouldEqual, "Task is already stopped.")
 			})
+			Convey("stop a running task before unsubscribing its dependencies", func() {
+				var states []core.TaskState
+				c.onUnsubscribe = func(string) {
+					states = append(states, tsk.State())
+				}
+				defer func() { c.onUnsubscribe = nil }()
+				tsk.(*task).Spin()
+				err := s.StopTask(tsk.ID())
+				So(err, ShouldBeEmpty)
+				So(states, ShouldNotBeEmpty)
+				for _, state := range states {
+					So(state, ShouldEqual, core.TaskStopped)
+				}
+			})
 		})
 
 		// 		// // TODO NICK
@@ -311,6 +332,192 @@ func TestScheduler(t *testing.T) {
 			So(err[0].Error(), ShouldResemble, "Task is disabled. Cannot be started.")
 			So(t.state, ShouldEqual, core.TaskDisabled)
 		})
+		Convey("Update a task", func() {
+			tsk, _ := s.CreateTask(schedule.NewSimpleSchedule(time.Second*1), w, false)
+			So(tsk, ShouldNotBeNil)
+			tsk.(*task).hitCount = 3
+
+			Convey("replaces the schedule and workflow in place", func() {
+				w2 := wmap.NewWorkflowMap()
+				w2.CollectNode.AddMetric("/foo/qux", 1)
+				utsk, errs := s.UpdateTask(tsk.ID(), schedule.NewSimpleSchedule(time.Second*5), w2)
+				So(errs, ShouldBeEmpty)
+				So(utsk.ID(), ShouldEqual, tsk.ID())
+				So(utsk.HitCount(), ShouldEqual, 3)
+				So(utsk.State(), ShouldEqual, core.TaskStopped)
+				So(utsk.Schedule().(*schedule.SimpleSchedule).Interval, ShouldEqual, time.Second*5)
+				So(utsk.WMap().CollectNode.Metrics, ShouldContainKey, "/foo/qux")
+			})
+			Convey("keeps the workflow when only the schedule is given", func() {
+				utsk, errs := s.UpdateTask(tsk.ID(), schedule.NewSimpleSchedule(time.Second*5), nil)
+				So(errs, ShouldBeEmpty)
+				So(utsk.WMap().CollectNode.Metrics, ShouldContainKey, "/foo/bar")
+			})
+			Convey("returns an error when the task doesn't exist", func() {
+				_, errs := s.UpdateTask("1234", schedule.NewSimpleSchedule(time.Second*5), nil)
+				So(len(errs), ShouldEqual, 1)
+				So(errs[0].Error(), ShouldContainSubstring, ErrTaskNotFound.Error())
+			})
+			Convey("returns an error when neither a schedule nor a workflow is given", func() {
+				_, errs := s.UpdateTask(tsk.ID(), nil, nil)
+				So(len(errs), ShouldEqual, 1)
+				So(errs[0].Error(), ShouldEqual, ErrTaskUpdateEmpty.Error())
+			})
+			Convey("leaves the task untouched when the schedule does not validate", func() {
+				_, errs := s.UpdateTask(tsk.ID(), schedule.NewSimpleSchedule(0), nil)
+				So(len(errs), ShouldEqual, 1)
+				So(tsk.Schedule().(*schedule.SimpleSchedule).Interval, ShouldEqual, time.Second)
+			})
+			Convey("leaves the task untouched when the new workflow does not validate", func() {
+				c.failValidatingMetrics = true
+				w2 := wmap.NewWorkflowMap()
+				w2.CollectNode.AddMetric("/foo/qux", 1)
+				_, errs := s.UpdateTask(tsk.ID(), nil, w2)
+				So(len(errs), ShouldBeGreaterThan, 0)
+				So(tsk.WMap().CollectNode.Metrics, ShouldContainKey, "/foo/bar")
+			})
+			Convey("returns an error when the task has ended", func() {
+				tsk.(*task).state = core.TaskEnded
+				w2 := wmap.NewWorkflowMap()
+				w2.CollectNode.AddMetric("/foo/qux", 1)
+				_, errs := s.UpdateTask(tsk.ID(), nil, w2)
+				So(len(errs), ShouldEqual, 1)
+				So(errs[0].Error(), ShouldEqual, ErrTaskEndedNotUpdatable.Error())
+				So(tsk.WMap().CollectNode.Metrics, ShouldContainKey, "/foo/bar")
+			})
+		})
+		Convey("Fire a task", func() {
+			tsk, _ := s.CreateTask(schedule.NewSimpleSchedule(time.Second*1), w, false)
+			So(tsk, ShouldNotBeNil)
+
+			Convey("runs the workflow once and records the run", func() {
+				// the dependencies of an ended task are still subscribed
+				tsk.(*task).state = core.TaskEnded
+				run, errs := s.FireTask(tsk.ID(), true)
+				So(errs, ShouldBeEmpty)
+				So(run.Failed, ShouldBeFalse)
+				So(run.Jobs, ShouldNotBeEmpty)
+				So(tsk.HitCount(), ShouldEqual, 1)
+				So(tsk.State(), ShouldEqual, core.TaskEnded)
+				So(len(tsk.(*task).History()), ShouldEqual, 1)
+			})
+			Convey("counts the run of a spinning task with the runs of its schedule", func() {
+				t := tsk.(*task)
+				done := make(chan core.TaskRun, 1)
+				t.state = core.TaskSpinning
+				t.runsDone = done
+				run, errs := s.FireTask(tsk.ID(), false)
+				So(errs, ShouldBeEmpty)
+				So(tsk.HitCount(), ShouldEqual, 1)
+				So(tsk.State(), ShouldEqual, core.TaskFiring)
+				So(t.runs, ShouldEqual, 1)
+				So(<-done, ShouldResemble, run)
+				t.runDone()
+				So(tsk.State(), ShouldEqual, core.TaskSpinning)
+			})
+			Convey("returns an error when a spinning task is busy", func() {
+				t := tsk.(*task)
+				t.state = core.TaskFiring
+				t.runsDone = make(chan core.TaskRun)
+				t.runs = 1
+				_, errs := s.FireTask(tsk.ID(), true)
+				So(len(errs), ShouldEqual, 1)
+				So(errs[0].Error(), ShouldEqual, ErrTaskBusy.Error())
+				So(tsk.HitCount(), ShouldEqual, 0)
+				So(tsk.BusySkipCount(), ShouldEqual, 1)
+			})
+			Convey("returns an error when the task is not running and not forced", func() {
+				_, errs := s.FireTask(tsk.ID(), false)
+				So(len(errs), ShouldEqual, 1)
+				So(errs[0].Error(), ShouldEqual, ErrTaskNotRunning.Error())
+				So(tsk.HitCount(), ShouldEqual, 0)
+			})
+			Convey("returns an error when the dependencies of a stopped task fail to subscribe", func() {
+				_, errs := s.FireTask(tsk.ID(), true)
+				So(len(errs), ShouldBeGreaterThan, 0)
+				So(tsk.HitCount(), ShouldEqual, 0)
+				So(tsk.State(), ShouldEqual, core.TaskStopped)
+			})
+			Convey("returns an error when the task is disabled", func() {
+				tsk.(*task).state = core.TaskDisabled
+				_, errs := s.FireTask(tsk.ID(), true)
+				So(len(errs), ShouldEqual, 1)
+				So(errs[0].Error(), ShouldEqual, ErrTaskDisabledNotFireable.Error())
+			})
+			Convey("returns an error when the task doesn't exist", func() {
+				_, errs := s.FireTask("1234", true)
+				So(len(errs), ShouldEqual, 1)
+				So(errs[0].Error(), ShouldContainSubstring, ErrTaskNotFound.Error())
+			})
+		})
+		Convey("Trigger a task", func() {
+			upstream, _ := s.CreateTask(schedule.NewSimpleSchedule(time.Second*1), w, false)
+			So(upstream, ShouldNotBeNil)
+			sch := schedule.NewTriggeredSchedule(upstream.ID(), false)
+			tsk, te := s.CreateTask(sch, w, false)
+			So(te.Errors(), ShouldBeEmpty)
+
+			Convey("fires it when the workflow of the triggering task completes", func() {
+				upstream.(*task).state = core.TaskEnded
+				tsk.(*task).state = core.TaskSpinning
+				_, errs := s.FireTask(upstream.ID(), true)
+				So(errs, ShouldBeEmpty)
+				stop := make(chan struct{})
+				time.AfterFunc(time.Second, func() { close(stop) })
+				So(sch.Wait(time.Time{}, stop).Error(), ShouldBeNil)
+			})
+			Convey("does not fire it when it is not running", func() {
+				upstream.(*task).state = core.TaskEnded
+				_, errs := s.FireTask(upstream.ID(), true)
+				So(errs, ShouldBeEmpty)
+				stop := make(chan struct{})
+				close(stop)
+				So(sch.Wait(time.Time{}, stop).Error(), ShouldEqual, schedule.ErrWaitCancelled)
+			})
+			Convey("returns an error when the triggering task doesn't exist", func() {
+				_, te := s.CreateTask(schedule.NewTriggeredSchedule("1234", false), w, false)
+				So(len(te.Errors()), ShouldEqual, 1)
+				So(te.Errors()[0].Error(), ShouldEqual, ErrTriggeringTaskNotFound.Error())
+			})
+			Convey("returns an error when the trigger would be a cycle", func() {
+				_, errs := s.UpdateTask(upstream.ID(), schedule.NewTriggeredSchedule(tsk.ID(), false), nil)
+				So(len(errs), ShouldEqual, 1)
+				So(errs[0].Error(), ShouldEqual, ErrTriggerCycle.Error())
+				_, errs = s.UpdateTask(tsk.ID(), schedule.NewTriggeredSchedule(tsk.ID(), false), nil)
+				So(len(errs), ShouldEqual, 1)
+				So(errs[0].Error(), ShouldEqual, ErrTriggerCycle.Error())
+			})
+		})
+		Convey("Fire a task on events", func() {
+			sch := schedule.NewEventSchedule([]string{control_event.PluginLoaded}, `Name == "mock"`)
+			tsk, te := s.CreateTask(sch, w, false)
+			So(te.Errors(), ShouldBeEmpty)
+			tsk.(*task).state = core.TaskSpinning
+			stop := make(chan struct{})
+			time.AfterFunc(time.Second, func() { close(stop) })
+
+			Convey("fires it on the events matching its schedule", func() {
+				s.HandleGomitEvent(gomit.Event{Body: &control_event.LoadPluginEvent{Name: "mock"}})
+				So(sch.Wait(time.Time{}, stop).Error(), ShouldBeNil)
+			})
+			Convey("does not fire it on other events", func() {
+				s.HandleGomitEvent(gomit.Event{Body: &control_event.LoadPluginEvent{Name: "file"}})
+				s.HandleGomitEvent(gomit.Event{Body: &control_event.UnloadPluginEvent{Name: "mock"}})
+				So(sch.Wait(time.Time{}, stop).Error(), ShouldEqual, schedule.ErrWaitCancelled)
+			})
+			Convey("notifies it of events while its schedule is an event schedule", func() {
+				So(s.tasks.EventTasks(), ShouldHaveLength, 1)
+				_, errs := s.UpdateTask(tsk.ID(), schedule.NewSimpleSchedule(time.Second), nil)
+				So(errs, ShouldBeEmpty)
+				So(s.tasks.EventTasks(), ShouldBeEmpty)
+				upd := schedule.NewEventSchedule([]string{control_event.PluginLoaded}, "")
+				_, errs = s.UpdateTask(tsk.ID(), upd, nil)
+				So(errs, ShouldBeEmpty)
+				So(s.tasks.EventTasks(), ShouldHaveLength, 1)
+				s.HandleGomitEvent(gomit.Event{Body: &control_event.LoadPluginEvent{Name: "file"}})
+				So(upd.Wait(time.Time{}, stop).Error(), ShouldBeNil)
+			})
+		})
 	})
 	Convey("Stop()", t, func() {
 		Convey("Should set scheduler state to SchedulerStopped", func() {
@@ -330,5 +537,96 @@ func TestScheduler(t *testing.T) {
 			So(scheduler.metricManager, ShouldEqual, c)
 		})
 	})
+	Convey("ResizeWorkQueue()", t, func() {
+		scheduler := New(GetDefaultConfig())
+		Convey("Should resize the work queue and its worker pool", func() {
+			st, err := scheduler.ResizeWorkQueue("process", 50, 4)
+			So(err, ShouldBeNil)
+			So(st.Limit, ShouldEqual, 50)
+			So(st.Workers, ShouldEqual, 4)
+			So(scheduler.QueueStats()["process"].Workers, ShouldEqual, 4)
+		})
+		Convey("Should return an error for an unknown work queue", func() {
+			_, err := scheduler.ResizeWorkQueue("foo", 50, 4)
+			So(err.Error(), ShouldContainSubstring, ErrWorkQueueNotFound.Error())
+		})
+		Convey("Should return an error for a worker pool without workers", func() {
+			_, err := scheduler.ResizeWorkQueue("collect", 50, 0)
+			So(err, ShouldEqual, ErrWorkQueueNoWorkers)
+		})
+		Convey("Should resize all the work queues", func() {
+			So(scheduler.ResizeWorkQueues(20, 2), ShouldBeNil)
+			for _, st := range scheduler.QueueStats() {
+				So(st.Limit, ShouldEqual, 20)
+				So(st.Workers, ShouldEqual, 2)
+			}
+		})
+	})
+
+}
 
+func TestSchedulerSpool(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	w := wmap.NewWorkflowMap()
+	w.CollectNode.AddMetric("/foo/bar", 1)
+	pu := wmap.NewPublishNode("file", 1)
+	pu.Retry = &wmap.RetryPolicy{Attempts: 1, Spool: true}
+	w.CollectNode.Add(pu)
+
+	Convey("Given a scheduler without a spool path", t, func() {
+		s := New(GetDefaultConfig())
+		s.SetMetricManager(new(mockMetricManager))
+		So(s.Start(), ShouldBeNil)
+		Convey("Should reject a task whose publish node spools its metrics", func() {
+			_, te := s.CreateTask(schedule.NewSimpleSchedule(time.Second*1), w, false)
+			So(te.Errors(), ShouldNotBeEmpty)
+			So(te.Errors()[0].Error(), ShouldContainSubstring, ErrSpoolDisabled.Error())
+		})
+		Convey("Should return an error when the spool is inspected", func() {
+			_, err := s.GetSpool("")
+			So(err, ShouldEqual, ErrSpoolDisabled)
+			_, err = s.PurgeSpool("")
+			So(err, ShouldEqual, ErrSpoolDisabled)
+		})
+	})
+	Convey("Given a scheduler with a spool path", t, func() {
+		dir, err := ioutil.TempDir("", "snap-spool")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		cfg := GetDefaultConfig()
+		cfg.SpoolPath = dir
+		cfg.SpoolMaxSize = 1024
+		s := New(cfg)
+		s.SetMetricManager(new(mockMetricManager))
+		So(s.Start(), ShouldBeNil)
+		tsk, te := s.CreateTask(schedule.NewSimpleSchedule(time.Second*1), w, false)
+		So(te.Errors(), ShouldBeEmpty)
+		So(s.spool.write(newSpoolRecord(tsk.ID()), []byte("a")), ShouldBeNil)
+		So(s.spool.write(newSpoolRecord("other"), []byte("bb")), ShouldBeNil)
+
+		Convey("Should describe the spool and the entries of a task", func() {
+			st, err := s.GetSpool(tsk.ID())
+			So(err, ShouldBeNil)
+			So(st.Path, ShouldEqual, dir)
+			So(st.MaxSize, ShouldEqual, 1024)
+			So(st.Size, ShouldEqual, 3)
+			So(st.Entries, ShouldHaveLength, 1)
+			So(st.Entries[0].TaskID, ShouldEqual, tsk.ID())
+		})
+		Convey("Should purge the entries of a task", func() {
+			n, err := s.PurgeSpool(tsk.ID())
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 1)
+			st, err := s.GetSpool("")
+			So(err, ShouldBeNil)
+			So(st.Entries, ShouldHaveLength, 1)
+		})
+		Convey("Should purge the entries of a removed task", func() {
+			So(s.RemoveTask(tsk.ID()), ShouldBeNil)
+			st, err := s.GetSpool("")
+			So(err, ShouldBeNil)
+			So(st.Entries, ShouldHaveLength, 1)
+			So(st.Entries[0].TaskID, ShouldEqual, "other")
+		})
+	})
 }
@@ -0,0 +1,314 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+)
+
+const spoolMetaExt = ".meta"
+
+var (
+	// ErrSpoolDisabled - The error message for a spool used while no spool path is set
+	ErrSpoolDisabled = errors.New("The spool is disabled, no spool path is set")
+	// ErrSpoolEntryTooLarge - The error message for a payload larger than the spool
+	ErrSpoolEntryTooLarge = errors.New("Payload is larger than the spool")
+)
+
+// spool holds the payloads of metrics the publish nodes of the tasks failed
+// to publish, until they are replayed once their publisher recovers. Each
+// payload is a file holding the metrics in the content type of the node,
+// described by a metadata file next to it. When the spool is full, the
+// oldest payloads are dropped to make room for the new ones.
+type spool struct {
+	sync.Mutex
+	path    string
+	maxSize int64
+	// lastID is the ID of the most recent entry. The IDs are the creation
+	// times of the entries, so they sort from oldest to newest.
+	lastID int64
+	// replaying holds the keys of the publish nodes whose entries are being
+	// replayed
+	replaying map[string]bool
+}
+
+// spoolRecord is the metadata of an entry of the spool
+type spoolRecord struct {
+	core.SpoolEntry
+	// Target and Config are those of the publish node, which the payload is
+	// replayed with
+	Target string
+	Config *cdata.ConfigDataNode
+}
+
+func newSpool(path string, maxSize int64) (*spool, error) {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, err
+	}
+	return &spool{
+		path:      path,
+		maxSize:   maxSize,
+		replaying: map[string]bool{},
+	}, nil
+}
+
+// write adds a payload to the spool, dropping the oldest entries while the
+// spool is too full to hold it
+func (s *spool) write(rec *spoolRecord, payload []byte) error {
+	size := int64(len(payload))
+	if s.maxSize > 0 && size > s.maxSize {
+		return ErrSpoolEntryTooLarge
+	}
+	s.Lock()
+	defer s.Unlock()
+	recs, err := s.load()
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, r := range recs {
+		total += r.Size
+	}
+	for s.maxSize > 0 && total+size > s.maxSize && len(recs) > 0 {
+		schedulerLogger.WithFields(log.Fields{
+			"_block":   "spool-write",
+			"entry-id": recs[0].ID,
+			"task-id":  recs[0].TaskID,
+		}).Warn("spool is full, dropping its oldest entry")
+		s.remove(recs[0])
+		total -= recs[0].Size
+		recs = recs[1:]
+	}
+
+	id := time.Now().UnixNano()
+	if id <= s.lastID {
+		id = s.lastID + 1
+	}
+	s.lastID = id
+	rec.ID = fmt.Sprintf("%020d", id)
+	rec.Size = size
+	rec.Created = time.Now()
+	if err := ioutil.WriteFile(s.payloadPath(rec), payload, 0644); err != nil {
+		return err
+	}
+	// the metadata is written last, as entries without one are ignored
+	meta, err := json.Marshal(rec)
+	if err != nil {
+		os.Remove(s.payloadPath(rec))
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(s.path, rec.ID+spoolMetaExt), meta, 0644); err != nil {
+		os.Remove(s.payloadPath(rec))
+		return err
+	}
+	return nil
+}
+
+// entries returns the entries of the given task, or of all the tasks if the
+// task ID is empty, oldest first
+func (s *spool) entries(taskID string) ([]*spoolRecord, error) {
+	s.Lock()
+	defer s.Unlock()
+	recs, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	if taskID == "" {
+		return recs, nil
+	}
+	filtered := []*spoolRecord{}
+	for _, r := range recs {
+		if r.TaskID == taskID {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered, nil
+}
+
+// stats describes the spool and the entries of the given task, or of all
+// the tasks if the task ID is empty. The size is that of the whole spool.
+func (s *spool) stats(taskID string) (core.SpoolStats, error) {
+	st := core.SpoolStats{Path: s.path, MaxSize: s.maxSize, Entries: []core.SpoolEntry{}}
+	all, err := s.entries("")
+	if err != nil {
+		return st, err
+	}
+	for _, r := range all {
+		st.Size += r.Size
+		if taskID == "" || r.TaskID == taskID {
+			st.Entries = append(st.Entries, r.SpoolEntry)
+		}
+	}
+	return st, nil
+}
+
+// purge removes the entries of the given task, or of all the tasks if the
+// task ID is empty, and returns the number of entries removed
+func (s *spool) purge(taskID string) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+	recs, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, r := range recs {
+		if taskID == "" || r.TaskID == taskID {
+			s.remove(r)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// delete removes an entry once it was replayed
+func (s *spool) delete(rec *spoolRecord) {
+	s.Lock()
+	defer s.Unlock()
+	s.remove(rec)
+}
+
+// payload returns the payload of an entry
+func (s *spool) payload(rec *spoolRecord) ([]byte, error) {
+	return ioutil.ReadFile(s.payloadPath(rec))
+}
+
+// startReplay marks the entries of a publish node as being replayed. It
+// returns false if they already are.
+func (s *spool) startReplay(key string) bool {
+	s.Lock()
+	defer s.Unlock()
+	if s.replaying[key] {
+		return false
+	}
+	s.replaying[key] = true
+	return true
+}
+
+func (s *spool) endReplay(key string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.replaying, key)
+}
+
+// load reads the metadata of the entries, oldest first. The caller must hold
+// the lock of the spool.
+func (s *spool) load() ([]*spoolRecord, error) {
+	files, err := ioutil.ReadDir(s.path)
+	if err != nil {
+		return nil, err
+	}
+	recs := []*spoolRecord{}
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), spoolMetaExt) {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(s.path, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		rec := &spoolRecord{}
+		if err := json.Unmarshal(b, rec); err != nil {
+			schedulerLogger.WithFields(log.Fields{
+				"_block": "spool-load",
+				"_error": err.Error(),
+				"file":   f.Name(),
+			}).Error("unable to read spool entry")
+			continue
+		}
+		recs = append(recs, rec)
+	}
+	sort.Sort(spoolRecordsByID(recs))
+	return recs, nil
+}
+
+// remove deletes the files of an entry. The caller must hold the lock of the
+// spool.
+func (s *spool) remove(rec *spoolRecord) {
+	os.Remove(filepath.Join(s.path, rec.ID+spoolMetaExt))
+	os.Remove(s.payloadPath(rec))
+}
+
+func (s *spool) payloadPath(rec *spoolRecord) string {
+	return filepath.Join(s.path, rec.ID+"."+rec.ContentType)
+}
+
+type spoolRecordsByID []*spoolRecord
+
+func (s spoolRecordsByID) Len() int           { return len(s) }
+func (s spoolRecordsByID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s spoolRecordsByID) Less(i, j int) bool { return s[i].ID < s[j].ID }
+
+// spoolKey identifies the publish node of a task whose entries are replayed
+// together
+func spoolKey(taskID, name string, version int, target string) string {
+	return fmt.Sprintf("%s"+core.Separator+"%s"+core.Separator+"%d"+core.Separator+"%s", taskID, name, version, target)
+}
+
+// encodeSpoolPayload writes metrics in the given content type, JSON if it is
+// empty, and returns the content type they were written in
+func encodeSpoolPayload(contentType string, mts []core.Metric) ([]byte, string, error) {
+	if contentType == "" {
+		contentType = plugin.SnapJSONContentType
+	}
+	pmts := make([]plugin.MetricType, len(mts))
+	for i, m := range mts {
+		pmts[i] = plugin.MetricType{
+			Namespace_:          m.Namespace(),
+			Tags_:               m.Tags(),
+			Timestamp_:          m.Timestamp(),
+			Version_:            m.Version(),
+			Config_:             m.Config(),
+			LastAdvertisedTime_: m.LastAdvertisedTime(),
+			Unit_:               m.Unit(),
+			Description_:        m.Description(),
+			Data_:               m.Data(),
+		}
+	}
+	return plugin.MarshalMetricTypes(contentType, pmts)
+}
+
+// decodeSpoolPayload reads the metrics of a payload
+func decodeSpoolPayload(contentType string, payload []byte) ([]core.Metric, error) {
+	pmts, err := plugin.UnmarshallMetricTypes(contentType, payload)
+	if err != nil {
+		return nil, err
+	}
+	mts := make([]core.Metric, len(pmts))
+	for i, m := range pmts {
+		mts[i] = m
+	}
+	return mts, nil
+}
@@ -0,0 +1,146 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	log "github.com/Sirupsen/logrus"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+)
+
+func newSpoolRecord(taskID string) *spoolRecord {
+	return &spoolRecord{
+		SpoolEntry: core.SpoolEntry{
+			TaskID:        taskID,
+			PluginName:    "file",
+			PluginVersion: 1,
+			ContentType:   plugin.SnapJSONContentType,
+			MetricCount:   1,
+		},
+	}
+}
+
+func TestSpool(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("Spool", t, func() {
+		dir, err := ioutil.TempDir("", "snap-spool")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		sp, err := newSpool(filepath.Join(dir, "spool"), 10)
+		So(err, ShouldBeNil)
+
+		Convey("holds nothing once created", func() {
+			st, err := sp.stats("")
+			So(err, ShouldBeNil)
+			So(st.Path, ShouldEqual, filepath.Join(dir, "spool"))
+			So(st.MaxSize, ShouldEqual, 10)
+			So(st.Size, ShouldEqual, 0)
+			So(st.Entries, ShouldBeEmpty)
+		})
+		Convey("returns the entries it holds, oldest first", func() {
+			So(sp.write(newSpoolRecord("1"), []byte("aaa")), ShouldBeNil)
+			So(sp.write(newSpoolRecord("2"), []byte("bb")), ShouldBeNil)
+			So(sp.write(newSpoolRecord("1"), []byte("c")), ShouldBeNil)
+			recs, err := sp.entries("")
+			So(err, ShouldBeNil)
+			So(recs, ShouldHaveLength, 3)
+			So(recs[0].ID, ShouldBeLessThan, recs[1].ID)
+			So(recs[1].ID, ShouldBeLessThan, recs[2].ID)
+			b, err := sp.payload(recs[0])
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEqual, "aaa")
+
+			recs, err = sp.entries("1")
+			So(err, ShouldBeNil)
+			So(recs, ShouldHaveLength, 2)
+			st, err := sp.stats("2")
+			So(err, ShouldBeNil)
+			So(st.Size, ShouldEqual, 6)
+			So(st.Entries, ShouldHaveLength, 1)
+			So(st.Entries[0].Size, ShouldEqual, 2)
+		})
+		Convey("drops its oldest entries when full", func() {
+			So(sp.write(newSpoolRecord("1"), []byte("aaaa")), ShouldBeNil)
+			So(sp.write(newSpoolRecord("1"), []byte("bbbb")), ShouldBeNil)
+			So(sp.write(newSpoolRecord("1"), []byte("cccc")), ShouldBeNil)
+			recs, err := sp.entries("")
+			So(err, ShouldBeNil)
+			So(recs, ShouldHaveLength, 2)
+			b, err := sp.payload(recs[0])
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEqual, "bbbb")
+		})
+		Convey("rejects a payload larger than the spool", func() {
+			So(sp.write(newSpoolRecord("1"), []byte("aaaa")), ShouldBeNil)
+			So(sp.write(newSpoolRecord("1"), []byte("0123456789a")), ShouldEqual, ErrSpoolEntryTooLarge)
+			recs, err := sp.entries("")
+			So(err, ShouldBeNil)
+			So(recs, ShouldHaveLength, 1)
+		})
+		Convey("purges the entries of a task", func() {
+			So(sp.write(newSpoolRecord("1"), []byte("a")), ShouldBeNil)
+			So(sp.write(newSpoolRecord("2"), []byte("b")), ShouldBeNil)
+			So(sp.write(newSpoolRecord("1"), []byte("c")), ShouldBeNil)
+			n, err := sp.purge("1")
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 2)
+			recs, err := sp.entries("")
+			So(err, ShouldBeNil)
+			So(recs, ShouldHaveLength, 1)
+			So(recs[0].TaskID, ShouldEqual, "2")
+			files, err := ioutil.ReadDir(sp.path)
+			So(err, ShouldBeNil)
+			So(files, ShouldHaveLength, 2)
+		})
+		Convey("keeps its entries across restarts", func() {
+			So(sp.write(newSpoolRecord("1"), []byte("a")), ShouldBeNil)
+			sp2, err := newSpool(sp.path, 10)
+			So(err, ShouldBeNil)
+			recs, err := sp2.entries("1")
+			So(err, ShouldBeNil)
+			So(recs, ShouldHaveLength, 1)
+		})
+	})
+	Convey("Spooled payloads", t, func() {
+		mts := []core.Metric{
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo"), Tags_: map[string]string{"rack": "r1"}, Data_: 1.5},
+		}
+		Convey("are read back in their content type", func() {
+			b, ct, err := encodeSpoolPayload("", mts)
+			So(err, ShouldBeNil)
+			So(ct, ShouldEqual, plugin.SnapJSONContentType)
+			decoded, err := decodeSpoolPayload(ct, b)
+			So(err, ShouldBeNil)
+			So(decoded, ShouldHaveLength, 1)
+			So(decoded[0].Namespace().String(), ShouldEqual, "/intel/mock/foo")
+			So(decoded[0].Tags(), ShouldResemble, map[string]string{"rack": "r1"})
+			So(decoded[0].Data(), ShouldEqual, 1.5)
+		})
+	})
+}
@@ -33,6 +33,7 @@ import (
 
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/scheduler_event"
+	"github.com/intelsdi-x/snap/core/serror"
 	"github.com/intelsdi-x/snap/grpc/controlproxy"
 	"github.com/intelsdi-x/snap/pkg/schedule"
 	"github.com/intelsdi-x/snap/scheduler/wmap"
@@ -58,6 +59,12 @@ var (
 	ErrTaskDisabledOnFailures = errors.New("Task disabled due to consecutive failures")
 	// ErrTaskNotDisabled - The error message for task must be disabled
 	ErrTaskNotDisabled = errors.New("Task must be disabled")
+	// ErrTaskNotRunning - The error message for a task fired while it is not running, without force
+	ErrTaskNotRunning = errors.New("Task is not running. Force must be set to fire it.")
+	// ErrTaskDisabledNotFireable - The error message for a disabled task which cannot be fired
+	ErrTaskDisabledNotFireable = errors.New("Task is disabled. Cannot be fired.")
+	// ErrTaskBusy - The error message for a task fired while its overlap policy allows no more runs
+	ErrTaskBusy = errors.New("Task is busy. Cannot be fired.")
 )
 
 type task struct {
@@ -65,8 +72,8 @@ type task struct {
 
 	id                 string
 	name               string
-	schResponseChan    chan schedule.Response
 	killChan           chan struct{}
+	rescheduleChan     chan struct{}
 	schedule           schedule.Schedule
 	workflow           *schedulerWorkflow
 	state              core.TaskState
@@ -79,14 +86,53 @@ type task struct {
 	missedIntervals    uint
 	failureMutex       sync.Mutex
 	failedRuns         uint
+	overflows          uint
+	deadlineExceeded   uint
 	lastFailureMessage string
 	lastFailureTime    time.Time
 	stopOnFailure      int
-	eventEmitter       gomit.Emitter
-	RemoteManagers     managers
+	collectPolicy      core.CollectPolicy
+	failurePolicy      core.FailurePolicy
+	// backoffLevel is the number of times the interval of a backing off task
+	// was doubled, and backoffSkips the number of fires it still skips
+	backoffLevel  uint
+	backoffSkips  uint
+	catchupPolicy core.CatchupPolicy
+	// overlapPolicy determines what the task does when its schedule fires
+	// while its workflow is running
+	overlapPolicy  core.OverlapPolicy
+	priority       core.TaskPriority
+	eventEmitter   gomit.Emitter
+	RemoteManagers managers
+	// persistent is set for tasks recorded in the scheduler's task store
+	persistent bool
+	history    *taskHistory
+	// latencies holds the latency histograms of the task, and latencySLO
+	// the duration its runs are expected to complete within
+	latencies  *taskLatencies
+	latencySLO time.Duration
+	// runs is the number of runs of the workflow in progress, queued is set
+	// while a fire held by the queue-one overlap policy waits for them, and
+	// busySkips counts the fires skipped because the task was busy
+	runs       uint
+	queued     bool
+	queuedFire time.Time
+	busySkips  uint
+	// workflowMutex is held for reading by the runs in progress, and for
+	// writing while the workflow of the task is swapped
+	workflowMutex sync.RWMutex
+	// spool holds the metrics the publish nodes failed to publish, nil if
+	// the spool of the scheduler is disabled, and replays tracks the replays
+	// of the spool in progress
+	spool   *spool
+	replays sync.WaitGroup
+	// spinDone is closed once the task stops spinning, and the runs counted
+	// in runs are sent on runsDone, nil once the task winds down
+	spinDone chan struct{}
+	runsDone chan core.TaskRun
 }
 
-//NewTask creates a Task
+// NewTask creates a Task
 func newTask(s schedule.Schedule, wf *schedulerWorkflow, m *workManager, mm managesMetrics, emitter gomit.Emitter, opts ...core.TaskOption) (*task, error) {
 
 	//Task would always be given a default name.
@@ -104,7 +150,7 @@ func newTask(s schedule.Schedule, wf *schedulerWorkflow, m *workManager, mm mana
 	task := &task{
 		id:               taskID,
 		name:             name,
-		schResponseChan:  make(chan schedule.Response),
+		rescheduleChan:   make(chan struct{}, 1),
 		schedule:         s,
 		state:            core.TaskStopped,
 		creationTime:     time.Now(),
@@ -115,6 +161,8 @@ func newTask(s schedule.Schedule, wf *schedulerWorkflow, m *workManager, mm mana
 		stopOnFailure:    DefaultStopOnFailure,
 		eventEmitter:     emitter,
 		RemoteManagers:   mgrs,
+		history:          newTaskHistory(defaultTaskHistorySize),
+		latencies:        newTaskLatencies(),
 	}
 	//set options
 	for _, opt := range opts {
@@ -133,7 +181,7 @@ func (t *task) Option(opts ...core.TaskOption) core.TaskOption {
 	return previous
 }
 
-//Returns the name of the task
+// Returns the name of the task
 func (t *task) GetName() string {
 	return t.name
 }
@@ -181,11 +229,37 @@ func (t *task) MissedCount() uint {
 
 // FailedRuns returns the number of intervals missed.
 func (t *task) FailedCount() uint {
+	t.failureMutex.Lock()
+	defer t.failureMutex.Unlock()
 	return t.failedRuns
 }
 
+// OverflowCount returns the number of runs which failed because a queue of
+// the work manager was full.
+func (t *task) OverflowCount() uint {
+	t.failureMutex.Lock()
+	defer t.failureMutex.Unlock()
+	return t.overflows
+}
+
+// DeadlineExceededCount returns the number of runs which failed because a
+// call to a plugin was cancelled once the deadline of its job passed.
+func (t *task) DeadlineExceededCount() uint {
+	t.failureMutex.Lock()
+	defer t.failureMutex.Unlock()
+	return t.deadlineExceeded
+}
+
+// BusySkipCount returns the number of fires of the schedule skipped because
+// the workflow of the task was still running.
+func (t *task) BusySkipCount() uint {
+	return t.busySkips
+}
+
 // LastFailureMessage returns the last error from a task run
 func (t *task) LastFailureMessage() string {
+	t.failureMutex.Lock()
+	defer t.failureMutex.Unlock()
 	return t.lastFailureMessage
 }
 
@@ -211,6 +285,89 @@ func (t *task) GetStopOnFailure() int {
 	return t.stopOnFailure
 }
 
+// History returns the records of the most recent runs of the task, oldest
+// first.
+func (t *task) History() []core.TaskRun {
+	return t.history.Runs()
+}
+
+func (t *task) SetCollectPolicy(v core.CollectPolicy) {
+	t.collectPolicy = v
+}
+
+func (t *task) GetCollectPolicy() core.CollectPolicy {
+	return t.collectPolicy
+}
+
+func (t *task) SetFailurePolicy(v core.FailurePolicy) {
+	t.failurePolicy = v
+}
+
+func (t *task) GetFailurePolicy() core.FailurePolicy {
+	return t.failurePolicy
+}
+
+func (t *task) SetCatchupPolicy(v core.CatchupPolicy) {
+	t.catchupPolicy = v
+}
+
+func (t *task) GetCatchupPolicy() core.CatchupPolicy {
+	return t.catchupPolicy
+}
+
+func (t *task) SetOverlapPolicy(v core.OverlapPolicy) {
+	t.overlapPolicy = v
+}
+
+func (t *task) GetOverlapPolicy() core.OverlapPolicy {
+	return t.overlapPolicy
+}
+
+func (t *task) SetLatencySLO(v time.Duration) {
+	t.latencySLO = v
+}
+
+// LatencySLO returns the duration the runs of the task are expected to
+// complete within, 0 if the task has no latency objective.
+func (t *task) LatencySLO() time.Duration {
+	return t.latencySLO
+}
+
+// SLOBreachCount returns the number of runs of the task which took longer
+// than its latency objective.
+func (t *task) SLOBreachCount() uint {
+	return t.latencies.breaches()
+}
+
+// LastRunDuration returns the time taken by the most recent run of the
+// task, 0 if it has not run yet.
+func (t *task) LastRunDuration() time.Duration {
+	if t.latencies == nil {
+		return 0
+	}
+	return t.latencies.lastRunDuration()
+}
+
+// Latencies returns the latency distributions of the runs of the task and of
+// the jobs run for each node of its workflow.
+func (t *task) Latencies() core.TaskLatencies {
+	return t.latencies.snapshot(t.latencySLO)
+}
+
+func (t *task) SetPriority(v core.TaskPriority) {
+	t.priority = v
+}
+
+func (t *task) GetPriority() core.TaskPriority {
+	return t.priority
+}
+
+// BackoffLevel returns the number of times the interval of a backing off
+// task was doubled, 0 if it is not backing off.
+func (t *task) BackoffLevel() uint {
+	return t.backoffLevel
+}
+
 // Spin will start a task spinning in its own routine while it waits for its
 // schedule.
 func (t *task) Spin() {
@@ -224,10 +381,13 @@ func (t *task) Spin() {
 	// misses for the interval while stopped.
 	t.lastFireTime = time.Now()
 	if t.state == core.TaskStopped {
+		t.resetBackoff()
 		t.state = core.TaskSpinning
 		t.killChan = make(chan struct{})
+		t.spinDone = make(chan struct{})
+		t.runsDone = make(chan core.TaskRun)
 		// spin in a goroutine
-		go t.spin()
+		go t.spin(t.spinDone, t.runsDone)
 	}
 }
 
@@ -240,7 +400,7 @@ func (t *task) Stop() {
 	}
 }
 
-//Enable changes the state from Disabled to Stopped
+// Enable changes the state from Disabled to Stopped
 func (t *task) Enable() error {
 	t.Lock()
 	defer t.Unlock()
@@ -262,83 +422,184 @@ func (t *task) Kill() {
 	}
 }
 
+// awaitStop waits for a stopping task to complete its runs in progress,
+// publish the batches of its workflow and give up replaying its spool
+func (t *task) awaitStop() {
+	t.Lock()
+	spinDone := t.spinDone
+	t.Unlock()
+	if spinDone != nil {
+		<-spinDone
+	}
+	t.replays.Wait()
+}
+
+// stopChan returns a channel closed once the task is stopped, nil for a task
+// which is not spinning
+func (t *task) stopChan() <-chan struct{} {
+	t.Lock()
+	defer t.Unlock()
+	switch t.state {
+	case core.TaskStopped, core.TaskEnded:
+		return nil
+	}
+	return t.killChan
+}
+
 func (t *task) WMap() *wmap.WorkflowMap {
 	return t.workflow.workflowMap
 }
 
+// Schedule returns the schedule of the task, which is replaced when the task
+// is updated
 func (t *task) Schedule() schedule.Schedule {
+	t.Lock()
+	defer t.Unlock()
 	return t.schedule
 }
 
-func (t *task) spin() {
+// spin waits on the schedule of the task and runs its workflow until the
+// task stops, then closes spinDone. The runs of the workflow are sent on done
+// once they complete, so the schedule is waited on while they are in progress.
+func (t *task) spin(spinDone chan struct{}, done chan core.TaskRun) {
+	defer close(spinDone)
 	var consecutiveFailures int
+	var waitStop chan struct{}
+	var schResponseChan chan schedule.Response
+	var last time.Time
 	for {
 		taskLogger.Debug("task spin loop")
-		// Start go routine to wait on schedule
-		go t.waitForSchedule()
+		if schResponseChan == nil {
+			// Start go routine to wait on schedule. Closing waitStop releases
+			// the waiter, which is awaited before waiting again; its response
+			// is then dropped.
+			waitStop = make(chan struct{})
+			schResponseChan = make(chan schedule.Response, 1)
+			t.Lock()
+			last = t.lastFireTime
+			go waitForSchedule(t.schedule, last, waitStop, schResponseChan)
+			t.Unlock()
+		}
 		// wait here on
 		//  schResponseChan - response from schedule
+		//  done - a run of the workflow completed
+		//  rescheduleChan - signals the schedule of the task was replaced
 		//  killChan - signals task needs to be stopped
 		select {
-		case sr := <-t.schResponseChan:
+		case sr := <-schResponseChan:
+			schResponseChan = nil
 			switch sr.State() {
 			// If response show this schedule is stil active we fire
 			case schedule.Active:
 				t.missedIntervals += sr.Missed()
-				t.lastFireTime = time.Now()
-				t.hitCount++
-				t.fire()
-				if t.lastFailureTime == t.lastFireTime {
-					consecutiveFailures++
-					taskLogger.WithFields(log.Fields{
-						"_block":                    "spin",
-						"task-id":                   t.id,
-						"task-name":                 t.name,
-						"consecutive failures":      consecutiveFailures,
-						"consecutive failure limit": t.stopOnFailure,
-						"error":                     t.lastFailureMessage,
-					}).Warn("Task failed")
-				} else {
-					consecutiveFailures = 0
+				if t.skipBackoffFire() {
+					break
 				}
-				if t.stopOnFailure >= 0 && consecutiveFailures >= t.stopOnFailure {
-					taskLogger.WithFields(log.Fields{
-						"_block":               "spin",
-						"task-id":              t.id,
-						"task-name":            t.name,
-						"consecutive failures": consecutiveFailures,
-						"error":                t.lastFailureMessage,
-					}).Error(ErrTaskDisabledOnFailures)
-					// You must lock on state change for tasks
-					t.Lock()
-					t.state = core.TaskDisabled
-					t.Unlock()
-					// Send task disabled event
-					event := new(scheduler_event.TaskDisabledEvent)
-					event.TaskID = t.id
-					event.Why = fmt.Sprintf("Task disabled with error: %s", t.lastFailureMessage)
-					defer t.eventEmitter.Emit(event)
-					return
+				t.Lock()
+				t.lastFireTime = time.Now()
+				fireTime := t.lastFireTime
+				t.Unlock()
+				if t.overlaps() {
+					break
 				}
+				t.startRun(fireTime, last, sr.Missed(), done)
 
 			// Schedule has ended
 			case schedule.Ended:
+				t.awaitRuns(done)
+				t.flushBatches()
 				// You must lock task to change state
 				t.Lock()
 				t.state = core.TaskEnded
 				t.Unlock()
+				// Send task ended event
+				event := new(scheduler_event.TaskEndedEvent)
+				event.TaskID = t.id
+				defer t.eventEmitter.Emit(event)
 				return //spin
 
 			// Schedule has errored
 			case schedule.Error:
+				t.awaitRuns(done)
+				t.flushBatches()
 				// You must lock task to change state
 				t.Lock()
 				t.state = core.TaskDisabled
 				t.Unlock()
+				// Send task disabled event
+				event := new(scheduler_event.TaskDisabledEvent)
+				event.TaskID = t.id
+				event.Why = fmt.Sprintf("Task disabled with schedule error: %v", sr.Error())
+				defer t.eventEmitter.Emit(event)
 				return //spin
 
 			}
+		case run := <-done:
+			t.runDone()
+			if run.Failed {
+				consecutiveFailures++
+				taskLogger.WithFields(log.Fields{
+					"_block":                    "spin",
+					"task-id":                   t.id,
+					"task-name":                 t.name,
+					"consecutive failures":      consecutiveFailures,
+					"consecutive failure limit": t.stopOnFailure,
+					"error":                     t.LastFailureMessage(),
+				}).Warn("Task failed")
+			} else {
+				consecutiveFailures = 0
+				t.resetBackoff()
+			}
+			if t.stopOnFailure >= 0 && consecutiveFailures >= t.stopOnFailure && t.backsOff(consecutiveFailures) {
+				t.backOff()
+				taskLogger.WithFields(log.Fields{
+					"_block":               "spin",
+					"task-id":              t.id,
+					"task-name":            t.name,
+					"consecutive failures": consecutiveFailures,
+					"backoff-level":        t.backoffLevel,
+					"skipped-fires":        t.backoffSkips,
+				}).Warn("Task backing off")
+			} else if t.stopOnFailure >= 0 && consecutiveFailures >= t.stopOnFailure {
+				taskLogger.WithFields(log.Fields{
+					"_block":               "spin",
+					"task-id":              t.id,
+					"task-name":            t.name,
+					"consecutive failures": consecutiveFailures,
+					"error":                t.LastFailureMessage(),
+				}).Error(ErrTaskDisabledOnFailures)
+				if schResponseChan != nil {
+					cancelWait(waitStop, schResponseChan)
+				}
+				t.awaitRuns(done)
+				t.flushBatches()
+				// You must lock on state change for tasks
+				t.Lock()
+				t.state = core.TaskDisabled
+				t.Unlock()
+				// Send task disabled event
+				event := new(scheduler_event.TaskDisabledEvent)
+				event.TaskID = t.id
+				event.Why = fmt.Sprintf("Task disabled with error: %s", t.LastFailureMessage())
+				defer t.eventEmitter.Emit(event)
+				return
+			}
+			// run the fire held while the task was busy
+			if fireTime, ok := t.dequeueFire(); ok {
+				t.startRun(fireTime, time.Time{}, 0, done)
+			}
+		case <-t.rescheduleChan:
+			// Wait on the new schedule instead
+			if schResponseChan != nil {
+				cancelWait(waitStop, schResponseChan)
+			}
+			schResponseChan = nil
 		case <-t.killChan:
+			if schResponseChan != nil {
+				cancelWait(waitStop, schResponseChan)
+			}
+			t.awaitRuns(done)
+			t.flushBatches()
 			// Only here can it truly be stopped
 			t.Lock()
 			t.state = core.TaskStopped
@@ -349,44 +610,318 @@ func (t *task) spin() {
 	}
 }
 
-func (t *task) fire() {
+// overlaps returns whether a fire of the schedule overlaps the runs of the
+// workflow in progress, in which case it is held or skipped as the overlap
+// policy of the task determines. Otherwise the run of the fire is counted.
+func (t *task) overlaps() bool {
 	t.Lock()
 	defer t.Unlock()
+	if t.runs < t.overlapPolicy.MaxRuns() {
+		t.addRun()
+		return false
+	}
+	mode := t.overlapPolicy.Mode
+	if (mode == "" || mode == core.OverlapQueueOne) && !t.queued {
+		t.queued = true
+		t.queuedFire = t.lastFireTime
+		return true
+	}
+	t.busySkips++
+	taskLogger.WithFields(log.Fields{
+		"_block":         "spin",
+		"task-id":        t.id,
+		"task-name":      t.name,
+		"runs":           t.runs,
+		"overlap-policy": mode,
+	}).Debug("Task busy, skipping fire")
+	return true
+}
+
+// dequeueFire returns the time of the fire held by the queue-one overlap
+// policy once it no longer overlaps the runs in progress, and counts its run.
+func (t *task) dequeueFire() (time.Time, bool) {
+	t.Lock()
+	defer t.Unlock()
+	if !t.queued || t.runs >= t.overlapPolicy.MaxRuns() {
+		return time.Time{}, false
+	}
+	t.queued = false
+	t.addRun()
+	return t.queuedFire, true
+}
 
-	t.state = core.TaskFiring
-	t.workflow.Start(t)
-	t.state = core.TaskSpinning
+// addRun counts a run of the workflow about to start. The task must be
+// locked.
+func (t *task) addRun() {
+	t.runs++
+	t.hitCount++
+	if t.state == core.TaskSpinning {
+		t.state = core.TaskFiring
+	}
 }
 
-func (t *task) waitForSchedule() {
-	select {
-	case <-t.killChan:
+// startRun runs the workflow of the task in its own goroutine for the fire
+// at the given time, after catching up the intervals missed since last, and
+// sends the record of the run on done once it completes. The run must have
+// been counted.
+func (t *task) startRun(fireTime, last time.Time, missed uint, done chan<- core.TaskRun) {
+	go func() {
+		t.workflowMutex.RLock()
+		t.catchUp(last, missed)
+		run := t.workflow.start(t, newTaskRun(fireTime))
+		t.workflowMutex.RUnlock()
+		done <- run
+	}()
+}
+
+// runDone records the completion of a run of the workflow
+func (t *task) runDone() {
+	t.Lock()
+	defer t.Unlock()
+	t.runs--
+	if t.runs == 0 && t.state == core.TaskFiring {
+		t.state = core.TaskSpinning
+	}
+}
+
+// awaitRuns waits for the runs of the workflow in progress to complete and
+// drops the fire held by the queue-one overlap policy. The runs fired on
+// demand from then on are no longer counted.
+func (t *task) awaitRuns(done <-chan core.TaskRun) {
+	t.Lock()
+	t.queued = false
+	t.runsDone = nil
+	runs := t.runs
+	t.Unlock()
+	for ; runs > 0; runs-- {
+		<-done
+		t.runDone()
+	}
+}
+
+// catchUp runs the workflow of the task for the intervals it missed since
+// last, as many of the most recent ones as its catch-up policy allows, oldest
+// first. It stops early if the task is stopped.
+func (t *task) catchUp(last time.Time, missed uint) {
+	n := t.catchupPolicy.Runs(missed)
+	if n == 0 {
 		return
-	case t.schResponseChan <- t.schedule.Wait(t.lastFireTime):
+	}
+	t.Lock()
+	sch, ok := t.schedule.(schedule.IntervalSchedule)
+	t.Unlock()
+	if !ok {
+		return
+	}
+	times := sch.MissedTimes(last, missed)
+	if uint(len(times)) < n {
+		n = uint(len(times))
+	}
+	for _, due := range times[uint(len(times))-n:] {
+		select {
+		case <-t.killChan:
+			return
+		default:
+		}
+		taskLogger.WithFields(log.Fields{
+			"_block":    "catch-up",
+			"task-id":   t.id,
+			"task-name": t.name,
+			"due-time":  due,
+		}).Debug("Catching up missed interval")
+		t.Lock()
+		t.hitCount++
+		t.Unlock()
+		t.workflow.start(t, newCatchupRun(due))
+	}
+}
+
+// fireOnDemand runs the workflow of the task once, out of its schedule, and
+// returns the record of the run. The run counts as a hit of the task. The run
+// of a spinning task is counted with the runs of its schedule, so it is
+// refused when the overlap policy of the task would hold or skip a fire. A
+// task which is not running is only fired if force is set, a stopped task
+// having its dependencies subscribed for the run only.
+func (t *task) fireOnDemand(force bool) (core.TaskRun, []serror.SnapError) {
+	t.Lock()
+	done := t.runsDone
+	switch {
+	case t.state == core.TaskDisabled:
+		t.Unlock()
+		return core.TaskRun{}, []serror.SnapError{serror.New(ErrTaskDisabledNotFireable)}
+	case done != nil:
+		if t.runs >= t.overlapPolicy.MaxRuns() {
+			t.busySkips++
+			t.Unlock()
+			return core.TaskRun{}, []serror.SnapError{serror.New(ErrTaskBusy)}
+		}
+		t.addRun()
+	case !force:
+		t.Unlock()
+		return core.TaskRun{}, []serror.SnapError{serror.New(ErrTaskNotRunning)}
+	}
+	// the dependencies of an ended task are still subscribed
+	subscribe := done == nil && t.state != core.TaskEnded
+	t.Unlock()
+
+	t.workflowMutex.RLock()
+	defer t.workflowMutex.RUnlock()
+	if subscribe {
+		depGroups := getWorkflowPlugins(t.workflow.processNodes, t.workflow.publishNodes, t.workflow.metrics)
+		if errs := subscribeDepGroups(t.id, depGroups, t.RemoteManagers, t.workflow.configTree); len(errs) > 0 {
+			return core.TaskRun{}, errs
+		}
+		defer unsubscribeDepGroups(t.id, depGroups, t.RemoteManagers)
+	}
+
+	t.Lock()
+	t.lastFireTime = time.Now()
+	fireTime := t.lastFireTime
+	if done == nil {
+		t.hitCount++
+	}
+	t.Unlock()
+	run := t.workflow.start(t, newTaskRun(fireTime))
+	if done != nil {
+		done <- run
+	}
+	return run, nil
+}
+
+// backsOff returns whether the failure policy of the task makes it back off
+// rather than be disabled after the given number of consecutive failures.
+func (t *task) backsOff(consecutiveFailures int) bool {
+	if !t.failurePolicy.Backoff {
+		return false
+	}
+	return t.failurePolicy.DisableAfter <= 0 || consecutiveFailures < t.failurePolicy.DisableAfter
+}
+
+// backOff doubles the interval between the fires of the task, up to the
+// maximum factor of its failure policy, by skipping fires of its schedule.
+func (t *task) backOff() {
+	if uint(1)<<(t.backoffLevel+1) <= t.failurePolicy.MaxBackoffFactor() {
+		t.backoffLevel++
+	}
+	t.backoffSkips = uint(1)<<t.backoffLevel - 1
+}
+
+// resetBackoff restores the interval between the fires of the task
+func (t *task) resetBackoff() {
+	t.backoffLevel = 0
+	t.backoffSkips = 0
+}
+
+// skipBackoffFire returns whether a fire of the schedule is skipped because
+// the task is backing off.
+func (t *task) skipBackoffFire() bool {
+	if t.backoffSkips == 0 {
+		return false
+	}
+	t.backoffSkips--
+	return true
+}
+
+// waitForSchedule waits on a schedule until it fires or stop is closed, and
+// sends the response of the schedule on the given (buffered) channel.
+func waitForSchedule(sch schedule.Schedule, last time.Time, stop <-chan struct{}, resp chan<- schedule.Response) {
+	resp <- sch.Wait(last, stop)
+}
+
+// cancelWait releases a waiter on a schedule and waits for it to return, so
+// the next wait on the schedule does not run alongside it
+func cancelWait(stop chan struct{}, resp <-chan schedule.Response) {
+	close(stop)
+	<-resp
+}
+
+// reschedule signals a spinning task that its schedule was replaced, so that
+// it stops waiting on the previous one.
+func (t *task) reschedule() {
+	select {
+	case t.rescheduleChan <- struct{}{}:
+	default:
 	}
 }
 
 // RecordFailure updates the failed runs and last failure properties
 func (t *task) RecordFailure(e []error) {
+	t.Lock()
+	fireTime := t.lastFireTime
+	t.Unlock()
 	// We synchronize this update to ensure it is atomic
 	t.failureMutex.Lock()
 	defer t.failureMutex.Unlock()
 	t.failedRuns++
-	t.lastFailureTime = t.lastFireTime
+	t.lastFailureTime = fireTime
 	t.lastFailureMessage = e[len(e)-1].Error()
+	for _, err := range e {
+		if _, ok := err.(*queuingError); ok {
+			t.overflows++
+			break
+		}
+	}
+	for _, err := range e {
+		if core.IsDeadlineExceeded(err) {
+			t.deadlineExceeded++
+			break
+		}
+	}
+}
+
+// recordFailure records the failure of a job in the task and in the record of
+// the run it belongs to, if any
+func (t *task) recordFailure(run *taskRun, e []error) {
+	t.RecordFailure(e)
+	if run != nil {
+		run.setFailed()
+	}
+}
+
+// recordJob records the latency of a job and adds the job to the record of
+// the run it belongs to, if any
+func (t *task) recordJob(run *taskRun, jtype, name string, version int, submitted time.Time, metricCount int, errs []error) {
+	if t.latencies != nil {
+		t.latencies.observeJob(jtype, name, version, time.Since(submitted))
+	}
+	if run != nil {
+		run.addJob(jtype, name, version, submitted, metricCount, errs)
+	}
+}
+
+// recordRun adds a completed run of the task to its history and returns it
+func (t *task) recordRun(run *taskRun) core.TaskRun {
+	r := run.done()
+	if t.history != nil {
+		t.history.add(r)
+	}
+	return r
+}
+
+// recordLatency records the duration of a completed run of the task and
+// returns whether the run breached the latency objective of the task.
+func (t *task) recordLatency(r core.TaskRun) bool {
+	if t.latencies == nil {
+		return false
+	}
+	return t.latencies.observeRun(r.Duration, t.latencySLO)
 }
 
 type taskCollection struct {
 	*sync.Mutex
 
 	table map[string]*task
+	// eventTable indexes the tasks with an event schedule, which are
+	// notified of every event of snapd
+	eventTable map[string]*task
 }
 
 func newTaskCollection() *taskCollection {
 	return &taskCollection{
 		Mutex: &sync.Mutex{},
 
-		table: make(map[string]*task),
+		table:      make(map[string]*task),
+		eventTable: make(map[string]*task),
 	}
 }
 
@@ -410,6 +945,7 @@ func (t *taskCollection) add(task *task) error {
 	if _, ok := t.table[task.id]; !ok {
 		//If we don't already have this task in the collection save it
 		t.table[task.id] = task
+		t.indexSchedule(task)
 	} else {
 		taskLogger.WithFields(log.Fields{
 			"_module": "scheduler-taskCollection",
@@ -436,6 +972,7 @@ func (t *taskCollection) remove(task *task) error {
 			return ErrTaskNotStopped
 		}
 		delete(t.table, task.id)
+		delete(t.eventTable, task.id)
 	} else {
 		taskLogger.WithFields(log.Fields{
 			"_block":  "remove",
@@ -457,6 +994,37 @@ func (t *taskCollection) Table() map[string]*task {
 	return tasks
 }
 
+// reindex updates the index of the tasks with an event schedule once the
+// schedule of a task is replaced
+func (t *taskCollection) reindex(task *task) {
+	t.Lock()
+	defer t.Unlock()
+	if _, ok := t.table[task.id]; ok {
+		t.indexSchedule(task)
+	}
+}
+
+// indexSchedule adds the task to the index of the tasks with an event
+// schedule or removes it from the index. The collection must be locked.
+func (t *taskCollection) indexSchedule(task *task) {
+	if _, ok := task.Schedule().(*schedule.EventSchedule); ok {
+		t.eventTable[task.id] = task
+		return
+	}
+	delete(t.eventTable, task.id)
+}
+
+// EventTasks returns the tasks with an event schedule
+func (t *taskCollection) EventTasks() []*task {
+	t.Lock()
+	defer t.Unlock()
+	tasks := make([]*task, 0, len(t.eventTable))
+	for _, t := range t.eventTable {
+		tasks = append(tasks, t)
+	}
+	return tasks
+}
+
 // createTaskClients walks the workflowmap and creates clients for this task
 // remoteManagers so that nodes that require proxy request can make them.
 func createTaskClients(mgrs *managers, wf *schedulerWorkflow) error {
@@ -0,0 +1,131 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
+)
+
+// taskHistory is a ring buffer holding the records of the most recent runs
+// of a task.
+type taskHistory struct {
+	sync.Mutex
+	runs []core.TaskRun
+	// next is the index the next run is recorded at
+	next int
+	// full is set once the buffer has wrapped around
+	full bool
+}
+
+// newTaskHistory returns a history keeping the given number of runs. A size
+// of 0 keeps no history.
+func newTaskHistory(size uint) *taskHistory {
+	return &taskHistory{
+		runs: make([]core.TaskRun, size),
+	}
+}
+
+// add records a run, replacing the oldest run once the history is full.
+func (h *taskHistory) add(r core.TaskRun) {
+	h.Lock()
+	defer h.Unlock()
+	if len(h.runs) == 0 {
+		return
+	}
+	h.runs[h.next] = r
+	h.next++
+	if h.next == len(h.runs) {
+		h.next = 0
+		h.full = true
+	}
+}
+
+// Runs returns the recorded runs, oldest first.
+func (h *taskHistory) Runs() []core.TaskRun {
+	h.Lock()
+	defer h.Unlock()
+	if !h.full {
+		return append([]core.TaskRun{}, h.runs[:h.next]...)
+	}
+	runs := make([]core.TaskRun, 0, len(h.runs))
+	runs = append(runs, h.runs[h.next:]...)
+	return append(runs, h.runs[:h.next]...)
+}
+
+// taskRun collects the record of a run while the workflow of a task is
+// running. Jobs may complete concurrently.
+type taskRun struct {
+	sync.Mutex
+	start time.Time
+	run   core.TaskRun
+}
+
+func newTaskRun(fireTime time.Time) *taskRun {
+	return &taskRun{
+		start: time.Now(),
+		run: core.TaskRun{
+			FireTime: fireTime,
+			Jobs:     []core.TaskRunJob{},
+		},
+	}
+}
+
+// newCatchupRun returns the run catching up the interval due at the given
+// time.
+func newCatchupRun(dueTime time.Time) *taskRun {
+	r := newTaskRun(dueTime)
+	r.run.CatchUp = true
+	return r
+}
+
+// addJob records the job run for a node of the workflow, given the time it
+// was submitted.
+func (r *taskRun) addJob(jtype, name string, version int, submitted time.Time, metricCount int, errs []error) {
+	j := core.TaskRunJob{
+		Type:          jtype,
+		PluginName:    name,
+		PluginVersion: version,
+		Duration:      time.Since(submitted),
+		MetricCount:   metricCount,
+	}
+	for _, e := range errs {
+		j.Errors = append(j.Errors, e.Error())
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.run.Jobs = append(r.run.Jobs, j)
+}
+
+func (r *taskRun) setFailed() {
+	r.Lock()
+	defer r.Unlock()
+	r.run.Failed = true
+}
+
+// done returns the record of the completed run.
+func (r *taskRun) done() core.TaskRun {
+	r.Lock()
+	defer r.Unlock()
+	r.run.Duration = time.Since(r.start)
+	return r.run
+}
@@ -0,0 +1,84 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/core"
+)
+
+func TestTaskHistory(t *testing.T) {
+	Convey("Task history", t, func() {
+		start := time.Now()
+		runAt := func(i int) core.TaskRun {
+			return core.TaskRun{FireTime: start.Add(time.Duration(i) * time.Second)}
+		}
+
+		Convey("returns the runs recorded, oldest first", func() {
+			h := newTaskHistory(3)
+			So(h.Runs(), ShouldBeEmpty)
+			h.add(runAt(0))
+			h.add(runAt(1))
+			runs := h.Runs()
+			So(len(runs), ShouldEqual, 2)
+			So(runs[0].FireTime, ShouldResemble, runAt(0).FireTime)
+			So(runs[1].FireTime, ShouldResemble, runAt(1).FireTime)
+		})
+		Convey("keeps only the most recent runs", func() {
+			h := newTaskHistory(3)
+			for i := 0; i < 5; i++ {
+				h.add(runAt(i))
+			}
+			runs := h.Runs()
+			So(len(runs), ShouldEqual, 3)
+			for i, r := range runs {
+				So(r.FireTime, ShouldResemble, runAt(i+2).FireTime)
+			}
+		})
+		Convey("keeps nothing with a size of 0", func() {
+			h := newTaskHistory(0)
+			h.add(runAt(0))
+			So(h.Runs(), ShouldBeEmpty)
+		})
+	})
+	Convey("Task run", t, func() {
+		r := newTaskRun(time.Now())
+		submitted := time.Now()
+		r.addJob("collector", "", 0, submitted, 3, nil)
+		r.addJob("publisher", "file", 2, submitted, 3, []error{errors.New("publish error")})
+		r.setFailed()
+		run := r.done()
+		So(run.Failed, ShouldBeTrue)
+		So(len(run.Jobs), ShouldEqual, 2)
+		So(run.Jobs[0].Type, ShouldEqual, "collector")
+		So(run.Jobs[0].MetricCount, ShouldEqual, 3)
+		So(run.Jobs[0].Errors, ShouldBeEmpty)
+		So(run.Jobs[1].PluginName, ShouldEqual, "file")
+		So(run.Jobs[1].PluginVersion, ShouldEqual, 2)
+		So(run.Jobs[1].Errors, ShouldResemble, []string{"publish error"})
+	})
+}
@@ -0,0 +1,125 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
+)
+
+// taskLatencies holds the latency histograms of the runs of a task and of the
+// jobs run for each node of its workflow. The histograms of the nodes are kept
+// when the workflow of the task is updated, so the latencies of a swapped
+// plugin can be compared to those of its replacement.
+type taskLatencies struct {
+	sync.Mutex
+	workflow *core.LatencyHistogram
+	// jobs is keyed by {type}:{plugin_name}:{plugin_version}
+	jobs        map[string]*jobLatency
+	sloBreaches uint
+	// lastRun is the duration of the most recent run
+	lastRun time.Duration
+}
+
+type jobLatency struct {
+	jtype     string
+	name      string
+	version   int
+	histogram *core.LatencyHistogram
+}
+
+func newTaskLatencies() *taskLatencies {
+	return &taskLatencies{
+		workflow: core.NewLatencyHistogram(),
+		jobs:     map[string]*jobLatency{},
+	}
+}
+
+// observeJob records the latency of a job run for a node of the workflow
+func (l *taskLatencies) observeJob(jtype, name string, version int, d time.Duration) {
+	key := fmt.Sprintf("%s"+core.Separator+"%s"+core.Separator+"%d", jtype, name, version)
+	l.Lock()
+	j, ok := l.jobs[key]
+	if !ok {
+		j = &jobLatency{jtype: jtype, name: name, version: version, histogram: core.NewLatencyHistogram()}
+		l.jobs[key] = j
+	}
+	l.Unlock()
+	j.histogram.Observe(d)
+}
+
+// observeRun records the duration of a run of the workflow and returns
+// whether it breached the given latency objective. An objective of 0 is never
+// breached.
+func (l *taskLatencies) observeRun(d, slo time.Duration) bool {
+	l.workflow.Observe(d)
+	l.Lock()
+	defer l.Unlock()
+	l.lastRun = d
+	if slo <= 0 || d <= slo {
+		return false
+	}
+	l.sloBreaches++
+	return true
+}
+
+func (l *taskLatencies) breaches() uint {
+	l.Lock()
+	defer l.Unlock()
+	return l.sloBreaches
+}
+
+func (l *taskLatencies) lastRunDuration() time.Duration {
+	l.Lock()
+	defer l.Unlock()
+	return l.lastRun
+}
+
+// snapshot returns the current state of the histograms, the nodes ordered by
+// type, plugin name and version.
+func (l *taskLatencies) snapshot(slo time.Duration) core.TaskLatencies {
+	l.Lock()
+	defer l.Unlock()
+	tl := core.TaskLatencies{
+		Workflow:    l.workflow.Snapshot(),
+		Jobs:        make([]core.JobLatency, 0, len(l.jobs)),
+		SLO:         slo,
+		SLOBreaches: l.sloBreaches,
+	}
+	keys := make([]string, 0, len(l.jobs))
+	for k := range l.jobs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		j := l.jobs[k]
+		tl.Jobs = append(tl.Jobs, core.JobLatency{
+			Type:          j.jtype,
+			PluginName:    j.name,
+			PluginVersion: j.version,
+			Latency:       j.histogram.Snapshot(),
+		})
+	}
+	return tl
+}
@@ -0,0 +1,132 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/intelsdi-x/gomit"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/scheduler_event"
+)
+
+type sloEventListener struct {
+	breached []*scheduler_event.LatencySLOBreachedEvent
+}
+
+func (l *sloEventListener) HandleGomitEvent(e gomit.Event) {
+	if v, ok := e.Body.(*scheduler_event.LatencySLOBreachedEvent); ok {
+		l.breached = append(l.breached, v)
+	}
+}
+
+type slowMetricManager struct {
+	mockPartialMetricManager
+	delay time.Duration
+}
+
+func (m *slowMetricManager) CollectMetrics(id string, tags map[string]map[string]string, deadline time.Time) ([]core.Metric, []error) {
+	time.Sleep(m.delay)
+	return m.mockPartialMetricManager.CollectMetrics(id, tags, deadline)
+}
+
+func TestTaskLatencies(t *testing.T) {
+	Convey("Task latencies", t, func() {
+		l := newTaskLatencies()
+
+		Convey("are kept for each node of the workflow", func() {
+			l.observeJob("collector", "", 0, 2*time.Millisecond)
+			l.observeJob("publisher", "file", 3, 4*time.Millisecond)
+			l.observeJob("publisher", "file", 3, 6*time.Millisecond)
+			tl := l.snapshot(0)
+			So(len(tl.Jobs), ShouldEqual, 2)
+			So(tl.Jobs[0].Type, ShouldEqual, "collector")
+			So(tl.Jobs[0].Latency.Count, ShouldEqual, 1)
+			So(tl.Jobs[1].PluginName, ShouldEqual, "file")
+			So(tl.Jobs[1].PluginVersion, ShouldEqual, 3)
+			So(tl.Jobs[1].Latency.Count, ShouldEqual, 2)
+			So(tl.Jobs[1].Latency.Max, ShouldEqual, 6*time.Millisecond)
+		})
+		Convey("count the runs breaching the latency objective", func() {
+			So(l.observeRun(time.Second, 0), ShouldBeFalse)
+			So(l.observeRun(10*time.Millisecond, 50*time.Millisecond), ShouldBeFalse)
+			So(l.observeRun(80*time.Millisecond, 50*time.Millisecond), ShouldBeTrue)
+			tl := l.snapshot(50 * time.Millisecond)
+			So(tl.Workflow.Count, ShouldEqual, 3)
+			So(tl.SLO, ShouldEqual, 50*time.Millisecond)
+			So(tl.SLOBreaches, ShouldEqual, 1)
+		})
+	})
+}
+
+func TestLatencySLO(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("Given a task with a latency objective", t, func() {
+		wm := newWorkManager()
+		wm.Start()
+		emitter := gomit.NewEventController()
+		lstnr := &sloEventListener{}
+		emitter.RegisterHandler("latency-slo-test", lstnr)
+
+		mm := &slowMetricManager{
+			mockPartialMetricManager: mockPartialMetricManager{
+				metrics: []core.Metric{plugin.NewMetricType(core.NewNamespace("foo", "bar"), time.Now(), nil, "", 1)},
+			},
+		}
+		wf := &schedulerWorkflow{eventEmitter: emitter}
+		tsk := &task{
+			id:               "1",
+			name:             "mock",
+			workflow:         wf,
+			manager:          wm,
+			metricsManager:   mm,
+			deadlineDuration: DefaultDeadlineDuration,
+			latencies:        newTaskLatencies(),
+		}
+		tsk.SetLatencySLO(20 * time.Millisecond)
+
+		Convey("a run within the objective emits no event", func() {
+			wf.Start(tsk)
+			So(lstnr.breached, ShouldBeEmpty)
+			So(tsk.SLOBreachCount(), ShouldEqual, 0)
+			tl := tsk.Latencies()
+			So(tl.Workflow.Count, ShouldEqual, 1)
+			So(len(tl.Jobs), ShouldEqual, 1)
+			So(tl.Jobs[0].Type, ShouldEqual, "collector")
+		})
+		Convey("a run breaching the objective emits an event", func() {
+			mm.delay = 40 * time.Millisecond
+			wf.Start(tsk)
+			So(tsk.SLOBreachCount(), ShouldEqual, 1)
+			So(len(lstnr.breached), ShouldEqual, 1)
+			So(lstnr.breached[0].TaskID, ShouldEqual, "1")
+			So(lstnr.breached[0].SLO, ShouldEqual, 20*time.Millisecond)
+			So(lstnr.breached[0].Latency, ShouldBeGreaterThan, 20*time.Millisecond)
+			So(tsk.LastRunDuration(), ShouldEqual, lstnr.breached[0].Latency)
+		})
+	})
+}
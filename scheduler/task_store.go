@@ -0,0 +1,217 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+const taskRecordExt = ".json"
+
+var (
+	// ErrTaskRecordMissingID - The error message for a task record without an ID
+	ErrTaskRecordMissingID = errors.New("Task record is missing an ID.")
+)
+
+// TaskStore persists the tasks managed by the scheduler so they can be
+// restored when snapd restarts.
+type TaskStore interface {
+	// Save creates or replaces the record of a task.
+	Save(*TaskRecord) error
+	// Remove deletes the record of the task with the given ID.
+	Remove(id string) error
+	// Load returns all the records held by the store.
+	Load() ([]*TaskRecord, error)
+}
+
+// TaskRecord is the persisted form of a task.
+type TaskRecord struct {
+	ID            string             `json:"id"`
+	Name          string             `json:"name"`
+	Deadline      string             `json:"deadline"`
+	StopOnFailure int                `json:"max-failures"`
+	CollectPolicy string             `json:"collect-policy"`
+	FailurePolicy core.FailurePolicy `json:"failure-policy"`
+	Catchup       core.CatchupPolicy `json:"catchup"`
+	Overlap       core.OverlapPolicy `json:"overlap"`
+	Priority      string             `json:"priority"`
+	LatencySLO    string             `json:"latency-slo,omitempty"`
+	Schedule      *core.Schedule     `json:"schedule"`
+	Workflow      *wmap.WorkflowMap  `json:"workflow"`
+	State         core.TaskState     `json:"state"`
+}
+
+// newTaskRecord returns the record of a task. Transient states are recorded
+// as the state the task settles in, so that a task which was running when
+// snapd stopped is started again when it is restored.
+func newTaskRecord(t *task) *TaskRecord {
+	state := t.State()
+	switch state {
+	case core.TaskFiring:
+		state = core.TaskSpinning
+	case core.TaskStopping:
+		state = core.TaskStopped
+	}
+	return &TaskRecord{
+		ID:            t.ID(),
+		Name:          t.GetName(),
+		Deadline:      t.DeadlineDuration().String(),
+		StopOnFailure: t.GetStopOnFailure(),
+		CollectPolicy: t.GetCollectPolicy().String(),
+		FailurePolicy: t.GetFailurePolicy(),
+		Catchup:       t.GetCatchupPolicy(),
+		Overlap:       t.GetOverlapPolicy(),
+		Priority:      t.GetPriority().String(),
+		LatencySLO:    latencySLOString(t.LatencySLO()),
+		Schedule:      core.ScheduleFromSchedule(t.Schedule()),
+		Workflow:      t.WMap(),
+		State:         state,
+	}
+}
+
+// latencySLOString returns the recorded form of a latency objective, empty
+// for a task without one
+func latencySLOString(slo time.Duration) string {
+	if slo == 0 {
+		return ""
+	}
+	return slo.String()
+}
+
+// fileTaskStore is a TaskStore that keeps one JSON file per task in a
+// directory.
+type fileTaskStore struct {
+	sync.Mutex
+	path string
+}
+
+// NewFileTaskStore returns a TaskStore keeping its records in the directory
+// at the given path. The directory is created when the records are loaded.
+func NewFileTaskStore(path string) TaskStore {
+	return &fileTaskStore{path: path}
+}
+
+func (f *fileTaskStore) recordPath(id string) string {
+	return filepath.Join(f.path, id+taskRecordExt)
+}
+
+// Save writes the record to a temporary file which is then renamed, so a
+// crash never leaves a partially written record behind.
+func (f *fileTaskStore) Save(r *TaskRecord) error {
+	if r.ID == "" {
+		return ErrTaskRecordMissingID
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	f.Lock()
+	defer f.Unlock()
+	if err := os.MkdirAll(f.path, 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(f.path, "."+r.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), f.recordPath(r.ID)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Remove deletes the record of a task. Removing a record that does not exist
+// is not an error.
+func (f *fileTaskStore) Remove(id string) error {
+	f.Lock()
+	defer f.Unlock()
+	if err := os.Remove(f.recordPath(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Load reads every record in the store. Records which cannot be read are
+// logged and skipped.
+func (f *fileTaskStore) Load() ([]*TaskRecord, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := os.MkdirAll(f.path, 0700); err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(f.path)
+	if err != nil {
+		return nil, err
+	}
+	records := []*TaskRecord{}
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") || !strings.HasSuffix(file.Name(), taskRecordExt) {
+			continue
+		}
+		logger := schedulerLogger.WithFields(log.Fields{
+			"_block":          "load-task-store",
+			"task-store-path": f.path,
+			"task-record":     file.Name(),
+		})
+		b, err := ioutil.ReadFile(filepath.Join(f.path, file.Name()))
+		if err != nil {
+			logger.Error("Reading task record ", err)
+			continue
+		}
+		r := &TaskRecord{}
+		if err := json.Unmarshal(b, r); err != nil {
+			logger.Error("Parsing task record ", err)
+			continue
+		}
+		if r.ID == "" {
+			logger.Error(ErrTaskRecordMissingID)
+			continue
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
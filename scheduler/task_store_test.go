@@ -0,0 +1,269 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/pkg/schedule"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+func TestFileTaskStore(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("File task store", t, func() {
+		dir, err := ioutil.TempDir("", "snap-task-store")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		store := NewFileTaskStore(filepath.Join(dir, "tasks"))
+
+		w := wmap.NewWorkflowMap()
+		w.CollectNode.AddMetric("/foo/bar", 1)
+		w.CollectNode.AddConfigItem("/foo/bar", "username", "root")
+		interval := "1s"
+		r := &TaskRecord{
+			ID:            "1234",
+			Name:          "foo",
+			Deadline:      "5s",
+			StopOnFailure: 3,
+			Schedule:      &core.Schedule{Type: "simple", Interval: interval},
+			Workflow:      w,
+			State:         core.TaskSpinning,
+		}
+
+		Convey("loads nothing from an empty store", func() {
+			records, err := store.Load()
+			So(err, ShouldBeNil)
+			So(records, ShouldBeEmpty)
+		})
+		Convey("loads the records it saved", func() {
+			So(store.Save(r), ShouldBeNil)
+			records, err := store.Load()
+			So(err, ShouldBeNil)
+			So(len(records), ShouldEqual, 1)
+			So(records[0].ID, ShouldEqual, "1234")
+			So(records[0].Name, ShouldEqual, "foo")
+			So(records[0].Deadline, ShouldEqual, "5s")
+			So(records[0].StopOnFailure, ShouldEqual, 3)
+			So(records[0].Schedule.Interval, ShouldEqual, interval)
+			So(records[0].Workflow.CollectNode.Metrics, ShouldContainKey, "/foo/bar")
+			So(records[0].State, ShouldEqual, core.TaskSpinning)
+		})
+		Convey("replaces a record saved twice", func() {
+			So(store.Save(r), ShouldBeNil)
+			r.State = core.TaskStopped
+			So(store.Save(r), ShouldBeNil)
+			records, err := store.Load()
+			So(err, ShouldBeNil)
+			So(len(records), ShouldEqual, 1)
+			So(records[0].State, ShouldEqual, core.TaskStopped)
+		})
+		Convey("removes records", func() {
+			So(store.Save(r), ShouldBeNil)
+			So(store.Remove(r.ID), ShouldBeNil)
+			records, err := store.Load()
+			So(err, ShouldBeNil)
+			So(records, ShouldBeEmpty)
+			So(store.Remove(r.ID), ShouldBeNil)
+		})
+		Convey("returns an error saving a record without an ID", func() {
+			So(store.Save(&TaskRecord{}), ShouldEqual, ErrTaskRecordMissingID)
+		})
+		Convey("skips records which cannot be parsed", func() {
+			So(store.Save(r), ShouldBeNil)
+			So(ioutil.WriteFile(filepath.Join(dir, "tasks", "bad.json"), []byte("{"), 0600), ShouldBeNil)
+			records, err := store.Load()
+			So(err, ShouldBeNil)
+			So(len(records), ShouldEqual, 1)
+		})
+	})
+}
+
+// mockEndingSchedule is a schedule whose first wait returns the given state
+type mockEndingSchedule struct {
+	state schedule.ScheduleState
+}
+
+func (m *mockEndingSchedule) GetState() schedule.ScheduleState {
+	return m.state
+}
+
+func (m *mockEndingSchedule) Validate() error {
+	return nil
+}
+
+func (m *mockEndingSchedule) Wait(last time.Time, stop <-chan struct{}) schedule.Response {
+	return m
+}
+
+func (m *mockEndingSchedule) State() schedule.ScheduleState {
+	return m.state
+}
+
+func (m *mockEndingSchedule) Error() error {
+	if m.state == schedule.Error {
+		return errors.New("schedule failed")
+	}
+	return nil
+}
+
+func (m *mockEndingSchedule) Missed() uint {
+	return 0
+}
+
+func (m *mockEndingSchedule) LastTime() time.Time {
+	return time.Time{}
+}
+
+func TestSchedulerTaskStore(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("Scheduler with a task store", t, func() {
+		dir, err := ioutil.TempDir("", "snap-task-store")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		cfg := GetDefaultConfig()
+		cfg.TaskStorePath = dir
+
+		c := new(mockMetricManager)
+		s := New(cfg)
+		s.SetMetricManager(c)
+		So(s.Start(), ShouldBeNil)
+
+		w := wmap.NewWorkflowMap()
+		w.CollectNode.AddMetric("/foo/bar", 1)
+		tsk, te := s.CreateTask(schedule.NewSimpleSchedule(time.Second*1), w, false,
+			core.SetTaskName("persisted"), core.TaskDeadlineDuration(3*time.Second), core.OptionStopOnFailure(7),
+			core.OptionFailurePolicy(core.FailurePolicy{Backoff: true, DisableAfter: 50}),
+			core.OptionCatchupPolicy(core.CatchupPolicy{Mode: core.CatchupRunAll, MaxRuns: 3}),
+			core.OptionOverlapPolicy(core.OverlapPolicy{Mode: core.OverlapConcurrent, MaxConcurrent: 2}),
+			core.OptionLatencySLO(250*time.Millisecond))
+		So(te.Errors(), ShouldBeEmpty)
+
+		Convey("records created tasks", func() {
+			records, err := s.taskStore.Load()
+			So(err, ShouldBeNil)
+			So(len(records), ShouldEqual, 1)
+			So(records[0].ID, ShouldEqual, tsk.ID())
+			So(records[0].State, ShouldEqual, core.TaskStopped)
+		})
+		Convey("removes the record of removed tasks", func() {
+			So(s.RemoveTask(tsk.ID()), ShouldBeNil)
+			records, err := s.taskStore.Load()
+			So(err, ShouldBeNil)
+			So(records, ShouldBeEmpty)
+		})
+		Convey("restores tasks when started", func() {
+			dtsk, te := s.CreateTask(schedule.NewSimpleSchedule(time.Second*1), w, false)
+			So(te.Errors(), ShouldBeEmpty)
+			dtsk.(*task).state = core.TaskDisabled
+			s.saveTask(dtsk.(*task))
+			s.Stop()
+
+			s2 := New(cfg)
+			s2.SetMetricManager(c)
+			So(s2.Start(), ShouldBeNil)
+			So(len(s2.GetTasks()), ShouldEqual, 2)
+
+			rt, err := s2.GetTask(tsk.ID())
+			So(err, ShouldBeNil)
+			So(rt.GetName(), ShouldEqual, "persisted")
+			So(rt.DeadlineDuration(), ShouldEqual, 3*time.Second)
+			So(rt.GetStopOnFailure(), ShouldEqual, 7)
+			So(rt.GetFailurePolicy(), ShouldResemble, core.FailurePolicy{Backoff: true, DisableAfter: 50})
+			So(rt.GetCatchupPolicy(), ShouldResemble, core.CatchupPolicy{Mode: core.CatchupRunAll, MaxRuns: 3})
+			So(rt.GetOverlapPolicy(), ShouldResemble, core.OverlapPolicy{Mode: core.OverlapConcurrent, MaxConcurrent: 2})
+			So(rt.LatencySLO(), ShouldEqual, 250*time.Millisecond)
+			So(rt.State(), ShouldEqual, core.TaskStopped)
+			So(rt.Schedule().(*schedule.SimpleSchedule).Interval, ShouldEqual, time.Second)
+
+			rdt, err := s2.GetTask(dtsk.ID())
+			So(err, ShouldBeNil)
+			So(rdt.State(), ShouldEqual, core.TaskDisabled)
+		})
+		Convey("records the task disabled by an error of its schedule", func() {
+			tt := tsk.(*task)
+			tt.schedule = &mockEndingSchedule{state: schedule.Error}
+			tt.Spin()
+			tt.awaitStop()
+			records, err := s.taskStore.Load()
+			So(err, ShouldBeNil)
+			So(len(records), ShouldEqual, 1)
+			So(records[0].State, ShouldEqual, core.TaskDisabled)
+		})
+		Convey("removes the record of a task whose schedule ended", func() {
+			tt := tsk.(*task)
+			tt.schedule = &mockEndingSchedule{state: schedule.Ended}
+			tt.Spin()
+			tt.awaitStop()
+			So(tt.State(), ShouldEqual, core.TaskEnded)
+			records, err := s.taskStore.Load()
+			So(err, ShouldBeNil)
+			So(records, ShouldBeEmpty)
+		})
+		Convey("does not restore ended tasks", func() {
+			tt := tsk.(*task)
+			tt.state = core.TaskEnded
+			s.saveTask(tt)
+			s.Stop()
+
+			s2 := New(cfg)
+			s2.SetMetricManager(c)
+			So(s2.Start(), ShouldBeNil)
+			So(s2.GetTasks(), ShouldBeEmpty)
+			records, err := s2.taskStore.Load()
+			So(err, ShouldBeNil)
+			So(records, ShouldBeEmpty)
+		})
+		Convey("does not restore tasks whose window stopped", func() {
+			tt := tsk.(*task)
+			stop := time.Now().Add(-time.Minute)
+			tt.schedule = schedule.NewWindowedSchedule(time.Second, nil, &stop)
+			s.saveTask(tt)
+			s.Stop()
+
+			s2 := New(cfg)
+			s2.SetMetricManager(c)
+			So(s2.Start(), ShouldBeNil)
+			So(s2.GetTasks(), ShouldBeEmpty)
+			records, err := s2.taskStore.Load()
+			So(err, ShouldBeNil)
+			So(records, ShouldBeEmpty)
+		})
+		Convey("does not record autodiscovered tasks", func() {
+			atsk, te := s.createAutodiscoveredTask(schedule.NewSimpleSchedule(time.Second*1), w, false)
+			So(te.Errors(), ShouldBeEmpty)
+			records, err := s.taskStore.Load()
+			So(err, ShouldBeNil)
+			So(len(records), ShouldEqual, 1)
+			So(records[0].ID, ShouldNotEqual, atsk.ID())
+		})
+	})
+}
@@ -22,10 +22,13 @@ limitations under the License.
 package scheduler
 
 import (
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
 	"github.com/intelsdi-x/snap/pkg/schedule"
 	"github.com/intelsdi-x/snap/scheduler/wmap"
 
@@ -38,6 +41,58 @@ var (
 	emitter = gomit.NewEventController()
 )
 
+// mockWaitSchedule is a schedule which never fires, and signals when its
+// waiter is released.
+type mockWaitSchedule struct {
+	released chan struct{}
+}
+
+func (m *mockWaitSchedule) GetState() schedule.ScheduleState {
+	return schedule.Active
+}
+
+func (m *mockWaitSchedule) Validate() error {
+	return nil
+}
+
+func (m *mockWaitSchedule) Wait(last time.Time, stop <-chan struct{}) schedule.Response {
+	<-stop
+	close(m.released)
+	return &schedule.SimpleScheduleResponse{}
+}
+
+// mockSlowStopSchedule is a schedule which never fires, and whose waiters
+// take a while to return once released. It records the most waiters it had
+// at a time.
+type mockSlowStopSchedule struct {
+	sync.Mutex
+	waiters    int
+	maxWaiters int
+}
+
+func (m *mockSlowStopSchedule) GetState() schedule.ScheduleState {
+	return schedule.Active
+}
+
+func (m *mockSlowStopSchedule) Validate() error {
+	return nil
+}
+
+func (m *mockSlowStopSchedule) Wait(last time.Time, stop <-chan struct{}) schedule.Response {
+	m.Lock()
+	m.waiters++
+	if m.waiters > m.maxWaiters {
+		m.maxWaiters = m.waiters
+	}
+	m.Unlock()
+	<-stop
+	time.Sleep(20 * time.Millisecond)
+	m.Lock()
+	m.waiters--
+	m.Unlock()
+	return &schedule.SimpleScheduleResponse{}
+}
+
 func TestTask(t *testing.T) {
 	log.SetLevel(log.FatalLevel)
 	Convey("Task", t, func() {
@@ -115,6 +170,53 @@ func TestTask(t *testing.T) {
 			})
 		})
 
+		Convey("Stopping a task releases its schedule waiter", func() {
+			sch := &mockWaitSchedule{released: make(chan struct{})}
+			task, err := newTask(sch, wf, newWorkManager(), c, emitter)
+			So(err, ShouldBeNil)
+			task.Spin()
+			task.Stop()
+			select {
+			case <-sch.released:
+			case <-time.After(time.Second):
+				So("schedule waiter was not released", ShouldBeEmpty)
+			}
+		})
+
+		Convey("A released schedule waiter returns before the schedule is waited on again", func() {
+			sch := &mockSlowStopSchedule{}
+			task, err := newTask(sch, wf, newWorkManager(), c, emitter)
+			So(err, ShouldBeNil)
+			task.Spin()
+			task.reschedule()
+			time.Sleep(10 * time.Millisecond)
+			task.Stop()
+			task.awaitStop()
+			task.Spin()
+			task.Stop()
+			task.awaitStop()
+			sch.Lock()
+			defer sch.Unlock()
+			So(sch.maxWaiters, ShouldEqual, 1)
+			So(sch.waiters, ShouldEqual, 0)
+		})
+
+		Convey("Rescheduled task waits on its new schedule", func() {
+			sch := schedule.NewSimpleSchedule(time.Hour)
+			task, err := newTask(sch, wf, newWorkManager(), c, emitter)
+			So(err, ShouldBeNil)
+			task.Spin()
+			task.Lock()
+			task.schedule = schedule.NewSimpleSchedule(time.Millisecond * 10)
+			task.Unlock()
+			task.reschedule()
+			time.Sleep(time.Millisecond * 100)
+			task.Lock()
+			So(task.hitCount, ShouldBeGreaterThan, 0)
+			task.Unlock()
+			task.Stop()
+		})
+
 		Convey("task fires", func() {
 			sch := schedule.NewSimpleSchedule(time.Nanosecond * 100)
 			task, err := newTask(sch, wf, newWorkManager(), c, emitter)
@@ -146,6 +248,119 @@ func TestTask(t *testing.T) {
 			So(err, ShouldBeNil)
 			So(task.State(), ShouldEqual, core.TaskStopped)
 		})
+
+		Convey("Backing off task", func() {
+			sch := schedule.NewSimpleSchedule(time.Millisecond * 10)
+			task, err := newTask(sch, wf, newWorkManager(), c, emitter,
+				core.OptionFailurePolicy(core.FailurePolicy{Backoff: true, MaxBackoff: 4, DisableAfter: 20}))
+			So(err, ShouldBeNil)
+
+			Convey("doubles its interval on each failure up to the max backoff", func() {
+				for _, level := range []uint{1, 2, 2} {
+					task.backOff()
+					So(task.BackoffLevel(), ShouldEqual, level)
+					skipped := 0
+					for task.skipBackoffFire() {
+						skipped++
+					}
+					So(skipped, ShouldEqual, 1<<level-1)
+				}
+			})
+			Convey("restores its interval when reset", func() {
+				task.backOff()
+				task.resetBackoff()
+				So(task.BackoffLevel(), ShouldEqual, 0)
+				So(task.skipBackoffFire(), ShouldBeFalse)
+			})
+			Convey("backs off until it reaches its disable-after limit", func() {
+				So(task.backsOff(19), ShouldBeTrue)
+				So(task.backsOff(20), ShouldBeFalse)
+			})
+			Convey("never backs off without a backoff failure policy", func() {
+				task.SetFailurePolicy(core.FailurePolicy{})
+				So(task.backsOff(1), ShouldBeFalse)
+			})
+		})
+
+		Convey("Task failing on a full queue counts the overflow", func() {
+			sch := schedule.NewSimpleSchedule(time.Second)
+			task, err := newTask(sch, wf, newWorkManager(), c, emitter)
+			So(err, ShouldBeNil)
+			task.RecordFailure([]error{&queuingError{Err: errLimitExceeded}})
+			task.RecordFailure([]error{errors.New("plugin failed")})
+			So(task.FailedCount(), ShouldEqual, 2)
+			So(task.OverflowCount(), ShouldEqual, 1)
+		})
+
+		Convey("Task failing on a plugin call past its deadline counts the overrun", func() {
+			sch := schedule.NewSimpleSchedule(time.Second)
+			task, err := newTask(sch, wf, newWorkManager(), c, emitter)
+			So(err, ShouldBeNil)
+			task.RecordFailure([]error{serror.New(core.ErrDeadlineExceeded)})
+			// as carried by its message from a remote control
+			task.RecordFailure([]error{errors.New(core.ErrDeadlineExceeded.Error())})
+			task.RecordFailure([]error{errors.New("plugin failed")})
+			So(task.FailedCount(), ShouldEqual, 3)
+			So(task.DeadlineExceededCount(), ShouldEqual, 2)
+			So(task.OverflowCount(), ShouldEqual, 0)
+		})
+
+		Convey("Catching up task", func() {
+			sch := schedule.NewSimpleSchedule(time.Second)
+			task, err := newTask(sch, wf, newWorkManager(), c, emitter,
+				core.OptionCatchupPolicy(core.CatchupPolicy{Mode: core.CatchupRunAll, MaxRuns: 2}))
+			So(err, ShouldBeNil)
+			So(sch.Validate(), ShouldBeNil)
+			last := time.Now().Add(-5 * time.Second)
+
+			Convey("runs the most recent missed intervals up to its max runs", func() {
+				task.catchUp(last, 4)
+				So(task.HitCount(), ShouldEqual, 2)
+				runs := task.History()
+				So(len(runs), ShouldEqual, 2)
+				So(runs[0].CatchUp, ShouldBeTrue)
+				So(runs[1].CatchUp, ShouldBeTrue)
+				So(runs[1].FireTime.Sub(runs[0].FireTime), ShouldEqual, time.Second)
+			})
+			Convey("runs the most recent missed interval only with run-once", func() {
+				task.SetCatchupPolicy(core.CatchupPolicy{Mode: core.CatchupRunOnce})
+				task.catchUp(last, 4)
+				So(task.HitCount(), ShouldEqual, 1)
+			})
+			Convey("never catches up with the skip policy", func() {
+				task.SetCatchupPolicy(core.CatchupPolicy{Mode: core.CatchupSkip})
+				task.catchUp(last, 4)
+				So(task.HitCount(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("Busy task", func() {
+			sch := schedule.NewSimpleSchedule(time.Second)
+			task, err := newTask(sch, wf, newWorkManager(), c, emitter)
+			So(err, ShouldBeNil)
+			task.runs = 1
+
+			Convey("queues one fire by default and skips the others", func() {
+				So(task.overlaps(), ShouldBeTrue)
+				So(task.queued, ShouldBeTrue)
+				So(task.overlaps(), ShouldBeTrue)
+				So(task.BusySkipCount(), ShouldEqual, 1)
+				So(task.MissedCount(), ShouldEqual, 0)
+			})
+			Convey("skips every fire with the skip policy", func() {
+				task.SetOverlapPolicy(core.OverlapPolicy{Mode: core.OverlapSkip})
+				So(task.overlaps(), ShouldBeTrue)
+				So(task.queued, ShouldBeFalse)
+				So(task.BusySkipCount(), ShouldEqual, 1)
+			})
+			Convey("runs concurrently up to its max concurrent runs", func() {
+				task.SetOverlapPolicy(core.OverlapPolicy{Mode: core.OverlapConcurrent, MaxConcurrent: 2})
+				So(task.overlaps(), ShouldBeFalse)
+				task.runs = 2
+				So(task.overlaps(), ShouldBeTrue)
+				So(task.BusySkipCount(), ShouldEqual, 1)
+			})
+		})
 	})
 
 	Convey("Create task collection", t, func() {
@@ -0,0 +1,241 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+// transformJob applies the rules of a transform node to the metrics of its
+// parent job. It is run within the workflow, not queued, as it needs no
+// plugin.
+type transformJob struct {
+	*coreJob
+	parentJob job
+	rules     []wmap.TransformRule
+	metrics   []core.Metric
+}
+
+func newTransformJob(parentJob job, rules []wmap.TransformRule, taskID string) job {
+	return &transformJob{
+		parentJob: parentJob,
+		rules:     rules,
+		metrics:   []core.Metric{},
+		coreJob:   newCoreJob(transformJobType, time.Now().Add(defaultDeadline), taskID, "", 0),
+	}
+}
+
+func (t *transformJob) Metrics() []core.Metric {
+	return t.metrics
+}
+
+func (t *transformJob) Run() {
+	log.WithFields(log.Fields{
+		"_module":    "scheduler-job",
+		"block":      "run",
+		"job-type":   "transform",
+		"rule-count": len(t.rules),
+	}).Debug("starting transform job")
+
+	mts, errs := transformMetrics(t.rules, t.parentJob.Metrics())
+	if len(errs) > 0 {
+		for _, e := range errs {
+			log.WithFields(log.Fields{
+				"_module":  "scheduler-job",
+				"block":    "run",
+				"job-type": "transform",
+				"error":    e,
+			}).Error("error with transform job")
+		}
+		t.AddErrors(errs...)
+		return
+	}
+	t.metrics = mts
+}
+
+// transformedMetric is a metric whose namespace, tags or value were changed
+// by the rules of a transform node
+type transformedMetric struct {
+	core.Metric
+	namespace core.Namespace
+	tags      map[string]string
+	data      interface{}
+}
+
+func (m transformedMetric) Namespace() core.Namespace {
+	return m.namespace
+}
+
+func (m transformedMetric) Tags() map[string]string {
+	return m.tags
+}
+
+func (m transformedMetric) Data() interface{} {
+	return m.data
+}
+
+// transformMetrics applies the rules, in order, to each of the metrics
+func transformMetrics(rules []wmap.TransformRule, mts []core.Metric) ([]core.Metric, []error) {
+	out := make([]core.Metric, 0, len(mts))
+	var errs []error
+	for _, m := range mts {
+		tm, keep, err := transformMetric(rules, m)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if keep {
+			out = append(out, tm)
+		}
+	}
+	return out, errs
+}
+
+// transformMetric applies the rules to a metric. The returned bool is false
+// if the metric was dropped.
+func transformMetric(rules []wmap.TransformRule, m core.Metric) (core.Metric, bool, error) {
+	tm := transformedMetric{
+		Metric:    m,
+		namespace: m.Namespace(),
+		tags:      m.Tags(),
+		data:      m.Data(),
+	}
+	// the tags of the metric may be shared with other metrics, so they are
+	// copied before being set
+	tagsCopied := false
+	for _, r := range rules {
+		if !r.Selects(tm.namespace.Strings()) {
+			continue
+		}
+		switch r.Op {
+		case wmap.TransformSetTag:
+			if !tagsCopied {
+				tags := make(map[string]string, len(tm.tags)+1)
+				for k, v := range tm.tags {
+					tags[k] = v
+				}
+				tm.tags = tags
+				tagsCopied = true
+			}
+			tm.tags[r.Tag] = r.Value
+		case wmap.TransformDrop:
+			return nil, false, nil
+		case wmap.TransformRename:
+			tm.namespace = renameNamespace(tm.namespace, wmap.NamespaceElements(r.From), wmap.NamespaceElements(r.To))
+		case wmap.TransformMultiply:
+			f, ok := toFloat(tm.data)
+			if !ok {
+				return nil, false, fmt.Errorf("cannot multiply value of metric %s: %T is not a number", tm.namespace.String(), tm.data)
+			}
+			tm.data = f * r.Factor
+		case wmap.TransformCast:
+			d, err := castValue(tm.data, r.Type)
+			if err != nil {
+				return nil, false, fmt.Errorf("cannot cast value of metric %s: %v", tm.namespace.String(), err)
+			}
+			tm.data = d
+		}
+	}
+	return tm, true, nil
+}
+
+// renameNamespace replaces the prefix from of the namespace with to. The
+// elements following the prefix are kept, along with their descriptions.
+func renameNamespace(ns core.Namespace, from, to []string) core.Namespace {
+	if len(ns) < len(from) {
+		return ns
+	}
+	for i, e := range from {
+		if ns[i].Value != e {
+			return ns
+		}
+	}
+	renamed := core.NewNamespace(to...)
+	return append(renamed, ns[len(from):]...)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case int8:
+		return float64(x), true
+	case int16:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case uint:
+		return float64(x), true
+	case uint8:
+		return float64(x), true
+	case uint16:
+		return float64(x), true
+	case uint32:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	}
+	return 0, false
+}
+
+// castValue converts the value of a metric to an int64, a float64 or a
+// string. Strings are parsed when converted to numbers.
+func castValue(v interface{}, typ string) (interface{}, error) {
+	switch typ {
+	case wmap.CastString:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		return fmt.Sprintf("%v", v), nil
+	case wmap.CastFloat:
+		if s, ok := v.(string); ok {
+			return strconv.ParseFloat(s, 64)
+		}
+		if f, ok := toFloat(v); ok {
+			return f, nil
+		}
+	case wmap.CastInt:
+		switch x := v.(type) {
+		case string:
+			return strconv.ParseInt(x, 10, 64)
+		case int64:
+			return x, nil
+		case uint64:
+			return int64(x), nil
+		}
+		if f, ok := toFloat(v); ok {
+			return int64(f), nil
+		}
+	}
+	return nil, fmt.Errorf("%T cannot be cast to %s", v, typ)
+}
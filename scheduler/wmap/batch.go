@@ -0,0 +1,97 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// BatchPolicy is the policy of a publish node accumulating the metrics of
+// several runs before handing them to its plugin at once. The batch is
+// published once it holds MaxMetrics metrics or its oldest metric was
+// buffered MaxAge ago, whichever comes first. At least one of the limits
+// must be set.
+type BatchPolicy struct {
+	MaxMetrics int    `json:"max_metrics,omitempty"yaml:"max_metrics"`
+	MaxAge     string `json:"max_age,omitempty"yaml:"max_age"`
+}
+
+func (b *BatchPolicy) UnmarshalJSON(data []byte) error {
+	t := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	for k, v := range t {
+		switch k {
+		case "max_metrics":
+			if err := json.Unmarshal(v, &b.MaxMetrics); err != nil {
+				return fmt.Errorf("%v (while parsing 'max_metrics')", err)
+			}
+		case "max_age":
+			if err := json.Unmarshal(v, &b.MaxAge); err != nil {
+				return fmt.Errorf("%v (while parsing 'max_age')", err)
+			}
+		default:
+			return fmt.Errorf("Unrecognized key '%v' in batch policy of publish workflow of task.", k)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if neither limit is set, the maximum number of
+// metrics is negative or the maximum age is malformed
+func (b *BatchPolicy) Validate() error {
+	if b == nil {
+		return nil
+	}
+	if b.MaxMetrics < 0 {
+		return fmt.Errorf("bad batch policy: negative max_metrics %d", b.MaxMetrics)
+	}
+	age, err := b.MaxAgeDuration()
+	if err != nil {
+		return fmt.Errorf("bad batch policy: %v", err)
+	}
+	if b.MaxMetrics == 0 && age == 0 {
+		return fmt.Errorf("bad batch policy: max_metrics or max_age must be set")
+	}
+	return nil
+}
+
+// MaxAgeDuration returns the time the oldest metric of a batch waits at most
+// before it is published, 0 if MaxAge is empty
+func (b *BatchPolicy) MaxAgeDuration() (time.Duration, error) {
+	if b.MaxAge == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(b.MaxAge)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative max_age %s", b.MaxAge)
+	}
+	return d, nil
+}
+
+func (b *BatchPolicy) String() string {
+	return fmt.Sprintf("max_metrics=%d max_age=%s", b.MaxMetrics, b.MaxAge)
+}
@@ -0,0 +1,105 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wmap
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var batchJSON = `
+{
+    "collect": {
+        "metrics": {
+            "/intel/mock/*": {}
+        },
+        "publish": [
+            {
+                "plugin_name": "influxdb",
+                "batch": {
+                    "max_metrics": 5000,
+                    "max_age": "30s"
+                }
+            }
+        ]
+    }
+}
+`
+
+var batchYAML = `
+---
+  collect:
+    metrics:
+      /intel/mock/*: {}
+    publish:
+      -
+        plugin_name: "influxdb"
+        batch:
+          max_metrics: 5000
+          max_age: "30s"
+`
+
+func TestBatchFromWorkflow(t *testing.T) {
+	Convey("Batch policies of publish nodes", t, func() {
+		check := func(wf *WorkflowMap) {
+			So(wf.CollectNode.PublishNodes, ShouldHaveLength, 1)
+			b := wf.CollectNode.PublishNodes[0].Batch
+			So(b, ShouldNotBeNil)
+			So(b.MaxMetrics, ShouldEqual, 5000)
+			So(b.MaxAge, ShouldEqual, "30s")
+		}
+		Convey("From JSON", func() {
+			wf, err := FromJson(batchJSON)
+			So(err, ShouldBeNil)
+			check(wf)
+		})
+		Convey("From YAML", func() {
+			wf, err := FromYaml(batchYAML)
+			So(err, ShouldBeNil)
+			check(wf)
+		})
+		Convey("Unknown keys are rejected", func() {
+			_, err := FromJson(`{"collect": {"metrics": {"/foo": {}}, "publish": [{"plugin_name": "file", "batch": {"size": 3}}]}}`)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestBatchValidate(t *testing.T) {
+	Convey("BatchPolicy.Validate()", t, func() {
+		var b *BatchPolicy
+		So(b.Validate(), ShouldBeNil)
+		So((&BatchPolicy{MaxMetrics: 100}).Validate(), ShouldBeNil)
+		So((&BatchPolicy{MaxAge: "10s"}).Validate(), ShouldBeNil)
+		So((&BatchPolicy{}).Validate(), ShouldNotBeNil)
+		So((&BatchPolicy{MaxMetrics: -1, MaxAge: "10s"}).Validate(), ShouldNotBeNil)
+		So((&BatchPolicy{MaxAge: "later"}).Validate(), ShouldNotBeNil)
+		So((&BatchPolicy{MaxAge: "-1s"}).Validate(), ShouldNotBeNil)
+	})
+	Convey("BatchPolicy.MaxAgeDuration()", t, func() {
+		d, err := (&BatchPolicy{MaxAge: "30s"}).MaxAgeDuration()
+		So(err, ShouldBeNil)
+		So(d, ShouldEqual, 30*time.Second)
+	})
+}
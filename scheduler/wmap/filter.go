@@ -0,0 +1,171 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Filter selects the metrics handed to the plugin of a process or publish
+// node. A metric passes the filter when its namespace matches one of the
+// Include globs (or Include is empty) and none of the Exclude globs, and its
+// tags satisfy every predicate of Tags.
+//
+// The globs are namespaces whose elements may hold the wildcards of
+// path.Match, '*' matching within an element. An element of '**' matches any
+// number of elements, so /intel/procfs/** matches every metric of procfs.
+//
+// Tags maps a tag name to a glob its value must match. A glob prefixed with
+// '!' negates the predicate, which a metric without the tag then satisfies.
+type Filter struct {
+	Include []string          `json:"include,omitempty"yaml:"include"`
+	Exclude []string          `json:"exclude,omitempty"yaml:"exclude"`
+	Tags    map[string]string `json:"tags,omitempty"yaml:"tags"`
+}
+
+func (f *Filter) UnmarshalJSON(data []byte) error {
+	t := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	for k, v := range t {
+		switch k {
+		case "include":
+			if err := json.Unmarshal(v, &f.Include); err != nil {
+				return fmt.Errorf("%v (while parsing 'include')", err)
+			}
+		case "exclude":
+			if err := json.Unmarshal(v, &f.Exclude); err != nil {
+				return fmt.Errorf("%v (while parsing 'exclude')", err)
+			}
+		case "tags":
+			if err := json.Unmarshal(v, &f.Tags); err != nil {
+				return fmt.Errorf("%v (while parsing 'tags')", err)
+			}
+		default:
+			return fmt.Errorf("Unrecognized key '%v' in filter of workflow node of task.", k)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if a glob of the filter is malformed
+func (f *Filter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	for _, g := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if !strings.HasPrefix(g, "/") {
+			return fmt.Errorf("bad filter namespace %s: must start with /", g)
+		}
+		for _, e := range splitNamespace(g) {
+			if _, err := path.Match(e, ""); err != nil {
+				return fmt.Errorf("bad filter namespace %s: %v", g, err)
+			}
+		}
+	}
+	for k, g := range f.Tags {
+		if _, err := path.Match(strings.TrimPrefix(g, "!"), ""); err != nil {
+			return fmt.Errorf("bad filter tag %s: %v", k, err)
+		}
+	}
+	return nil
+}
+
+// Matches returns whether a metric with the given namespace and tags passes
+// the filter. Every metric passes a nil filter.
+func (f *Filter) Matches(ns []string, tags map[string]string) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.Include) > 0 {
+		included := false
+		for _, g := range f.Include {
+			if matchNamespace(splitNamespace(g), ns) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	for _, g := range f.Exclude {
+		if matchNamespace(splitNamespace(g), ns) {
+			return false
+		}
+	}
+	for k, g := range f.Tags {
+		negate := strings.HasPrefix(g, "!")
+		v, ok := tags[k]
+		matched := false
+		if ok {
+			matched, _ = path.Match(strings.TrimPrefix(g, "!"), v)
+		}
+		if matched == negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *Filter) String() string {
+	var parts []string
+	if len(f.Include) > 0 {
+		parts = append(parts, "include="+strings.Join(f.Include, ","))
+	}
+	if len(f.Exclude) > 0 {
+		parts = append(parts, "exclude="+strings.Join(f.Exclude, ","))
+	}
+	for k, v := range f.Tags {
+		parts = append(parts, fmt.Sprintf("tag %s=%s", k, v))
+	}
+	return strings.Join(parts, " ")
+}
+
+func splitNamespace(ns string) []string {
+	return strings.Split(strings.TrimPrefix(ns, "/"), "/")
+}
+
+// matchNamespace matches the elements of a namespace against the elements of
+// a glob
+func matchNamespace(glob, ns []string) bool {
+	if len(glob) == 0 {
+		return len(ns) == 0
+	}
+	if glob[0] == "**" {
+		for i := 0; i <= len(ns); i++ {
+			if matchNamespace(glob[1:], ns[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(ns) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(glob[0], ns[0]); !ok {
+		return false
+	}
+	return matchNamespace(glob[1:], ns[1:])
+}
@@ -0,0 +1,160 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wmap
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var filterJSON = `
+{
+    "collect": {
+        "metrics": {
+            "/intel/mock/*": {}
+        },
+        "process": [
+            {
+                "plugin_name": "passthru",
+                "filter": {
+                    "include": ["/intel/mock/**"],
+                    "exclude": ["/intel/mock/foo"]
+                }
+            }
+        ],
+        "publish": [
+            {
+                "plugin_name": "file",
+                "filter": {
+                    "tags": {
+                        "rack": "r1*",
+                        "env": "!test"
+                    }
+                }
+            }
+        ]
+    }
+}
+`
+
+var filterYAML = `
+---
+  collect:
+    metrics:
+      /intel/mock/*: {}
+    process:
+      -
+        plugin_name: "passthru"
+        filter:
+          include:
+            - /intel/mock/**
+          exclude:
+            - /intel/mock/foo
+    publish:
+      -
+        plugin_name: "file"
+        filter:
+          tags:
+            rack: "r1*"
+            env: "!test"
+`
+
+func TestFilterFromWorkflow(t *testing.T) {
+	Convey("Filters of workflow nodes", t, func() {
+		check := func(wf *WorkflowMap) {
+			So(wf.CollectNode.ProcessNodes, ShouldHaveLength, 1)
+			f := wf.CollectNode.ProcessNodes[0].Filter
+			So(f, ShouldNotBeNil)
+			So(f.Include, ShouldResemble, []string{"/intel/mock/**"})
+			So(f.Exclude, ShouldResemble, []string{"/intel/mock/foo"})
+			So(wf.CollectNode.PublishNodes, ShouldHaveLength, 1)
+			f = wf.CollectNode.PublishNodes[0].Filter
+			So(f, ShouldNotBeNil)
+			So(f.Tags, ShouldResemble, map[string]string{"rack": "r1*", "env": "!test"})
+		}
+		Convey("From JSON", func() {
+			wf, err := FromJson(filterJSON)
+			So(err, ShouldBeNil)
+			check(wf)
+		})
+		Convey("From YAML", func() {
+			wf, err := FromYaml(filterYAML)
+			So(err, ShouldBeNil)
+			check(wf)
+		})
+		Convey("Unknown keys are rejected", func() {
+			_, err := FromJson(`{"collect": {"metrics": {"/foo": {}}, "publish": [{"plugin_name": "file", "filter": {"includes": ["/foo"]}}]}}`)
+			So(err, ShouldNotBeNil)
+		})
+		Convey("Nodes without a filter have none", func() {
+			wf, err := FromJson(`{"collect": {"metrics": {"/foo": {}}, "publish": [{"plugin_name": "file"}]}}`)
+			So(err, ShouldBeNil)
+			So(wf.CollectNode.PublishNodes[0].Filter, ShouldBeNil)
+		})
+	})
+}
+
+func TestFilterMatches(t *testing.T) {
+	Convey("Filter.Matches()", t, func() {
+		ns := []string{"intel", "mock", "host0", "foo"}
+		Convey("A nil filter matches every metric", func() {
+			var f *Filter
+			So(f.Matches(ns, nil), ShouldBeTrue)
+		})
+		Convey("Include globs", func() {
+			So((&Filter{Include: []string{"/intel/mock/*/foo"}}).Matches(ns, nil), ShouldBeTrue)
+			So((&Filter{Include: []string{"/intel/mock/*"}}).Matches(ns, nil), ShouldBeFalse)
+			So((&Filter{Include: []string{"/intel/**"}}).Matches(ns, nil), ShouldBeTrue)
+			So((&Filter{Include: []string{"/**/foo"}}).Matches(ns, nil), ShouldBeTrue)
+			So((&Filter{Include: []string{"/intel/mock/host0/foo/**"}}).Matches(ns, nil), ShouldBeTrue)
+			So((&Filter{Include: []string{"/intel/procfs/**", "/intel/mock/host[0-9]/*"}}).Matches(ns, nil), ShouldBeTrue)
+			So((&Filter{Include: []string{"/intel/procfs/**"}}).Matches(ns, nil), ShouldBeFalse)
+		})
+		Convey("Exclude globs", func() {
+			So((&Filter{Exclude: []string{"/intel/mock/*/foo"}}).Matches(ns, nil), ShouldBeFalse)
+			So((&Filter{Exclude: []string{"/intel/mock/*/bar"}}).Matches(ns, nil), ShouldBeTrue)
+			So((&Filter{Include: []string{"/intel/**"}, Exclude: []string{"/**/foo"}}).Matches(ns, nil), ShouldBeFalse)
+		})
+		Convey("Tag predicates", func() {
+			tags := map[string]string{"rack": "r12", "env": "prod"}
+			So((&Filter{Tags: map[string]string{"rack": "r1*"}}).Matches(ns, tags), ShouldBeTrue)
+			So((&Filter{Tags: map[string]string{"rack": "r2*"}}).Matches(ns, tags), ShouldBeFalse)
+			So((&Filter{Tags: map[string]string{"dc": "*"}}).Matches(ns, tags), ShouldBeFalse)
+			So((&Filter{Tags: map[string]string{"env": "!test"}}).Matches(ns, tags), ShouldBeTrue)
+			So((&Filter{Tags: map[string]string{"env": "!prod"}}).Matches(ns, tags), ShouldBeFalse)
+			So((&Filter{Tags: map[string]string{"dc": "!east"}}).Matches(ns, tags), ShouldBeTrue)
+			So((&Filter{Tags: map[string]string{"rack": "r1*", "env": "!prod"}}).Matches(ns, tags), ShouldBeFalse)
+		})
+	})
+}
+
+func TestFilterValidate(t *testing.T) {
+	Convey("Filter.Validate()", t, func() {
+		var f *Filter
+		So(f.Validate(), ShouldBeNil)
+		So((&Filter{Include: []string{"/intel/**"}, Tags: map[string]string{"rack": "!r1*"}}).Validate(), ShouldBeNil)
+		So((&Filter{Include: []string{"intel/**"}}).Validate(), ShouldNotBeNil)
+		So((&Filter{Exclude: []string{"/intel/[mock"}}).Validate(), ShouldNotBeNil)
+		So((&Filter{Tags: map[string]string{"rack": "r[1"}}).Validate(), ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,102 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RetryPolicy is the policy of a publish node whose plugin fails to publish
+// the metrics of a run. The publish is retried up to Attempts times, waiting
+// Backoff before the first retry and twice as long before each of the next.
+// If Spool is set, the metrics still failing to publish are written to the
+// spool of the scheduler and replayed once the publisher recovers.
+type RetryPolicy struct {
+	Attempts int    `json:"attempts"yaml:"attempts"`
+	Backoff  string `json:"backoff,omitempty"yaml:"backoff"`
+	Spool    bool   `json:"spool,omitempty"yaml:"spool"`
+}
+
+func (r *RetryPolicy) UnmarshalJSON(data []byte) error {
+	t := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	for k, v := range t {
+		switch k {
+		case "attempts":
+			if err := json.Unmarshal(v, &r.Attempts); err != nil {
+				return fmt.Errorf("%v (while parsing 'attempts')", err)
+			}
+		case "backoff":
+			if err := json.Unmarshal(v, &r.Backoff); err != nil {
+				return fmt.Errorf("%v (while parsing 'backoff')", err)
+			}
+		case "spool":
+			if err := json.Unmarshal(v, &r.Spool); err != nil {
+				return fmt.Errorf("%v (while parsing 'spool')", err)
+			}
+		default:
+			return fmt.Errorf("Unrecognized key '%v' in retry policy of publish workflow of task.", k)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if the number of attempts is negative or the
+// backoff is malformed
+func (r *RetryPolicy) Validate() error {
+	if r == nil {
+		return nil
+	}
+	if r.Attempts < 0 {
+		return fmt.Errorf("bad retry policy: negative attempts %d", r.Attempts)
+	}
+	if _, err := r.BackoffDuration(); err != nil {
+		return fmt.Errorf("bad retry policy: %v", err)
+	}
+	return nil
+}
+
+// BackoffDuration returns the time waited before the first retry, none if
+// Backoff is empty
+func (r *RetryPolicy) BackoffDuration() (time.Duration, error) {
+	if r.Backoff == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(r.Backoff)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative backoff %s", r.Backoff)
+	}
+	return d, nil
+}
+
+func (r *RetryPolicy) String() string {
+	s := fmt.Sprintf("attempts=%d backoff=%s", r.Attempts, r.Backoff)
+	if r.Spool {
+		s += " spool"
+	}
+	return s
+}
@@ -0,0 +1,115 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wmap
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var retryJSON = `
+{
+    "collect": {
+        "metrics": {
+            "/intel/mock/*": {}
+        },
+        "publish": [
+            {
+                "plugin_name": "file",
+                "retry": {
+                    "attempts": 3,
+                    "backoff": "500ms",
+                    "spool": true
+                }
+            }
+        ]
+    }
+}
+`
+
+var retryYAML = `
+---
+  collect:
+    metrics:
+      /intel/mock/*: {}
+    publish:
+      -
+        plugin_name: "file"
+        retry:
+          attempts: 3
+          backoff: "500ms"
+          spool: true
+`
+
+func TestRetryFromWorkflow(t *testing.T) {
+	Convey("Retry policies of publish nodes", t, func() {
+		check := func(wf *WorkflowMap) {
+			So(wf.CollectNode.PublishNodes, ShouldHaveLength, 1)
+			r := wf.CollectNode.PublishNodes[0].Retry
+			So(r, ShouldNotBeNil)
+			So(r.Attempts, ShouldEqual, 3)
+			So(r.Backoff, ShouldEqual, "500ms")
+			So(r.Spool, ShouldBeTrue)
+		}
+		Convey("From JSON", func() {
+			wf, err := FromJson(retryJSON)
+			So(err, ShouldBeNil)
+			check(wf)
+		})
+		Convey("From YAML", func() {
+			wf, err := FromYaml(retryYAML)
+			So(err, ShouldBeNil)
+			check(wf)
+		})
+		Convey("Unknown keys are rejected", func() {
+			_, err := FromJson(`{"collect": {"metrics": {"/foo": {}}, "publish": [{"plugin_name": "file", "retry": {"retries": 3}}]}}`)
+			So(err, ShouldNotBeNil)
+		})
+		Convey("Nodes without a retry policy have none", func() {
+			wf, err := FromJson(`{"collect": {"metrics": {"/foo": {}}, "publish": [{"plugin_name": "file"}]}}`)
+			So(err, ShouldBeNil)
+			So(wf.CollectNode.PublishNodes[0].Retry, ShouldBeNil)
+		})
+	})
+}
+
+func TestRetryValidate(t *testing.T) {
+	Convey("RetryPolicy.Validate()", t, func() {
+		var r *RetryPolicy
+		So(r.Validate(), ShouldBeNil)
+		So((&RetryPolicy{Attempts: 2, Backoff: "1s", Spool: true}).Validate(), ShouldBeNil)
+		So((&RetryPolicy{Spool: true}).Validate(), ShouldBeNil)
+		So((&RetryPolicy{Attempts: -1}).Validate(), ShouldNotBeNil)
+		So((&RetryPolicy{Attempts: 1, Backoff: "soon"}).Validate(), ShouldNotBeNil)
+		So((&RetryPolicy{Attempts: 1, Backoff: "-1s"}).Validate(), ShouldNotBeNil)
+	})
+	Convey("RetryPolicy.BackoffDuration()", t, func() {
+		d, err := (&RetryPolicy{}).BackoffDuration()
+		So(err, ShouldBeNil)
+		So(d, ShouldEqual, 0)
+		d, err = (&RetryPolicy{Backoff: "250ms"}).BackoffDuration()
+		So(err, ShouldBeNil)
+		So(d, ShouldEqual, 250*time.Millisecond)
+	})
+}
@@ -70,6 +70,11 @@ func (c *CollectWorkflowMapNode) String(pad string) string {
 		out += pu.String(pad) + "\n"
 	}
 	out += "\n"
+	out += pad + "Transform Nodes:\n"
+	for _, tr := range c.TransformNodes {
+		out += tr.String(pad) + "\n"
+	}
+	out += "\n"
 	return out
 }
 
@@ -83,6 +88,9 @@ func (p *ProcessWorkflowMapNode) String(pad string) string {
 		out += pad + "      " + fmt.Sprintf("%s=%+v\n", k, v)
 	}
 	out += pad + "   Target:" + p.Target + "\n"
+	if p.Filter != nil {
+		out += pad + "   Filter: " + p.Filter.String() + "\n"
+	}
 
 	out += pad + "   Process Nodes:\n"
 	for _, pr := range p.ProcessNodes {
@@ -92,6 +100,10 @@ func (p *ProcessWorkflowMapNode) String(pad string) string {
 	for _, pu := range p.PublishNodes {
 		out += pu.String(pad + "   ")
 	}
+	out += pad + "   Transform Nodes:\n"
+	for _, tr := range p.TransformNodes {
+		out += tr.String(pad + "   ")
+	}
 	return out
 }
 
@@ -104,5 +116,36 @@ func (p *PublishWorkflowMapNode) String(pad string) string {
 	for k, v := range p.Config {
 		out += pad + "      " + fmt.Sprintf("%s=%+v\n", k, v)
 	}
+	if p.Filter != nil {
+		out += pad + "   Filter: " + p.Filter.String() + "\n"
+	}
+	if p.Retry != nil {
+		out += pad + "   Retry: " + p.Retry.String() + "\n"
+	}
+	if p.Batch != nil {
+		out += pad + "   Batch: " + p.Batch.String() + "\n"
+	}
+	return out
+}
+
+func (t *TransformWorkflowMapNode) String(pad string) string {
+	var out string
+	out += pad + "   Rules:\n"
+	for _, r := range t.Rules {
+		out += pad + "      " + r.String() + "\n"
+	}
+
+	out += pad + "   Process Nodes:\n"
+	for _, pr := range t.ProcessNodes {
+		out += pr.String(pad + "   ")
+	}
+	out += pad + "   Publish Nodes:\n"
+	for _, pu := range t.PublishNodes {
+		out += pu.String(pad + "   ")
+	}
+	out += pad + "   Transform Nodes:\n"
+	for _, tr := range t.TransformNodes {
+		out += tr.String(pad + "   ")
+	}
 	return out
 }
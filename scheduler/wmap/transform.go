@@ -0,0 +1,250 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wmap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The operations of the rules of a transform node
+const (
+	TransformSetTag   = "set-tag"
+	TransformDrop     = "drop"
+	TransformRename   = "rename"
+	TransformMultiply = "multiply"
+	TransformCast     = "cast"
+)
+
+// The types a cast rule converts the values of metrics to
+const (
+	CastInt    = "int"
+	CastFloat  = "float"
+	CastString = "string"
+)
+
+// TransformWorkflowMapNode is a node of the workflow transforming the metrics
+// of its parent node with a set of rules, in order. It runs within the
+// scheduler, without a processor plugin, and its metrics are handed to its
+// child nodes like those of a process node.
+type TransformWorkflowMapNode struct {
+	Rules          []TransformRule            `json:"rules"yaml:"rules"`
+	ProcessNodes   []ProcessWorkflowMapNode   `json:"process,omitempty"yaml:"process"`
+	PublishNodes   []PublishWorkflowMapNode   `json:"publish,omitempty"yaml:"publish"`
+	TransformNodes []TransformWorkflowMapNode `json:"transform,omitempty"yaml:"transform"`
+}
+
+func (tw *TransformWorkflowMapNode) UnmarshalJSON(data []byte) error {
+	t := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	for k, v := range t {
+		switch k {
+		case "rules":
+			if err := json.Unmarshal(v, &tw.Rules); err != nil {
+				return err
+			}
+		case "process":
+			if err := json.Unmarshal(v, &tw.ProcessNodes); err != nil {
+				return err
+			}
+		case "publish":
+			if err := json.Unmarshal(v, &tw.PublishNodes); err != nil {
+				return err
+			}
+		case "transform":
+			if err := json.Unmarshal(v, &tw.TransformNodes); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("Unrecognized key '%v' in transform workflow of task.", k)
+		}
+	}
+	return nil
+}
+
+func NewTransformNode(rules ...TransformRule) *TransformWorkflowMapNode {
+	return &TransformWorkflowMapNode{
+		Rules: rules,
+	}
+}
+
+func (t *TransformWorkflowMapNode) Add(node interface{}) error {
+	switch x := node.(type) {
+	case *ProcessWorkflowMapNode:
+		t.ProcessNodes = append(t.ProcessNodes, *x)
+	case *PublishWorkflowMapNode:
+		t.PublishNodes = append(t.PublishNodes, *x)
+	case *TransformWorkflowMapNode:
+		t.TransformNodes = append(t.TransformNodes, *x)
+	default:
+		return errors.New(fmt.Sprintf("cannot add workflow node type (%v) to transform node as child", x))
+	}
+	return nil
+}
+
+// Validate returns an error if the node has no rules or one of its rules is
+// malformed
+func (t *TransformWorkflowMapNode) Validate() error {
+	if len(t.Rules) == 0 {
+		return errors.New("transform node has no rules")
+	}
+	for i, r := range t.Rules {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("bad rule %d of transform node: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// TransformRule is a rule of a transform node. Namespace is a glob selecting
+// the metrics the rule applies to, as those of a Filter, every metric being
+// selected if it is empty. The other fields are the arguments of the
+// operation of the rule:
+//   - set-tag sets the tag Tag to Value
+//   - drop drops the metrics, and requires Namespace
+//   - rename replaces the namespace prefix From with To
+//   - multiply multiplies the numeric values by Factor, the values becoming
+//     floats
+//   - cast converts the values to Type, one of int, float or string
+type TransformRule struct {
+	Op        string  `json:"op"yaml:"op"`
+	Namespace string  `json:"namespace,omitempty"yaml:"namespace"`
+	Tag       string  `json:"tag,omitempty"yaml:"tag"`
+	Value     string  `json:"value,omitempty"yaml:"value"`
+	From      string  `json:"from,omitempty"yaml:"from"`
+	To        string  `json:"to,omitempty"yaml:"to"`
+	Factor    float64 `json:"factor,omitempty"yaml:"factor"`
+	Type      string  `json:"type,omitempty"yaml:"type"`
+}
+
+func (r *TransformRule) UnmarshalJSON(data []byte) error {
+	t := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	for k, v := range t {
+		var err error
+		switch k {
+		case "op":
+			err = json.Unmarshal(v, &r.Op)
+		case "namespace":
+			err = json.Unmarshal(v, &r.Namespace)
+		case "tag":
+			err = json.Unmarshal(v, &r.Tag)
+		case "value":
+			err = json.Unmarshal(v, &r.Value)
+		case "from":
+			err = json.Unmarshal(v, &r.From)
+		case "to":
+			err = json.Unmarshal(v, &r.To)
+		case "factor":
+			err = json.Unmarshal(v, &r.Factor)
+		case "type":
+			err = json.Unmarshal(v, &r.Type)
+		default:
+			return fmt.Errorf("Unrecognized key '%v' in rule of transform workflow of task.", k)
+		}
+		if err != nil {
+			return fmt.Errorf("%v (while parsing '%v')", err, k)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if the operation of the rule is unknown or its
+// arguments are missing or malformed
+func (r TransformRule) Validate() error {
+	if r.Namespace != "" {
+		if err := (&Filter{Include: []string{r.Namespace}}).Validate(); err != nil {
+			return err
+		}
+	}
+	switch r.Op {
+	case TransformSetTag:
+		if r.Tag == "" {
+			return fmt.Errorf("%s requires a tag", r.Op)
+		}
+	case TransformDrop:
+		if r.Namespace == "" {
+			return fmt.Errorf("%s requires a namespace", r.Op)
+		}
+	case TransformRename:
+		if !strings.HasPrefix(r.From, "/") || !strings.HasPrefix(r.To, "/") {
+			return fmt.Errorf("%s requires from and to namespaces starting with /", r.Op)
+		}
+		if len(NamespaceElements(r.From)) == 0 {
+			return fmt.Errorf("%s cannot rename the root namespace", r.Op)
+		}
+	case TransformMultiply:
+		if r.Factor == 0 {
+			return fmt.Errorf("%s requires a non-zero factor", r.Op)
+		}
+	case TransformCast:
+		switch r.Type {
+		case CastInt, CastFloat, CastString:
+		default:
+			return fmt.Errorf("%s to unknown type '%s'", r.Op, r.Type)
+		}
+	default:
+		return fmt.Errorf("unknown operation '%s'", r.Op)
+	}
+	return nil
+}
+
+// Selects returns whether the rule applies to a metric with the given
+// namespace
+func (r TransformRule) Selects(ns []string) bool {
+	if r.Namespace == "" {
+		return true
+	}
+	return matchNamespace(splitNamespace(r.Namespace), ns)
+}
+
+func (r TransformRule) String() string {
+	var args []string
+	if r.Namespace != "" {
+		args = append(args, "namespace="+r.Namespace)
+	}
+	switch r.Op {
+	case TransformSetTag:
+		args = append(args, fmt.Sprintf("%s=%s", r.Tag, r.Value))
+	case TransformRename:
+		args = append(args, fmt.Sprintf("%s->%s", r.From, r.To))
+	case TransformMultiply:
+		args = append(args, fmt.Sprintf("factor=%v", r.Factor))
+	case TransformCast:
+		args = append(args, "type="+r.Type)
+	}
+	return r.Op + " " + strings.Join(args, " ")
+}
+
+// NamespaceElements returns the elements of a namespace string, none for
+// the root namespace
+func NamespaceElements(ns string) []string {
+	ns = strings.Trim(ns, "/")
+	if ns == "" {
+		return nil
+	}
+	return strings.Split(ns, "/")
+}
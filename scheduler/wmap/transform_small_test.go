@@ -0,0 +1,153 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wmap
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var transformJSON = `
+{
+    "collect": {
+        "metrics": {
+            "/intel/mock/*": {}
+        },
+        "transform": [
+            {
+                "rules": [
+                    {"op": "set-tag", "tag": "dc", "value": "east"},
+                    {"op": "drop", "namespace": "/intel/mock/*/baz"},
+                    {"op": "rename", "from": "/intel/mock", "to": "/mock"},
+                    {"op": "multiply", "namespace": "/mock/foo", "factor": 0.001},
+                    {"op": "cast", "type": "float"}
+                ],
+                "publish": [
+                    {
+                        "plugin_name": "file"
+                    }
+                ]
+            }
+        ]
+    }
+}
+`
+
+var transformYAML = `
+---
+  collect:
+    metrics:
+      /intel/mock/*: {}
+    transform:
+      -
+        rules:
+          - op: set-tag
+            tag: dc
+            value: east
+          - op: drop
+            namespace: /intel/mock/*/baz
+          - op: rename
+            from: /intel/mock
+            to: /mock
+          - op: multiply
+            namespace: /mock/foo
+            factor: 0.001
+          - op: cast
+            type: float
+        publish:
+          -
+            plugin_name: "file"
+`
+
+func TestTransformFromWorkflow(t *testing.T) {
+	Convey("Transform nodes of a workflow", t, func() {
+		check := func(wf *WorkflowMap) {
+			So(wf.CollectNode.TransformNodes, ShouldHaveLength, 1)
+			tn := wf.CollectNode.TransformNodes[0]
+			So(tn.Validate(), ShouldBeNil)
+			So(tn.Rules, ShouldResemble, []TransformRule{
+				{Op: TransformSetTag, Tag: "dc", Value: "east"},
+				{Op: TransformDrop, Namespace: "/intel/mock/*/baz"},
+				{Op: TransformRename, From: "/intel/mock", To: "/mock"},
+				{Op: TransformMultiply, Namespace: "/mock/foo", Factor: 0.001},
+				{Op: TransformCast, Type: CastFloat},
+			})
+			So(tn.PublishNodes, ShouldHaveLength, 1)
+			So(tn.PublishNodes[0].Name, ShouldEqual, "file")
+		}
+		Convey("From JSON", func() {
+			wf, err := FromJson(transformJSON)
+			So(err, ShouldBeNil)
+			check(wf)
+		})
+		Convey("From YAML", func() {
+			wf, err := FromYaml(transformYAML)
+			So(err, ShouldBeNil)
+			check(wf)
+		})
+		Convey("Unknown keys are rejected", func() {
+			_, err := FromJson(`{"collect": {"metrics": {"/foo": {}}, "transform": [{"rules": [{"op": "drop", "ns": "/foo"}]}]}}`)
+			So(err, ShouldNotBeNil)
+			_, err = FromJson(`{"collect": {"metrics": {"/foo": {}}, "transform": [{"rule": []}]}}`)
+			So(err, ShouldNotBeNil)
+		})
+		Convey("Transform nodes are added to process and transform nodes", func() {
+			pr := NewProcessNode("passthru", 1)
+			tn := NewTransformNode(TransformRule{Op: TransformDrop, Namespace: "/foo"})
+			So(tn.Add(NewTransformNode()), ShouldBeNil)
+			So(pr.Add(tn), ShouldBeNil)
+			So(pr.TransformNodes, ShouldHaveLength, 1)
+			So(pr.TransformNodes[0].TransformNodes, ShouldHaveLength, 1)
+		})
+	})
+}
+
+func TestTransformRuleValidate(t *testing.T) {
+	Convey("TransformRule.Validate()", t, func() {
+		valid := []TransformRule{
+			{Op: TransformSetTag, Tag: "dc"},
+			{Op: TransformDrop, Namespace: "/intel/**"},
+			{Op: TransformRename, From: "/intel/mock", To: "/"},
+			{Op: TransformMultiply, Factor: -1},
+			{Op: TransformCast, Type: CastInt},
+			{Op: TransformCast, Type: CastString},
+		}
+		for _, r := range valid {
+			So(r.Validate(), ShouldBeNil)
+		}
+		invalid := []TransformRule{
+			{Op: "scale", Factor: 2},
+			{Op: TransformSetTag, Value: "east"},
+			{Op: TransformDrop},
+			{Op: TransformDrop, Namespace: "intel/mock"},
+			{Op: TransformRename, From: "/intel/mock"},
+			{Op: TransformRename, From: "/", To: "/mock"},
+			{Op: TransformMultiply},
+			{Op: TransformCast, Type: "bool"},
+		}
+		for _, r := range invalid {
+			So(r.Validate(), ShouldNotBeNil)
+		}
+		So((&TransformWorkflowMapNode{}).Validate(), ShouldNotBeNil)
+	})
+}
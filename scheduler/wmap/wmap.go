@@ -175,6 +175,8 @@ type CollectWorkflowMapNode struct {
 	Tags         map[string]map[string]string      `json:"tags,omitempty"yaml:"tags"`
 	ProcessNodes []ProcessWorkflowMapNode          `json:"process,omitempty"yaml:"process"`
 	PublishNodes []PublishWorkflowMapNode          `json:"publish,omitempty"yaml:"publish"`
+	// TransformNodes transform the metrics within the scheduler
+	TransformNodes []TransformWorkflowMapNode `json:"transform,omitempty"yaml:"transform"`
 }
 
 func (cw *CollectWorkflowMapNode) UnmarshalJSON(data []byte) error {
@@ -204,6 +206,10 @@ func (cw *CollectWorkflowMapNode) UnmarshalJSON(data []byte) error {
 			if err := json.Unmarshal(v, &cw.PublishNodes); err != nil {
 				return err
 			}
+		case "transform":
+			if err := json.Unmarshal(v, &cw.TransformNodes); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("Unrecognized key '%v' in collect workflow of task.", k)
 		}
@@ -262,6 +268,8 @@ func (c *CollectWorkflowMapNode) Add(node interface{}) error {
 		c.ProcessNodes = append(c.ProcessNodes, *x)
 	case *PublishWorkflowMapNode:
 		c.PublishNodes = append(c.PublishNodes, *x)
+	case *TransformWorkflowMapNode:
+		c.TransformNodes = append(c.TransformNodes, *x)
 	default:
 		return errors.New(fmt.Sprintf("cannot add workflow node type (%v) to collect node as child", x))
 	}
@@ -287,9 +295,13 @@ type ProcessWorkflowMapNode struct {
 	Version      int                      `json:"plugin_version"yaml:"plugin_version"`
 	ProcessNodes []ProcessWorkflowMapNode `json:"process,omitempty"yaml:"process"`
 	PublishNodes []PublishWorkflowMapNode `json:"publish,omitempty"yaml:"publish"`
+	// TransformNodes transform the metrics within the scheduler
+	TransformNodes []TransformWorkflowMapNode `json:"transform,omitempty"yaml:"transform"`
 	// TODO processor config
 	Config map[string]interface{} `json:"config,omitempty"yaml:"config"`
 	Target string                 `json:"target"yaml:"target"`
+	// Filter selects the metrics of the parent node the plugin receives
+	Filter *Filter `json:"filter,omitempty"yaml:"filter"`
 }
 
 func (pw *ProcessWorkflowMapNode) UnmarshalJSON(data []byte) error {
@@ -315,6 +327,10 @@ func (pw *ProcessWorkflowMapNode) UnmarshalJSON(data []byte) error {
 			if err := json.Unmarshal(v, &pw.PublishNodes); err != nil {
 				return err
 			}
+		case "transform":
+			if err := json.Unmarshal(v, &pw.TransformNodes); err != nil {
+				return err
+			}
 		case "config":
 			if err := json.Unmarshal(v, &pw.Config); err != nil {
 				return fmt.Errorf("%v (while parsing 'config')", err)
@@ -323,6 +339,10 @@ func (pw *ProcessWorkflowMapNode) UnmarshalJSON(data []byte) error {
 			if err := json.Unmarshal(v, &pw.Target); err != nil {
 				return fmt.Errorf("%v (while parsing 'target')", err)
 			}
+		case "filter":
+			if err := json.Unmarshal(v, &pw.Filter); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("Unrecognized key '%v' in process workflow of task.", k)
 		}
@@ -345,6 +365,8 @@ func (p *ProcessWorkflowMapNode) Add(node interface{}) error {
 		p.ProcessNodes = append(p.ProcessNodes, *x)
 	case *PublishWorkflowMapNode:
 		p.PublishNodes = append(p.PublishNodes, *x)
+	case *TransformWorkflowMapNode:
+		p.TransformNodes = append(p.TransformNodes, *x)
 	default:
 		return errors.New(fmt.Sprintf("cannot add workflow node type (%v) to process node as child", x))
 	}
@@ -371,6 +393,12 @@ type PublishWorkflowMapNode struct {
 	// TODO publisher config
 	Config map[string]interface{} `json:"config,omitempty"yaml:"config"`
 	Target string                 `json:"target"yaml:"target"`
+	// Filter selects the metrics of the parent node the plugin receives
+	Filter *Filter `json:"filter,omitempty"yaml:"filter"`
+	// Retry is the policy applied when the plugin fails to publish
+	Retry *RetryPolicy `json:"retry,omitempty"yaml:"retry"`
+	// Batch accumulates the metrics of several runs before publishing them
+	Batch *BatchPolicy `json:"batch,omitempty"yaml:"batch"`
 }
 
 func (pw *PublishWorkflowMapNode) UnmarshalJSON(data []byte) error {
@@ -396,6 +424,18 @@ func (pw *PublishWorkflowMapNode) UnmarshalJSON(data []byte) error {
 			if err := json.Unmarshal(v, &pw.Target); err != nil {
 				return fmt.Errorf("%v (while parsing 'target')", err)
 			}
+		case "filter":
+			if err := json.Unmarshal(v, &pw.Filter); err != nil {
+				return err
+			}
+		case "retry":
+			if err := json.Unmarshal(v, &pw.Retry); err != nil {
+				return err
+			}
+		case "batch":
+			if err := json.Unmarshal(v, &pw.Batch); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("Unrecognized key '%v' in publish workflow of task.", k)
 		}
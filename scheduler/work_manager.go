@@ -19,7 +19,12 @@ limitations under the License.
 
 package scheduler
 
-import "sync"
+import (
+	"sync"
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
+)
 
 /*
 
@@ -56,11 +61,19 @@ type workManager struct {
 	collectWkrSize uint
 	publishWkrSize uint
 	processWkrSize uint
+	priorityAging  time.Duration
 	collectchan    chan queuedJob
 	publishchan    chan queuedJob
 	processchan    chan queuedJob
 	kill           chan struct{}
 	mutex          *sync.Mutex
+
+	// the overflow policies of the queues and the time the block
+	// policy waits for room in a full queue
+	collectOverflow string
+	publishOverflow string
+	processOverflow string
+	overflowTimeout time.Duration
 }
 
 type workManagerState int
@@ -135,6 +148,58 @@ func PublishWkrSizeOption(v uint) workManagerOption {
 	}
 }
 
+// PriorityAgingOption sets the time a job waits in a queue for each level
+// its priority is raised by, and returns the previous priority aging state.
+// Jobs never age if it is 0.
+func PriorityAgingOption(v time.Duration) workManagerOption {
+	return func(w *workManager) workManagerOption {
+		previous := w.priorityAging
+		w.priorityAging = v
+		return PriorityAgingOption(previous)
+	}
+}
+
+// CollectOverflowOption sets the overflow policy of the collector queue and
+// returns the previous collector overflow policy state.
+func CollectOverflowOption(v string) workManagerOption {
+	return func(w *workManager) workManagerOption {
+		previous := w.collectOverflow
+		w.collectOverflow = v
+		return CollectOverflowOption(previous)
+	}
+}
+
+// ProcessOverflowOption sets the overflow policy of the processor queue and
+// returns the previous processor overflow policy state.
+func ProcessOverflowOption(v string) workManagerOption {
+	return func(w *workManager) workManagerOption {
+		previous := w.processOverflow
+		w.processOverflow = v
+		return ProcessOverflowOption(previous)
+	}
+}
+
+// PublishOverflowOption sets the overflow policy of the publisher queue and
+// returns the previous publisher overflow policy state.
+func PublishOverflowOption(v string) workManagerOption {
+	return func(w *workManager) workManagerOption {
+		previous := w.publishOverflow
+		w.publishOverflow = v
+		return PublishOverflowOption(previous)
+	}
+}
+
+// OverflowTimeoutOption sets the time a job arriving at a full queue with the
+// block overflow policy waits for room, and returns the previous overflow
+// timeout state.
+func OverflowTimeoutOption(v time.Duration) workManagerOption {
+	return func(w *workManager) workManagerOption {
+		previous := w.overflowTimeout
+		w.overflowTimeout = v
+		return OverflowTimeoutOption(previous)
+	}
+}
+
 func newWorkManager(opts ...workManagerOption) *workManager {
 
 	wm := &workManager{
@@ -144,6 +209,7 @@ func newWorkManager(opts ...workManagerOption) *workManager {
 		collectWkrSize: defaultWkrSize,
 		publishWkrSize: defaultWkrSize,
 		processWkrSize: defaultWkrSize,
+		priorityAging:  defaultPriorityAging,
 		collectchan:    make(chan queuedJob),
 		publishchan:    make(chan queuedJob),
 		processchan:    make(chan queuedJob),
@@ -159,6 +225,15 @@ func newWorkManager(opts ...workManagerOption) *workManager {
 	wm.collectq = newQueue(wm.collectQSize, wm.sendToWorker)
 	wm.publishq = newQueue(wm.publishQSize, wm.sendToWorker)
 	wm.processq = newQueue(wm.processQSize, wm.sendToWorker)
+	for q, overflow := range map[*queue]string{
+		wm.collectq: wm.collectOverflow,
+		wm.publishq: wm.publishOverflow,
+		wm.processq: wm.processOverflow,
+	} {
+		q.aging = wm.priorityAging
+		q.overflow = overflow
+		q.overflowTimeout = wm.overflowTimeout
+	}
 
 	wm.publishq.Start()
 	wm.collectq.Start()
@@ -216,11 +291,13 @@ func (w *workManager) Stop() {
 }
 
 // Work dispatches jobs to worker pools for processing.
+// The jobs waiting in a queue are worked in the order of the
+// priority of their task.
 //
 // Returns a queued job to the caller, which will be
 // completed by the work queue aubsystem.
 func (w *workManager) Work(j job) queuedJob {
-	qj := newQueuedJob(j)
+	qj := newPrioritizedJob(j, j.Priority())
 	switch j.Type() {
 	case collectJobType:
 		w.collectq.Event <- qj
@@ -232,9 +309,79 @@ func (w *workManager) Work(j job) queuedJob {
 	return qj
 }
 
+// QueueStats returns the depth of the collect, process and
+// publish queues and the utilization of their worker pools.
+func (w *workManager) QueueStats() map[string]core.WorkQueueStats {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return map[string]core.WorkQueueStats{
+		"collect": poolStats(w.collectq, w.collectWkrs),
+		"process": poolStats(w.processq, w.processWkrs),
+		"publish": poolStats(w.publishq, w.publishWkrs),
+	}
+}
+
+// Resize sets the limit of the queue of the given job type and the size of
+// the worker pool working it, while the work manager runs. The workers
+// removed from a pool which shrinks complete the job they are running
+// before they stop.
+func (w *workManager) Resize(t jobType, qSize, wkrSize uint) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	switch t {
+	case collectJobType:
+		w.collectq.setLimit(qSize)
+		w.collectQSize = qSize
+		w.collectWkrs = resizePool(w.collectWkrs, w.collectchan, wkrSize)
+		w.collectWkrSize = wkrSize
+	case processJobType:
+		w.processq.setLimit(qSize)
+		w.processQSize = qSize
+		w.processWkrs = resizePool(w.processWkrs, w.processchan, wkrSize)
+		w.processWkrSize = wkrSize
+	case publishJobType:
+		w.publishq.setLimit(qSize)
+		w.publishQSize = qSize
+		w.publishWkrs = resizePool(w.publishWkrs, w.publishchan, wkrSize)
+		w.publishWkrSize = wkrSize
+	}
+}
+
+// resizePool starts or stops workers until the pool has the given size.
+func resizePool(wkrs []*worker, rChan <-chan queuedJob, size uint) []*worker {
+	for uint(len(wkrs)) < size {
+		nw := newWorker(rChan)
+		go nw.start()
+		wkrs = append(wkrs, nw)
+	}
+	for uint(len(wkrs)) > size {
+		close(wkrs[len(wkrs)-1].kamikaze)
+		wkrs = wkrs[:len(wkrs)-1]
+	}
+	return wkrs
+}
+
+// poolStats returns the depth of a queue and the utilization of the worker
+// pool working it.
+func poolStats(q *queue, wkrs []*worker) core.WorkQueueStats {
+	st := q.stats()
+	st.Workers = uint(len(wkrs))
+	for _, wk := range wkrs {
+		if wk.isBusy() {
+			st.BusyWorkers++
+		}
+	}
+	return st
+}
+
 // AddCollectWorker adds a new worker to
 // the collector worker pool
 func (w *workManager) AddCollectWorker() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
 	nw := newWorker(w.collectchan)
 	go nw.start()
 	w.collectWkrs = append(w.collectWkrs, nw)
@@ -244,6 +391,9 @@ func (w *workManager) AddCollectWorker() {
 // AddPublishWorker adds a new worker to
 // the publisher worker pool
 func (w *workManager) AddPublishWorker() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
 	nw := newWorker(w.publishchan)
 	go nw.start()
 	w.publishWkrs = append(w.publishWkrs, nw)
@@ -253,6 +403,9 @@ func (w *workManager) AddPublishWorker() {
 // AddProcessWorker adds a new worker to
 // the processor worker pool
 func (w *workManager) AddProcessWorker() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
 	nw := newWorker(w.processchan)
 	go nw.start()
 	w.processWkrs = append(w.processWkrs, nw)
@@ -42,6 +42,7 @@ type mockJob struct {
 	completePromise Promise
 	numSyncs        int
 	rvs             []RendezVous
+	priority        core.TaskPriority
 }
 
 // Create an asynchronous mockJob.
@@ -87,6 +88,8 @@ func (mj *mockJob) Type() jobType        { return collectJobType }
 func (mj *mockJob) TypeString() string   { return "" }
 func (mj *mockJob) TaskID() string       { return "" }
 
+func (mj *mockJob) Priority() core.TaskPriority { return mj.priority }
+
 // Complete the first incomplete rendez-vous (if there is one)
 func (mj *mockJob) RendezVous() {
 	mj.Lock()
@@ -251,4 +254,39 @@ func TestWorkerManager(t *testing.T) {
 			So(mgr.collectWkrSize, ShouldEqual, len(mgr.collectWkrs))
 		})
 	})
+	Convey("Resize()", t, func() {
+		Convey("it grows and shrinks a worker pool and sets the queue limit", func() {
+			mgr := newWorkManager()
+			mgr.Resize(collectJobType, 10, 3)
+			So(mgr.collectq.limit, ShouldEqual, 10)
+			So(len(mgr.collectWkrs), ShouldEqual, 3)
+			So(mgr.QueueStats()["collect"].Workers, ShouldEqual, 3)
+
+			removed := mgr.collectWkrs[1:]
+			mgr.Resize(collectJobType, 2, 1)
+			So(mgr.collectq.limit, ShouldEqual, 2)
+			So(len(mgr.collectWkrs), ShouldEqual, 1)
+			So(mgr.collectWkrSize, ShouldEqual, 1)
+			for _, wk := range removed {
+				_, open := <-wk.kamikaze
+				So(open, ShouldBeFalse)
+			}
+			So(mgr.QueueStats()["publish"].Workers, ShouldEqual, defaultWkrSize)
+		})
+		Convey("it reports the busy workers and completes the jobs of removed workers", func() {
+			// Stop() closed the broadcast killing all the workers
+			workerKillChan = make(chan struct{})
+			mgr := newWorkManager(CollectWkrSizeOption(2))
+			j := newMultiSyncMockJob(2)
+			qj := mgr.Work(j)
+			j.RendezVous() // j is running
+			So(mgr.QueueStats()["collect"].BusyWorkers, ShouldEqual, 1)
+
+			mgr.Resize(collectJobType, defaultQSize, 1)
+			j.RendezVous()
+			So(qj.Promise().Await(), ShouldBeEmpty)
+			So(j.worked, ShouldBeTrue)
+			So(mgr.QueueStats()["collect"].BusyWorkers, ShouldEqual, 0)
+		})
+	})
 }
@@ -21,6 +21,7 @@ package scheduler
 
 import (
 	"errors"
+	"sync/atomic"
 
 	"github.com/intelsdi-x/snap/pkg/chrono"
 	"github.com/pborman/uuid"
@@ -32,6 +33,8 @@ type worker struct {
 	id       string
 	rcv      <-chan queuedJob
 	kamikaze chan struct{}
+	// busy is set while the worker runs a job
+	busy int32
 }
 
 func newWorker(rChan <-chan queuedJob) *worker {
@@ -49,7 +52,9 @@ func (w *worker) start() {
 		case q := <-w.rcv:
 			// assert that deadline is not exceeded
 			if chrono.Chrono.Now().Before(q.Job().Deadline()) {
+				atomic.StoreInt32(&w.busy, 1)
 				q.Job().Run()
+				atomic.StoreInt32(&w.busy, 0)
 			} else {
 				// the deadline was exceeded and this job will not run
 				q.Job().AddErrors(errors.New("Worker refused to run overdue job."))
@@ -58,7 +63,9 @@ func (w *worker) start() {
 			// mark the job complete
 			q.Promise().Complete(q.Job().Errors())
 
-		// the single kill-channel -- used when resizing worker pools
+		// the single kill-channel -- used when resizing worker pools.
+		// it is only received between jobs, so a worker removed from
+		// its pool always completes the job it is running.
 		case <-w.kamikaze:
 			return
 
@@ -68,3 +75,8 @@ func (w *worker) start() {
 		}
 	}
 }
+
+// isBusy returns whether the worker is running a job
+func (w *worker) isBusy() bool {
+	return atomic.LoadInt32(&w.busy) == 1
+}
@@ -21,8 +21,11 @@ package scheduler
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/intelsdi-x/gomit"
@@ -30,6 +33,7 @@ import (
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/cdata"
 	"github.com/intelsdi-x/snap/core/scheduler_event"
+	"github.com/intelsdi-x/snap/core/serror"
 	"github.com/intelsdi-x/snap/scheduler/wmap"
 )
 
@@ -102,7 +106,12 @@ func convertCollectionNode(cnode *wmap.CollectWorkflowMapNode, wf *schedulerWork
 	if err != nil {
 		return err
 	}
-	wf.processNodes = pr
+	// Transform nodes are walked along with the process nodes
+	tr, err := convertTransformNode(cnode.TransformNodes)
+	if err != nil {
+		return err
+	}
+	wf.processNodes = append(pr, tr...)
 	// Iterate over first level publish nodes
 	pu, err := convertPublishNode(cnode.PublishNodes)
 	if err != nil {
@@ -127,6 +136,11 @@ func convertProcessNode(pr []wmap.ProcessWorkflowMapNode) ([]*processNode, error
 		if err != nil {
 			return nil, err
 		}
+		trC, err := convertTransformNode(p.TransformNodes)
+		if err != nil {
+			return nil, err
+		}
+		prC = append(prC, trC...)
 
 		// If version is not 1+ we use -1 to indicate we want
 		// the plugin manager to select the highest version
@@ -134,12 +148,16 @@ func convertProcessNode(pr []wmap.ProcessWorkflowMapNode) ([]*processNode, error
 		if p.Version < 1 {
 			p.Version = -1
 		}
+		if err := p.Filter.Validate(); err != nil {
+			return nil, err
+		}
 		p.Name = strings.ToLower(p.Name)
 		prNodes[i] = &processNode{
 			name:         p.Name,
 			version:      p.Version,
 			config:       cdn,
 			Target:       p.Target,
+			filter:       p.Filter,
 			ProcessNodes: prC,
 			PublishNodes: puC,
 		}
@@ -161,19 +179,65 @@ func convertPublishNode(pu []wmap.PublishWorkflowMapNode) ([]*publishNode, error
 		if p.Version < 1 {
 			p.Version = -1
 		}
+		if err := p.Filter.Validate(); err != nil {
+			return nil, err
+		}
+		if err := p.Retry.Validate(); err != nil {
+			return nil, err
+		}
+		if err := p.Batch.Validate(); err != nil {
+			return nil, err
+		}
 		p.Name = strings.ToLower(p.Name)
 		puNodes[i] = &publishNode{
 			name:    p.Name,
 			version: p.Version,
 			config:  cdn,
 			Target:  p.Target,
+			filter:  p.Filter,
+			retry:   p.Retry,
+		}
+		if p.Batch != nil {
+			puNodes[i].batch = newPublishBatch(p.Batch)
 		}
 	}
 	return puNodes, nil
 }
 
+// convertTransformNode converts transform nodes to process nodes run within
+// the scheduler rather than by a processor plugin
+func convertTransformNode(tr []wmap.TransformWorkflowMapNode) ([]*processNode, error) {
+	trNodes := make([]*processNode, len(tr))
+	for i, t := range tr {
+		if err := t.Validate(); err != nil {
+			return nil, err
+		}
+		prC, err := convertProcessNode(t.ProcessNodes)
+		if err != nil {
+			return nil, err
+		}
+		puC, err := convertPublishNode(t.PublishNodes)
+		if err != nil {
+			return nil, err
+		}
+		trC, err := convertTransformNode(t.TransformNodes)
+		if err != nil {
+			return nil, err
+		}
+		trNodes[i] = &processNode{
+			config:       cdata.NewNode(),
+			transform:    t.Rules,
+			ProcessNodes: append(prC, trC...),
+			PublishNodes: puC,
+		}
+	}
+	return trNodes, nil
+}
+
 type schedulerWorkflow struct {
-	state WorkflowState
+	// state is a WorkflowState, accessed atomically as runs of the workflow
+	// may start concurrently
+	state int32
 	// Metrics to collect
 	metrics []core.RequestedMetric
 	// The config data tree for collectors
@@ -191,9 +255,13 @@ type processNode struct {
 	version            int
 	config             *cdata.ConfigDataNode
 	Target             string
+	filter             *wmap.Filter
 	ProcessNodes       []*processNode
 	PublishNodes       []*publishNode
 	InboundContentType string
+	// transform holds the rules of a transform node, which is run within
+	// the scheduler instead of by a processor plugin
+	transform []wmap.TransformRule
 }
 
 func (p *processNode) Name() string {
@@ -209,6 +277,9 @@ func (p *processNode) Config() *cdata.ConfigDataNode {
 }
 
 func (p *processNode) TypeName() string {
+	if p.transform != nil {
+		return "transform"
+	}
 	return "processor"
 }
 
@@ -217,7 +288,13 @@ type publishNode struct {
 	version            int
 	config             *cdata.ConfigDataNode
 	Target             string
+	filter             *wmap.Filter
 	InboundContentType string
+	// retry is the policy applied when the plugin fails to publish
+	retry *wmap.RetryPolicy
+	// batch accumulates the metrics of the runs of the task, nil if the
+	// node publishes the metrics of each run
+	batch *publishBatch
 }
 
 func (p *publishNode) Name() string {
@@ -238,27 +315,79 @@ func (p *publishNode) TypeName() string {
 
 type wfContentTypes map[string]map[string][]string
 
-// Start starts a workflow
-func (s *schedulerWorkflow) Start(t *task) {
+// Start starts a workflow and returns the record of the run
+func (s *schedulerWorkflow) Start(t *task) core.TaskRun {
+	t.Lock()
+	fireTime := t.lastFireTime
+	t.Unlock()
+	return s.start(t, newTaskRun(fireTime))
+}
+
+// start runs the workflow, recording the run in the given record, and
+// returns the record once the run completes. Several runs of a workflow may
+// be in progress at a time, each with its own record.
+func (s *schedulerWorkflow) start(t *task, tr *taskRun) (run core.TaskRun) {
 	workflowLogger.WithFields(log.Fields{
 		"_block":    "workflow-start",
 		"task-id":   t.id,
 		"task-name": t.name,
 	}).Debug("Starting workflow")
-	s.state = WorkflowStarted
+	atomic.StoreInt32(&s.state, int32(WorkflowStarted))
+	defer func() {
+		run = t.recordRun(tr)
+		if t.recordLatency(run) {
+			workflowLogger.WithFields(log.Fields{
+				"_block":      "workflow-start",
+				"task-id":     t.id,
+				"task-name":   t.name,
+				"duration":    run.Duration.String(),
+				"latency-slo": t.latencySLO.String(),
+			}).Warn("Task run breached its latency objective")
+			s.eventEmitter.Emit(&scheduler_event.LatencySLOBreachedEvent{
+				TaskID:  t.id,
+				Latency: run.Duration,
+				SLO:     t.latencySLO,
+			})
+		}
+		s.eventEmitter.Emit(&scheduler_event.WorkflowCompletedEvent{
+			TaskID: t.id,
+			Failed: run.Failed,
+		})
+	}()
 	j := newCollectorJob(s.metrics, t.deadlineDuration, t.metricsManager, t.workflow.configTree, t.id, s.tags)
+	if tr.run.CatchUp {
+		j.(*collectorJob).catchupTime = tr.run.FireTime
+	}
+	j.(*collectorJob).priority = t.priority
 
 	// dispatch 'collect' job to be worked
 	// Block until the job has been either run or skipped.
+	submitted := time.Now()
 	errors := t.manager.Work(j).Promise().Await()
+	t.recordJob(tr, j.TypeString(), j.Name(), j.Version(), submitted, len(j.Metrics()), errors)
 
 	if len(errors) > 0 {
-		t.RecordFailure(errors)
+		pluginErrors, pluginsOnly := collectorPluginErrors(errors)
 		event := new(scheduler_event.MetricCollectionFailedEvent)
 		event.TaskID = t.id
 		event.Errors = errors
+		event.PluginErrors = pluginErrors
+		// With the best-effort collect policy the failure of some of the
+		// collector plugins does not fail the task as long as the others
+		// returned metrics.
+		if t.GetCollectPolicy() != core.CollectBestEffort || !pluginsOnly || len(j.(*collectorJob).metrics) == 0 {
+			t.recordFailure(tr, errors)
+			defer s.eventEmitter.Emit(event)
+			return
+		}
+		workflowLogger.WithFields(log.Fields{
+			"_block":         "workflow-start",
+			"task-id":        t.id,
+			"task-name":      t.name,
+			"failed-plugins": len(pluginErrors),
+		}).Warn("Some collector plugins failed, continuing with the metrics collected")
+		event.Partial = true
 		defer s.eventEmitter.Emit(event)
-		return
 	}
 
 	// Send event
@@ -268,20 +397,44 @@ func (s *schedulerWorkflow) Start(t *task) {
 	defer s.eventEmitter.Emit(event)
 
 	// walk through the tree and dispatch work
-	workJobs(s.processNodes, s.publishNodes, t, j)
+	workJobs(s.processNodes, s.publishNodes, t, tr, j)
+	return
+}
+
+// collectorPluginErrors groups the errors of a collect job by the collector
+// plugin which returned them. The returned bool is false if any of the errors
+// is not attributed to a plugin.
+func collectorPluginErrors(errs []error) (map[string][]error, bool) {
+	pluginErrors := map[string][]error{}
+	pluginsOnly := true
+	for _, e := range errs {
+		serr, ok := e.(serror.SnapError)
+		if !ok {
+			pluginsOnly = false
+			continue
+		}
+		name, ok := serr.Fields()["plugin-name"]
+		if !ok {
+			pluginsOnly = false
+			continue
+		}
+		key := fmt.Sprintf("%v:%v", name, serr.Fields()["plugin-version"])
+		pluginErrors[key] = append(pluginErrors[key], e)
+	}
+	return pluginErrors, pluginsOnly
 }
 
 func (s *schedulerWorkflow) State() WorkflowState {
-	return s.state
+	return WorkflowState(atomic.LoadInt32(&s.state))
 }
 
 func (s *schedulerWorkflow) StateString() string {
-	return WorkflowStateLookup[s.state]
+	return WorkflowStateLookup[s.State()]
 }
 
 // workJobs takes a slice of process and publish nodes and submits jobs for each for a task.
 // It then iterates down any process nodes to submit their child node jobs for the task
-func workJobs(prs []*processNode, pus []*publishNode, t *task, pj job) {
+func workJobs(prs []*processNode, pus []*publishNode, t *task, tr *taskRun, pj job) {
 	// optimize for no jobs
 	if len(prs) == 0 && len(pus) == 0 {
 		return
@@ -301,14 +454,14 @@ func workJobs(prs []*processNode, pus []*publishNode, t *task, pj job) {
 		// increment the wait group (before starting goroutine to prevent a race condition)
 		wg.Add(1)
 		// Start goroutine to submit the process job
-		go submitProcessJob(pj, t, wg, pr)
+		go submitProcessJob(pj, t, tr, wg, pr)
 	}
 	// range over the publish jobs and call submitPublishJob
 	for _, pu := range pus {
 		// increment the wait group (before starting goroutine to prevent a race condition)
 		wg.Add(1)
 		// Start goroutine to submit the process job
-		go submitPublishJob(pj, t, wg, pu)
+		go submitPublishJob(pj, t, tr, wg, pu)
 	}
 	// Wait until all job submisson goroutines are done
 	wg.Wait()
@@ -322,13 +475,18 @@ func workJobs(prs []*processNode, pus []*publishNode, t *task, pj job) {
 	}).Debug("Batch submission complete")
 }
 
-func submitProcessJob(pj job, t *task, wg *sync.WaitGroup, pr *processNode) {
+func submitProcessJob(pj job, t *task, tr *taskRun, wg *sync.WaitGroup, pr *processNode) {
 	// Decrement the waitgroup
 	defer wg.Done()
+	if pr.transform != nil {
+		runTransformJob(pj, t, tr, pr)
+		return
+	}
 	// Create a new process job
 	mgr, err := t.RemoteManagers.Get(pr.Target)
 	if err != nil {
-		t.RecordFailure([]error{err})
+		t.recordFailure(tr, []error{err})
+		t.recordJob(tr, pr.TypeName(), pr.Name(), pr.Version(), time.Now(), 0, []error{err})
 		workflowLogger.WithFields(log.Fields{
 			"_block":           "submit-prblish-job",
 			"task-id":          t.id,
@@ -340,6 +498,7 @@ func submitProcessJob(pj job, t *task, wg *sync.WaitGroup, pr *processNode) {
 		return
 	}
 	j := newProcessJob(pj, pr.Name(), pr.Version(), pr.InboundContentType, pr.config.Table(), mgr, t.id)
+	j.(*processJob).filter = pr.filter
 	workflowLogger.WithFields(log.Fields{
 		"_block":           "submit-process-job",
 		"task-id":          t.id,
@@ -349,12 +508,14 @@ func submitProcessJob(pj job, t *task, wg *sync.WaitGroup, pr *processNode) {
 		"parent-node-type": pj.TypeString(),
 	}).Debug("Submitting process job")
 	// Submit the job against the task.managesWork
+	submitted := time.Now()
 	errors := t.manager.Work(j).Promise().Await()
+	t.recordJob(tr, j.TypeString(), j.Name(), j.Version(), submitted, len(j.Metrics()), errors)
 	// Check for errors and update the task
 	if len(errors) != 0 {
 		// Record the failures in the task
 		// note: this function is thread safe against t
-		t.RecordFailure(errors)
+		t.recordFailure(tr, errors)
 		workflowLogger.WithFields(log.Fields{
 			"_block":           "submit-process-job",
 			"task-id":          t.id,
@@ -374,16 +535,39 @@ func submitProcessJob(pj job, t *task, wg *sync.WaitGroup, pr *processNode) {
 		"parent-node-type": pj.TypeString(),
 	}).Debug("Process job completed")
 	// Iterate into any child process or publish nodes
-	workJobs(pr.ProcessNodes, pr.PublishNodes, t, j)
+	workJobs(pr.ProcessNodes, pr.PublishNodes, t, tr, j)
+}
+
+// runTransformJob runs the rules of a transform node against the metrics of
+// its parent job, then iterates into its child nodes. The job is not queued,
+// as it needs no plugin.
+func runTransformJob(pj job, t *task, tr *taskRun, pr *processNode) {
+	j := newTransformJob(pj, pr.transform, t.id)
+	started := time.Now()
+	j.Run()
+	errors := j.Errors()
+	t.recordJob(tr, j.TypeString(), j.Name(), j.Version(), started, len(j.Metrics()), errors)
+	if len(errors) != 0 {
+		t.recordFailure(tr, errors)
+		workflowLogger.WithFields(log.Fields{
+			"_block":           "run-transform-job",
+			"task-id":          t.id,
+			"task-name":        t.name,
+			"parent-node-type": pj.TypeString(),
+		}).Warn("Transform job failed")
+		return
+	}
+	workJobs(pr.ProcessNodes, pr.PublishNodes, t, tr, j)
 }
 
-func submitPublishJob(pj job, t *task, wg *sync.WaitGroup, pu *publishNode) {
+func submitPublishJob(pj job, t *task, tr *taskRun, wg *sync.WaitGroup, pu *publishNode) {
 	// Decrement the waitgroup
 	defer wg.Done()
 	// Create a new process job
 	mgr, err := t.RemoteManagers.Get(pu.Target)
 	if err != nil {
-		t.RecordFailure([]error{err})
+		t.recordFailure(tr, []error{err})
+		t.recordJob(tr, pu.TypeName(), pu.Name(), pu.Version(), time.Now(), 0, []error{err})
 		workflowLogger.WithFields(log.Fields{
 			"_block":           "submit-publish-job",
 			"task-id":          t.id,
@@ -394,7 +578,30 @@ func submitPublishJob(pj job, t *task, wg *sync.WaitGroup, pu *publishNode) {
 		}).Warn("Error getting control instance")
 		return
 	}
-	j := newPublishJob(pj, pu.Name(), pu.Version(), pu.InboundContentType, pu.config.Table(), mgr, t.id)
+	// A batching node publishes the metrics of several runs at once, in the
+	// run filling its batch
+	if pu.batch != nil {
+		mts := pu.batch.add(filterMetrics(pu.filter, pj.Metrics()), func() { t.flushBatch(pu) })
+		if mts == nil {
+			workflowLogger.WithFields(log.Fields{
+				"_block":          "submit-publish-job",
+				"task-id":         t.id,
+				"task-name":       t.name,
+				"publish-name":    pu.Name(),
+				"publish-version": pu.Version(),
+			}).Debug("Metrics added to the batch of the publish node")
+			return
+		}
+		pj = newBatchedJob(t, mts)
+	}
+	publishMetrics(pj, t, tr, pu, mgr)
+}
+
+// publishMetrics submits a publish job for the metrics of the parent job and
+// waits for it to complete, retrying it and spooling the metrics as the
+// retry policy of the node sets.
+func publishMetrics(pj job, t *task, tr *taskRun, pu *publishNode, mgr managesMetrics) {
+	j := newNodePublishJob(pj, t, pu, mgr, pj.Deadline())
 	workflowLogger.WithFields(log.Fields{
 		"_block":           "submit-publish-job",
 		"task-id":          t.id,
@@ -404,12 +611,60 @@ func submitPublishJob(pj job, t *task, wg *sync.WaitGroup, pu *publishNode) {
 		"parent-node-type": pj.TypeString(),
 	}).Debug("Submitting publish job")
 	// Submit the job against the task.managesWork
+	submitted := time.Now()
 	errors := t.manager.Work(j).Promise().Await()
+	// Retry the failed publish according to the retry policy of the node,
+	// backing off a little more before each attempt. The backoff outlasts
+	// the deadline of the run, so each attempt is given a deadline of its
+	// own. The retries are given up once the task is stopped.
+	if pu.retry != nil {
+		backoff, _ := pu.retry.BackoffDuration()
+		stop := t.stopChan()
+	retry:
+		for attempt := 1; len(errors) != 0 && attempt <= pu.retry.Attempts; attempt++ {
+			logger := workflowLogger.WithFields(log.Fields{
+				"_block":          "submit-publish-job",
+				"task-id":         t.id,
+				"task-name":       t.name,
+				"publish-name":    pu.Name(),
+				"publish-version": pu.Version(),
+				"attempt":         attempt,
+				"backoff":         backoff.String(),
+			})
+			logger.Debug("Retrying publish job")
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-stop:
+				timer.Stop()
+				logger.Debug("Task stopped, giving up retrying publish job")
+				break retry
+			}
+			backoff *= 2
+			j = newNodePublishJob(pj, t, pu, mgr, time.Now().Add(t.deadlineDuration))
+			errors = t.manager.Work(j).Promise().Await()
+		}
+	}
+	mts := filterMetrics(pu.filter, pj.Metrics())
+	t.recordJob(tr, j.TypeString(), j.Name(), j.Version(), submitted, len(mts), errors)
 	// Check for errors and update the task
 	if len(errors) != 0 {
+		// The metrics written to the spool are not lost, so the failure is
+		// not recorded against the task
+		if pu.retry != nil && pu.retry.Spool && t.spoolMetrics(pu, mts) {
+			workflowLogger.WithFields(log.Fields{
+				"_block":           "submit-publish-job",
+				"task-id":          t.id,
+				"task-name":        t.name,
+				"publish-name":     pu.Name(),
+				"publish-version":  pu.Version(),
+				"parent-node-type": pj.TypeString(),
+			}).Warn("Publish job failed, its metrics were spooled")
+			return
+		}
 		// Record the failures in the task
 		// note: this function is thread safe against t
-		t.RecordFailure(errors)
+		t.recordFailure(tr, errors)
 		workflowLogger.WithFields(log.Fields{
 			"_block":           "submit-publish-job",
 			"task-id":          t.id,
@@ -428,6 +683,118 @@ func submitPublishJob(pj job, t *task, wg *sync.WaitGroup, pu *publishNode) {
 		"publish-version":  pu.Version(),
 		"parent-node-type": pj.TypeString(),
 	}).Debug("Publish job completed")
+	// The publisher recovered, so the metrics it failed to publish before
+	// are replayed
+	if pu.retry != nil && pu.retry.Spool && t.spool != nil {
+		t.replays.Add(1)
+		go t.replaySpool(pu, mgr)
+	}
 	// Publish nodes cannot contain child nodes (publish is a terminal node)
 	// so unlike process nodes there is not a call to workJobs here for child nodes.
 }
+
+// newNodePublishJob returns a publish job for a publish node, which must
+// complete before the given deadline
+func newNodePublishJob(pj job, t *task, pu *publishNode, mgr managesMetrics, deadline time.Time) job {
+	j := newPublishJob(pj, pu.Name(), pu.Version(), pu.InboundContentType, pu.config.Table(), mgr, t.id)
+	j.(*publisherJob).filter = pu.filter
+	j.(*publisherJob).deadline = deadline
+	return j
+}
+
+// spoolMetrics writes the metrics a publish node failed to publish to the
+// spool of the task, in the content type of the node. It returns whether
+// they were written.
+func (t *task) spoolMetrics(pu *publishNode, mts []core.Metric) bool {
+	logger := workflowLogger.WithFields(log.Fields{
+		"_block":          "spool-metrics",
+		"task-id":         t.id,
+		"task-name":       t.name,
+		"publish-name":    pu.Name(),
+		"publish-version": pu.Version(),
+	})
+	if t.spool == nil {
+		logger.Error(ErrSpoolDisabled)
+		return false
+	}
+	payload, contentType, err := encodeSpoolPayload(pu.InboundContentType, mts)
+	if err != nil {
+		logger.WithField("_error", err.Error()).Error("unable to encode metrics to spool")
+		return false
+	}
+	rec := &spoolRecord{
+		SpoolEntry: core.SpoolEntry{
+			TaskID:        t.id,
+			PluginName:    pu.Name(),
+			PluginVersion: pu.Version(),
+			ContentType:   contentType,
+			MetricCount:   len(mts),
+		},
+		Target: pu.Target,
+		Config: pu.config,
+	}
+	if err := t.spool.write(rec, payload); err != nil {
+		logger.WithField("_error", err.Error()).Error("unable to spool metrics")
+		return false
+	}
+	return true
+}
+
+// replaySpool publishes the payloads spooled for a publish node of the task,
+// oldest first, and removes them from the spool. It stops at the first
+// payload failing to publish again, which is kept for the next replay, and
+// once the task is stopped.
+func (t *task) replaySpool(pu *publishNode, mgr managesMetrics) {
+	defer t.replays.Done()
+	key := spoolKey(t.id, pu.Name(), pu.Version(), pu.Target)
+	if !t.spool.startReplay(key) {
+		return
+	}
+	defer t.spool.endReplay(key)
+	recs, err := t.spool.entries(t.id)
+	if err != nil {
+		return
+	}
+	stop := t.stopChan()
+	for _, rec := range recs {
+		if spoolKey(rec.TaskID, rec.PluginName, rec.PluginVersion, rec.Target) != key {
+			continue
+		}
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		logger := workflowLogger.WithFields(log.Fields{
+			"_block":          "replay-spool",
+			"task-id":         t.id,
+			"task-name":       t.name,
+			"publish-name":    pu.Name(),
+			"publish-version": pu.Version(),
+			"entry-id":        rec.ID,
+		})
+		payload, err := t.spool.payload(rec)
+		if err != nil {
+			logger.WithField("_error", err.Error()).Error("unable to read spooled payload, dropping it")
+			t.spool.delete(rec)
+			continue
+		}
+		mts, err := decodeSpoolPayload(rec.ContentType, payload)
+		if err != nil {
+			logger.WithField("_error", err.Error()).Error("unable to decode spooled payload, dropping it")
+			t.spool.delete(rec)
+			continue
+		}
+		config := pu.config.Table()
+		if rec.Config != nil {
+			config = rec.Config.Table()
+		}
+		j := newPublishJob(newBatchedJob(t, mts), rec.PluginName, rec.PluginVersion, pu.InboundContentType, config, mgr, t.id)
+		if errs := t.manager.Work(j).Promise().Await(); len(errs) != 0 {
+			logger.WithField("_error", errs[0].Error()).Warn("Replay of spooled payload failed")
+			return
+		}
+		t.spool.delete(rec)
+		logger.WithField("metric-count", len(mts)).Info("Replayed spooled payload")
+	}
+}
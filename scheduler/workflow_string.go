@@ -57,11 +57,21 @@ func (p *processNode) String(args ...string) string {
 	if len(args) > 0 {
 		pad = args[0]
 	}
-	out += fmt.Sprintf("%sName: %s\n", pad, p.Name())
-	out += fmt.Sprintf("%s   Version: %d\n", pad, p.Version())
-	out += fmt.Sprintf("%s   Config:\n", pad)
-	for k, v := range p.Config().Table() {
-		out += fmt.Sprintf("%s      %s=%+v\n", pad, k, v)
+	if p.transform != nil {
+		out += fmt.Sprintf("%sTransform:\n", pad)
+		for _, r := range p.transform {
+			out += fmt.Sprintf("%s   Rule: %s\n", pad, r.String())
+		}
+	} else {
+		out += fmt.Sprintf("%sName: %s\n", pad, p.Name())
+		out += fmt.Sprintf("%s   Version: %d\n", pad, p.Version())
+		out += fmt.Sprintf("%s   Config:\n", pad)
+		for k, v := range p.Config().Table() {
+			out += fmt.Sprintf("%s      %s=%+v\n", pad, k, v)
+		}
+		if p.filter != nil {
+			out += fmt.Sprintf("%s   Filter: %s\n", pad, p.filter.String())
+		}
 	}
 	out += fmt.Sprintf("%s   (Processors): \n", pad)
 	for _, p2 := range p.ProcessNodes {
@@ -86,5 +96,8 @@ func (p *publishNode) String(args ...string) string {
 	for k, v := range p.Config().Table() {
 		out += fmt.Sprintf("%s      %s=%+v\n", pad, k, v)
 	}
+	if p.filter != nil {
+		out += fmt.Sprintf("%s   Filter: %s\n", pad, p.filter.String())
+	}
 	return out
 }
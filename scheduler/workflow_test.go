@@ -24,6 +24,7 @@ package scheduler
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
 	"sync"
@@ -32,6 +33,7 @@ import (
 
 	"github.com/intelsdi-x/gomit"
 	"github.com/intelsdi-x/snap/control"
+	"github.com/intelsdi-x/snap/control/plugin"
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/cdata"
 	"github.com/intelsdi-x/snap/core/control_event"
@@ -265,7 +267,7 @@ type Mock1 struct {
 	queue      map[string]int
 }
 
-func (m *Mock1) CollectMetrics(string, map[string]map[string]string) ([]core.Metric, []error) {
+func (m *Mock1) CollectMetrics(string, map[string]map[string]string, time.Time) ([]core.Metric, []error) {
 	return nil, nil
 }
 
@@ -284,6 +286,10 @@ func (m *Mock1) Promise() promise.Promise {
 	return m
 }
 
+func (m *Mock1) Priority() core.TaskPriority {
+	return core.PriorityNormal
+}
+
 func (m *Mock1) Await() []error {
 	m.Lock()
 	defer m.Unlock()
@@ -338,7 +344,7 @@ func TestWorkJobs(t *testing.T) {
 				prs = append(prs, pr)
 				pus = append(pus, pu)
 			}
-			workJobs(prs, pus, t, pj)
+			workJobs(prs, pus, t, nil, pj)
 			So(t.failedRuns, ShouldEqual, 0)
 			So(m1.queue["processor"], ShouldEqual, 3)
 			So(m1.queue["publisher"], ShouldEqual, 3)
@@ -374,7 +380,7 @@ func TestWorkJobs(t *testing.T) {
 				pr.ProcessNodes = cprs
 				pr.PublishNodes = cpus
 			}
-			workJobs(prs, pus, t, pj)
+			workJobs(prs, pus, t, nil, pj)
 			So(t.failedRuns, ShouldEqual, 0)
 			// (3*3)+3
 			So(m2.queue["processor"], ShouldEqual, 12)
@@ -415,7 +421,7 @@ func TestWorkJobs(t *testing.T) {
 				pr.ProcessNodes = cprs
 				pr.PublishNodes = cpus
 			}
-			workJobs(prs, pus, t, pj)
+			workJobs(prs, pus, t, nil, pj)
 			So(t.failedRuns, ShouldEqual, 1)
 			So(t.lastFailureMessage, ShouldEqual, "I am an error")
 			// (3*3)+3
@@ -426,3 +432,540 @@ func TestWorkJobs(t *testing.T) {
 
 	})
 }
+
+// mockPartialMetricManager returns metrics along with the error of a failing
+// collector plugin
+type mockPartialMetricManager struct {
+	mockMetricManager
+	metrics []core.Metric
+	errs    []error
+}
+
+func (m *mockPartialMetricManager) CollectMetrics(string, map[string]map[string]string, time.Time) ([]core.Metric, []error) {
+	return m.metrics, m.errs
+}
+
+type collectEventListener struct {
+	collected []*scheduler_event.MetricCollectedEvent
+	failed    []*scheduler_event.MetricCollectionFailedEvent
+}
+
+func (l *collectEventListener) HandleGomitEvent(e gomit.Event) {
+	switch v := e.Body.(type) {
+	case *scheduler_event.MetricCollectedEvent:
+		l.collected = append(l.collected, v)
+	case *scheduler_event.MetricCollectionFailedEvent:
+		l.failed = append(l.failed, v)
+	}
+}
+
+func TestCollectPolicy(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("Given a collection where one of the collector plugins fails", t, func() {
+		wm := newWorkManager()
+		wm.Start()
+		emitter := gomit.NewEventController()
+		lstnr := &collectEventListener{}
+		emitter.RegisterHandler("collect-policy-test", lstnr)
+
+		pluginErr := serror.New(errors.New("plugin failed"), map[string]interface{}{
+			"plugin-name":    "flaky",
+			"plugin-version": 1,
+		})
+		mm := &mockPartialMetricManager{
+			metrics: []core.Metric{plugin.NewMetricType(core.NewNamespace("foo", "bar"), time.Now(), nil, "", 1)},
+			errs:    []error{pluginErr},
+		}
+		wf := &schedulerWorkflow{eventEmitter: emitter}
+		tsk := &task{
+			id:               "1",
+			name:             "mock",
+			workflow:         wf,
+			manager:          wm,
+			metricsManager:   mm,
+			deadlineDuration: DefaultDeadlineDuration,
+			history:          newTaskHistory(defaultTaskHistorySize),
+		}
+
+		Convey("an all-or-nothing task fails", func() {
+			wf.Start(tsk)
+			So(tsk.failedRuns, ShouldEqual, 1)
+			runs := tsk.History()
+			So(len(runs), ShouldEqual, 1)
+			So(runs[0].Failed, ShouldBeTrue)
+			So(len(runs[0].Jobs), ShouldEqual, 1)
+			So(runs[0].Jobs[0].Type, ShouldEqual, "collector")
+			So(runs[0].Jobs[0].Errors, ShouldResemble, []string{pluginErr.Error()})
+			So(lstnr.collected, ShouldBeEmpty)
+			So(len(lstnr.failed), ShouldEqual, 1)
+			So(lstnr.failed[0].Partial, ShouldBeFalse)
+			So(lstnr.failed[0].PluginErrors, ShouldContainKey, "flaky:1")
+		})
+		Convey("a best-effort task continues with the metrics collected", func() {
+			tsk.SetCollectPolicy(core.CollectBestEffort)
+			wf.Start(tsk)
+			So(tsk.failedRuns, ShouldEqual, 0)
+			So(len(lstnr.collected), ShouldEqual, 1)
+			So(len(lstnr.collected[0].Metrics), ShouldEqual, 1)
+			So(len(lstnr.failed), ShouldEqual, 1)
+			So(lstnr.failed[0].Partial, ShouldBeTrue)
+			So(lstnr.failed[0].PluginErrors["flaky:1"], ShouldResemble, []error{pluginErr})
+			runs := tsk.History()
+			So(len(runs), ShouldEqual, 1)
+			So(runs[0].Failed, ShouldBeFalse)
+			So(runs[0].Jobs[0].MetricCount, ShouldEqual, 1)
+		})
+		Convey("a best-effort task fails when no metrics were collected", func() {
+			tsk.SetCollectPolicy(core.CollectBestEffort)
+			mm.metrics = nil
+			wf.Start(tsk)
+			So(tsk.failedRuns, ShouldEqual, 1)
+			So(lstnr.collected, ShouldBeEmpty)
+		})
+		Convey("a best-effort task fails on errors not attributed to a plugin", func() {
+			tsk.SetCollectPolicy(core.CollectBestEffort)
+			mm.errs = append(mm.errs, errors.New("subscription group error"))
+			wf.Start(tsk)
+			So(tsk.failedRuns, ShouldEqual, 1)
+			So(lstnr.collected, ShouldBeEmpty)
+		})
+	})
+}
+
+// mockFilterMetricManager collects the given metrics and records those
+// handed to its processor and publisher plugins
+type mockFilterMetricManager struct {
+	mockMetricManager
+	sync.Mutex
+	metrics   []core.Metric
+	processed map[string][]core.Metric
+	published map[string][]core.Metric
+}
+
+func (m *mockFilterMetricManager) CollectMetrics(string, map[string]map[string]string, time.Time) ([]core.Metric, []error) {
+	return m.metrics, nil
+}
+
+func (m *mockFilterMetricManager) ProcessMetrics(mts []core.Metric, _ map[string]ctypes.ConfigValue, _ string, name string, _ int, _ time.Time) ([]core.Metric, []error) {
+	m.Lock()
+	defer m.Unlock()
+	m.processed[name] = mts
+	return mts, nil
+}
+
+func (m *mockFilterMetricManager) PublishMetrics(mts []core.Metric, _ map[string]ctypes.ConfigValue, _ string, name string, _ int, _ time.Time) []error {
+	m.Lock()
+	defer m.Unlock()
+	m.published[name] = mts
+	return nil
+}
+
+func TestWorkflowFilters(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("Given a workflow whose nodes filter the metrics", t, func() {
+		wm := newWorkManager()
+		wm.Start()
+		mm := &mockFilterMetricManager{
+			metrics: []core.Metric{
+				plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo"), Tags_: map[string]string{"rack": "r1"}},
+				plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "bar"), Tags_: map[string]string{"rack": "r2"}},
+				plugin.MetricType{Namespace_: core.NewNamespace("intel", "procfs", "load")},
+			},
+			processed: map[string][]core.Metric{},
+			published: map[string][]core.Metric{},
+		}
+		wf := &schedulerWorkflow{
+			eventEmitter: gomit.NewEventController(),
+			processNodes: []*processNode{{
+				name:   "passthru",
+				config: cdata.NewNode(),
+				filter: &wmap.Filter{Include: []string{"/intel/mock/**"}},
+				PublishNodes: []*publishNode{{
+					name:   "rack1",
+					config: cdata.NewNode(),
+					filter: &wmap.Filter{Tags: map[string]string{"rack": "r1"}},
+				}},
+			}},
+			publishNodes: []*publishNode{
+				{name: "all", config: cdata.NewNode()},
+				{name: "nomock", config: cdata.NewNode(), filter: &wmap.Filter{Exclude: []string{"/intel/mock/*"}}},
+				{name: "none", config: cdata.NewNode(), filter: &wmap.Filter{Include: []string{"/intel/disk/**"}}},
+			},
+		}
+		tsk := &task{
+			id:               "1",
+			name:             "mock",
+			workflow:         wf,
+			manager:          wm,
+			metricsManager:   mm,
+			deadlineDuration: DefaultDeadlineDuration,
+			history:          newTaskHistory(defaultTaskHistorySize),
+			RemoteManagers:   newManagers(mm),
+		}
+		wf.Start(tsk)
+
+		Convey("the plugins are handed the metrics passing the filter of their node", func() {
+			So(tsk.failedRuns, ShouldEqual, 0)
+			So(mm.processed["passthru"], ShouldHaveLength, 2)
+			So(mm.published["all"], ShouldHaveLength, 3)
+			So(mm.published["nomock"], ShouldHaveLength, 1)
+			So(mm.published["nomock"][0].Namespace().String(), ShouldEqual, "/intel/procfs/load")
+			So(mm.published["rack1"], ShouldHaveLength, 1)
+			So(mm.published["rack1"][0].Namespace().String(), ShouldEqual, "/intel/mock/foo")
+		})
+		Convey("the plugin of a node no metric passes the filter of is not called", func() {
+			So(mm.published, ShouldNotContainKey, "none")
+		})
+	})
+}
+
+func TestTransformWorkflow(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("Given a workflow with a transform node", t, func() {
+		wm := newWorkManager()
+		wm.Start()
+		mm := &mockFilterMetricManager{
+			metrics: []core.Metric{
+				plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo"), Tags_: map[string]string{"rack": "r1"}, Data_: 1500},
+				plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "bar"), Data_: "42"},
+				plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "host0", "baz"), Data_: 1},
+			},
+			processed: map[string][]core.Metric{},
+			published: map[string][]core.Metric{},
+		}
+		wfMap := wmap.NewWorkflowMap()
+		wfMap.CollectNode.AddMetric("/intel/mock/*", 1)
+		tn := wmap.NewTransformNode(
+			wmap.TransformRule{Op: wmap.TransformSetTag, Tag: "dc", Value: "east"},
+			wmap.TransformRule{Op: wmap.TransformDrop, Namespace: "/intel/mock/*/baz"},
+			wmap.TransformRule{Op: wmap.TransformRename, From: "/intel/mock", To: "/mock"},
+			wmap.TransformRule{Op: wmap.TransformMultiply, Namespace: "/mock/foo", Factor: 0.001},
+			wmap.TransformRule{Op: wmap.TransformCast, Namespace: "/mock/bar", Type: wmap.CastInt},
+		)
+		tn.Add(wmap.NewPublishNode("file", 1))
+		wfMap.CollectNode.Add(tn)
+		wfMap.CollectNode.Add(wmap.NewPublishNode("raw", 1))
+		wf, err := wmapToWorkflow(wfMap)
+		So(err, ShouldBeNil)
+		wf.eventEmitter = gomit.NewEventController()
+		tsk := &task{
+			id:               "1",
+			name:             "mock",
+			workflow:         wf,
+			manager:          wm,
+			metricsManager:   mm,
+			deadlineDuration: DefaultDeadlineDuration,
+			history:          newTaskHistory(defaultTaskHistorySize),
+			RemoteManagers:   newManagers(mm),
+		}
+
+		Convey("the transform node is not subscribed as a plugin", func() {
+			deps := getWorkflowPlugins(wf.processNodes, wf.publishNodes, wf.metrics)
+			names := []string{}
+			for _, p := range deps[""].subscribedPlugins {
+				names = append(names, p.TypeName()+":"+p.Name())
+			}
+			So(names, ShouldContain, "publisher:file")
+			So(names, ShouldContain, "publisher:raw")
+			So(names, ShouldHaveLength, 2)
+		})
+		Convey("the children of the transform node are handed the transformed metrics", func() {
+			wf.Start(tsk)
+			So(tsk.failedRuns, ShouldEqual, 0)
+			So(mm.published["raw"], ShouldHaveLength, 3)
+			So(mm.published["raw"][0].Tags(), ShouldNotContainKey, "dc")
+			mts := mm.published["file"]
+			So(mts, ShouldHaveLength, 2)
+			So(mts[0].Namespace().String(), ShouldEqual, "/mock/foo")
+			So(mts[0].Data(), ShouldEqual, 1.5)
+			So(mts[0].Tags(), ShouldResemble, map[string]string{"rack": "r1", "dc": "east"})
+			So(mts[1].Namespace().String(), ShouldEqual, "/mock/bar")
+			So(mts[1].Data(), ShouldEqual, int64(42))
+			runs := tsk.History()
+			So(runs, ShouldHaveLength, 1)
+			types := []string{}
+			for _, j := range runs[0].Jobs {
+				types = append(types, j.Type)
+			}
+			So(types, ShouldContain, "transform")
+		})
+		Convey("a rule failing on a metric fails the run", func() {
+			mm.metrics = append(mm.metrics, plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo"), Data_: "n/a"})
+			wf.Start(tsk)
+			So(tsk.failedRuns, ShouldEqual, 1)
+			So(mm.published, ShouldNotContainKey, "file")
+		})
+	})
+	Convey("A workflow with a malformed transform rule is rejected", t, func() {
+		wfMap := wmap.NewWorkflowMap()
+		wfMap.CollectNode.AddMetric("/intel/mock/*", 1)
+		wfMap.CollectNode.Add(wmap.NewTransformNode(wmap.TransformRule{Op: wmap.TransformCast, Type: "bool"}))
+		_, err := wmapToWorkflow(wfMap)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+// mockFlakyMetricManager fails to publish the given number of times before
+// publishing, and records the metrics of each publish and the calls made past
+// their deadline
+type mockFlakyMetricManager struct {
+	mockFilterMetricManager
+	failures  int
+	calls     int
+	overdue   int
+	publishes [][]core.Metric
+}
+
+func (m *mockFlakyMetricManager) PublishMetrics(mts []core.Metric, _ map[string]ctypes.ConfigValue, _ string, name string, _ int, deadline time.Time) []error {
+	m.Lock()
+	defer m.Unlock()
+	m.calls++
+	if !deadline.IsZero() && time.Now().After(deadline) {
+		m.overdue++
+	}
+	if m.failures > 0 {
+		m.failures--
+		return []error{errors.New("publisher unavailable")}
+	}
+	m.publishes = append(m.publishes, mts)
+	return nil
+}
+
+func (m *mockFlakyMetricManager) publishCount() int {
+	m.Lock()
+	defer m.Unlock()
+	return len(m.publishes)
+}
+
+func TestPublishRetry(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("Given a workflow whose publish node has a retry policy", t, func() {
+		wm := newWorkManager()
+		wm.Start()
+		mm := &mockFlakyMetricManager{
+			mockFilterMetricManager: mockFilterMetricManager{
+				metrics: []core.Metric{
+					plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo"), Data_: 1},
+					plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "bar"), Data_: 2},
+				},
+			},
+		}
+		pu := &publishNode{
+			name:   "file",
+			config: cdata.NewNode(),
+			retry:  &wmap.RetryPolicy{Attempts: 2, Backoff: "1ms"},
+		}
+		wf := &schedulerWorkflow{
+			eventEmitter: gomit.NewEventController(),
+			publishNodes: []*publishNode{pu},
+		}
+		tsk := &task{
+			id:               "1",
+			name:             "mock",
+			workflow:         wf,
+			manager:          wm,
+			metricsManager:   mm,
+			deadlineDuration: DefaultDeadlineDuration,
+			history:          newTaskHistory(defaultTaskHistorySize),
+			RemoteManagers:   newManagers(mm),
+		}
+
+		Convey("a publish failing fewer times than the attempts succeeds", func() {
+			mm.failures = 2
+			wf.Start(tsk)
+			So(tsk.failedRuns, ShouldEqual, 0)
+			So(mm.calls, ShouldEqual, 3)
+			So(mm.publishes, ShouldHaveLength, 1)
+		})
+		Convey("a publish failing every attempt fails the run", func() {
+			mm.failures = 3
+			wf.Start(tsk)
+			So(tsk.failedRuns, ShouldEqual, 1)
+			So(mm.calls, ShouldEqual, 3)
+			So(mm.publishes, ShouldBeEmpty)
+		})
+		Convey("a retry backing off past the deadline of the run is given a deadline of its own", func() {
+			tsk.deadlineDuration = 20 * time.Millisecond
+			pu.retry.Backoff = "50ms"
+			mm.failures = 1
+			wf.Start(tsk)
+			So(tsk.failedRuns, ShouldEqual, 0)
+			So(mm.calls, ShouldEqual, 2)
+			So(mm.overdue, ShouldEqual, 0)
+		})
+		Convey("a stopped task gives up retrying", func() {
+			tsk.state = core.TaskSpinning
+			tsk.killChan = make(chan struct{})
+			close(tsk.killChan)
+			pu.retry.Backoff = "1h"
+			mm.failures = 3
+			wf.Start(tsk)
+			So(tsk.failedRuns, ShouldEqual, 1)
+			So(mm.calls, ShouldEqual, 1)
+		})
+		Convey("with a spool", func() {
+			dir, err := ioutil.TempDir("", "snap-spool")
+			So(err, ShouldBeNil)
+			defer os.RemoveAll(dir)
+			tsk.spool, err = newSpool(dir, 0)
+			So(err, ShouldBeNil)
+			pu.retry.Spool = true
+
+			Convey("the metrics failing every attempt are spooled without failing the run", func() {
+				mm.failures = 3
+				wf.Start(tsk)
+				So(tsk.failedRuns, ShouldEqual, 0)
+				recs, err := tsk.spool.entries("1")
+				So(err, ShouldBeNil)
+				So(recs, ShouldHaveLength, 1)
+				So(recs[0].PluginName, ShouldEqual, "file")
+				So(recs[0].MetricCount, ShouldEqual, 2)
+				So(recs[0].ContentType, ShouldEqual, plugin.SnapJSONContentType)
+
+				Convey("and replayed once the publisher recovers", func() {
+					wf.Start(tsk)
+					tsk.awaitStop()
+					So(tsk.failedRuns, ShouldEqual, 0)
+					So(mm.publishCount(), ShouldEqual, 2)
+					So(mm.publishes[1], ShouldHaveLength, 2)
+					So(mm.publishes[1][0].Namespace().String(), ShouldEqual, "/intel/mock/foo")
+					recs, err := tsk.spool.entries("1")
+					So(err, ShouldBeNil)
+					So(recs, ShouldBeEmpty)
+				})
+				Convey("and kept when the task is stopped before they are replayed", func() {
+					tsk.state = core.TaskSpinning
+					tsk.killChan = make(chan struct{})
+					close(tsk.killChan)
+					wf.Start(tsk)
+					tsk.awaitStop()
+					So(mm.publishCount(), ShouldEqual, 1)
+					recs, err := tsk.spool.entries("1")
+					So(err, ShouldBeNil)
+					So(recs, ShouldHaveLength, 1)
+				})
+			})
+		})
+	})
+	Convey("A workflow with a malformed retry policy is rejected", t, func() {
+		wfMap := wmap.NewWorkflowMap()
+		wfMap.CollectNode.AddMetric("/intel/mock/*", 1)
+		pu := wmap.NewPublishNode("file", 1)
+		pu.Retry = &wmap.RetryPolicy{Attempts: 1, Backoff: "soon"}
+		wfMap.CollectNode.Add(pu)
+		_, err := wmapToWorkflow(wfMap)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestBatchPublish(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("Given a workflow whose publish node batches the metrics of its runs", t, func() {
+		wm := newWorkManager()
+		wm.Start()
+		mm := &mockFlakyMetricManager{
+			mockFilterMetricManager: mockFilterMetricManager{
+				metrics: []core.Metric{
+					plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo"), Data_: 1},
+					plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "bar"), Data_: 2},
+				},
+			},
+		}
+		wfMap := wmap.NewWorkflowMap()
+		wfMap.CollectNode.AddMetric("/intel/mock/*", 1)
+		pu := wmap.NewPublishNode("file", 1)
+		pu.Batch = &wmap.BatchPolicy{MaxMetrics: 5}
+		wfMap.CollectNode.Add(pu)
+		wf, err := wmapToWorkflow(wfMap)
+		So(err, ShouldBeNil)
+		tsk, err := newTask(schedule.NewSimpleSchedule(time.Hour), wf, wm, mm, gomit.NewEventController())
+		So(err, ShouldBeNil)
+
+		Convey("the metrics are published once the batch is full", func() {
+			wf.Start(tsk)
+			wf.Start(tsk)
+			So(mm.publishes, ShouldBeEmpty)
+			So(wf.publishNodes[0].batch.size(), ShouldEqual, 4)
+			wf.Start(tsk)
+			So(mm.publishes, ShouldHaveLength, 1)
+			So(mm.publishes[0], ShouldHaveLength, 6)
+			So(wf.publishNodes[0].batch.size(), ShouldEqual, 0)
+			So(tsk.failedRuns, ShouldEqual, 0)
+		})
+		Convey("the metrics are published when the batch reaches its max age", func() {
+			wf.publishNodes[0].batch.maxAge = 20 * time.Millisecond
+			wf.Start(tsk)
+			So(mm.publishCount(), ShouldEqual, 0)
+			deadline := time.Now().Add(5 * time.Second)
+			for mm.publishCount() == 0 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			So(mm.publishCount(), ShouldEqual, 1)
+			So(mm.publishes[0], ShouldHaveLength, 2)
+		})
+		Convey("a batch failing to publish outside of a run fails the task", func() {
+			wf.Start(tsk)
+			mm.failures = 1
+			tsk.flushBatches()
+			So(tsk.failedRuns, ShouldEqual, 1)
+			So(mm.publishes, ShouldBeEmpty)
+		})
+		Convey("the metrics are published when the task stops", func() {
+			tsk.Spin()
+			wf.Start(tsk)
+			So(mm.publishes, ShouldBeEmpty)
+			tsk.Stop()
+			tsk.awaitStop()
+			So(tsk.State(), ShouldEqual, core.TaskStopped)
+			So(mm.publishes, ShouldHaveLength, 1)
+			So(mm.publishes[0], ShouldHaveLength, 2)
+		})
+	})
+	Convey("A workflow with a malformed batch policy is rejected", t, func() {
+		wfMap := wmap.NewWorkflowMap()
+		wfMap.CollectNode.AddMetric("/intel/mock/*", 1)
+		pu := wmap.NewPublishNode("file", 1)
+		pu.Batch = &wmap.BatchPolicy{}
+		wfMap.CollectNode.Add(pu)
+		_, err := wmapToWorkflow(wfMap)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestConcurrentWorkflowRuns(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("Given a workflow whose runs overlap", t, func() {
+		wm := newWorkManager()
+		wm.Start()
+		mm := &mockFlakyMetricManager{
+			mockFilterMetricManager: mockFilterMetricManager{
+				metrics: []core.Metric{
+					plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo"), Data_: 1},
+				},
+			},
+			failures: 2,
+		}
+		wfMap := wmap.NewWorkflowMap()
+		wfMap.CollectNode.AddMetric("/intel/mock/*", 1)
+		wfMap.CollectNode.Add(wmap.NewPublishNode("file", 1))
+		wf, err := wmapToWorkflow(wfMap)
+		So(err, ShouldBeNil)
+		tsk, err := newTask(schedule.NewSimpleSchedule(time.Hour), wf, wm, mm, gomit.NewEventController())
+		So(err, ShouldBeNil)
+
+		Convey("each run is recorded", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					wf.Start(tsk)
+				}()
+			}
+			wg.Wait()
+			So(wf.State(), ShouldEqual, WorkflowStarted)
+			So(tsk.FailedCount(), ShouldEqual, 2)
+			So(mm.publishCount(), ShouldEqual, 2)
+		})
+	})
+}
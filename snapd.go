@@ -86,6 +86,9 @@ var (
 	// used to save a reference to the CLi App
 	cliApp *cli.App
 
+	// the signals reloading the configuration, replaced when the app restarts
+	reloadSignals chan os.Signal
+
 	// log levels
 	l = map[int]string{
 		1: "debug",
@@ -189,6 +192,14 @@ type managesTribe interface {
 	GetMember(name string) *agreement.Member
 }
 
+type flushesBatches interface {
+	FlushBatches()
+}
+
+type resizesWorkQueues interface {
+	ResizeWorkQueues(queueSize, poolSize uint) error
+}
+
 func main() {
 	// Add a check to see if gitversion is blank from the build process
 	if gitversion == "" {
@@ -299,6 +310,11 @@ func action(ctx *cli.Context) error {
 	coreModules = append(coreModules, c)
 	s := scheduler.New(cfg.Scheduler)
 	s.SetMetricManager(c)
+	// the built-in telemetry collector gathers the task and work queue metrics
+	// from the scheduler
+	c.SetTaskManager(s)
+	// the control events fire the tasks with an event schedule
+	c.RegisterEventHandler(scheduler.HandlerRegistrationName, s)
 	coreModules = append(coreModules, s)
 
 	// Auth requested and not provided as part of config
@@ -341,6 +357,7 @@ func action(ctx *cli.Context) error {
 		r.BindMetricManager(c)
 		r.BindConfigManager(c.Config)
 		r.BindTaskManager(s)
+		c.SetRequestCounter(r)
 
 		//Rest Authentication
 		if cfg.RestAPI.RestAuth {
@@ -367,6 +384,9 @@ func action(ctx *cli.Context) error {
 	// die gracefully when an interrupt, kill, etc. are received
 	startInterruptHandling(coreModules...)
 
+	// Reload the settings which can change while snapd runs on a SIGUSR1
+	startConfigReloadHandling(ctx, s)
+
 	// Start our modules
 	var started []coreModule
 	for _, m := range coreModules {
@@ -761,6 +781,7 @@ func applyCmdLineFlags(cfg *Config, ctx *cli.Context) {
 	cfg.Control.CacheExpiration = jsonutil.Duration{setDurationVal(cfg.Control.CacheExpiration.Duration, ctx, "cache-expiration")}
 	cfg.Control.ListenAddr = setStringVal(cfg.Control.ListenAddr, ctx, "control-listen-addr")
 	cfg.Control.ListenPort = setIntVal(cfg.Control.ListenPort, ctx, "control-listen-port")
+	cfg.Control.Telemetry = setBoolVal(cfg.Control.Telemetry, ctx, "telemetry")
 	// next for the RESTful server related flags
 	cfg.RestAPI.Enable = setBoolVal(cfg.RestAPI.Enable, ctx, "disable-api", invertBoolean)
 	cfg.RestAPI.Port = setIntVal(cfg.RestAPI.Port, ctx, "api-port")
@@ -770,9 +791,19 @@ func applyCmdLineFlags(cfg *Config, ctx *cli.Context) {
 	cfg.RestAPI.RestKey = setStringVal(cfg.RestAPI.RestKey, ctx, "rest-key")
 	cfg.RestAPI.RestAuth = setBoolVal(cfg.RestAPI.RestAuth, ctx, "rest-auth")
 	cfg.RestAPI.RestAuthPassword = setStringVal(cfg.RestAPI.RestAuthPassword, ctx, "rest-auth-pwd")
+	cfg.RestAPI.MetricsAddress = setStringVal(cfg.RestAPI.MetricsAddress, ctx, "metrics-addr")
+	cfg.RestAPI.MetricsAuth = setBoolVal(cfg.RestAPI.MetricsAuth, ctx, "metrics-auth")
 	// next for the scheduler related flags
 	cfg.Scheduler.WorkManagerQueueSize = setUIntVal(cfg.Scheduler.WorkManagerQueueSize, ctx, "work-manager-queue-size")
 	cfg.Scheduler.WorkManagerPoolSize = setUIntVal(cfg.Scheduler.WorkManagerPoolSize, ctx, "work-manager-pool-size")
+	cfg.Scheduler.TaskStorePath = setStringVal(cfg.Scheduler.TaskStorePath, ctx, "task-store-path")
+	cfg.Scheduler.TaskHistorySize = setUIntVal(cfg.Scheduler.TaskHistorySize, ctx, "task-history-size")
+	cfg.Scheduler.SpoolPath = setStringVal(cfg.Scheduler.SpoolPath, ctx, "spool-path")
+	cfg.Scheduler.SpoolMaxSize = setUIntVal(cfg.Scheduler.SpoolMaxSize, ctx, "spool-max-size")
+	cfg.Scheduler.WorkManagerCollectOverflow = setStringVal(cfg.Scheduler.WorkManagerCollectOverflow, ctx, "work-manager-collect-overflow")
+	cfg.Scheduler.WorkManagerProcessOverflow = setStringVal(cfg.Scheduler.WorkManagerProcessOverflow, ctx, "work-manager-process-overflow")
+	cfg.Scheduler.WorkManagerPublishOverflow = setStringVal(cfg.Scheduler.WorkManagerPublishOverflow, ctx, "work-manager-publish-overflow")
+	cfg.Scheduler.WorkManagerOverflowTimeout = jsonutil.Duration{setDurationVal(cfg.Scheduler.WorkManagerOverflowTimeout.Duration, ctx, "work-manager-overflow-timeout")}
 	// and finally for the tribe-related flags
 	cfg.Tribe.Name = setStringVal(cfg.Tribe.Name, ctx, "tribe-node-name")
 	cfg.Tribe.Enable = setBoolVal(cfg.Tribe.Enable, ctx, "tribe")
@@ -964,6 +995,13 @@ func startInterruptHandling(modules ...coreModule) {
 				"_module": "snapd",
 			}).Info("shutting down modules")
 
+		// The batches of the tasks are published before control stops
+		// their plugins
+		for _, m := range modules {
+			if f, ok := m.(flushesBatches); ok {
+				f.FlushBatches()
+			}
+		}
 		for _, m := range modules {
 			log.WithFields(
 				log.Fields{
@@ -998,6 +1036,61 @@ func startInterruptHandling(modules ...coreModule) {
 	}()
 }
 
+func startConfigReloadHandling(ctx *cli.Context, s resizesWorkQueues) {
+	if reloadSignals != nil {
+		signal.Stop(reloadSignals)
+		close(reloadSignals)
+	}
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGUSR1)
+	reloadSignals = c
+
+	go func() {
+		for sig := range c {
+			logger := log.WithFields(
+				log.Fields{
+					"block":   "main",
+					"_module": "snapd",
+					"signal":  sig.String(),
+				})
+			cfg, err := reloadConfig(ctx)
+			if err != nil {
+				logger.WithField("error", err.Error()).Error("unable to reload configuration")
+				continue
+			}
+			if err := s.ResizeWorkQueues(cfg.Scheduler.WorkManagerQueueSize, cfg.Scheduler.WorkManagerPoolSize); err != nil {
+				logger.WithField("error", err.Error()).Error("unable to resize scheduler work queues")
+				continue
+			}
+			logger.Info("configuration reloaded")
+		}
+	}()
+}
+
+// reloadConfig reads the settings which can change while snapd runs from the
+// configuration file again, the values passed on the command line still
+// overriding them. Unlike readConfig, it returns an error rather than exit
+// if the file cannot be read.
+func reloadConfig(ctx *cli.Context) (*Config, error) {
+	cfg := getDefaultConfig()
+	path := ctx.String("config")
+	if path == "" && defaultConfigFile() {
+		path = defaultConfigPath
+	}
+	if path != "" {
+		serrs := cfgfile.Read(path, &cfg, CONFIG_CONSTRAINTS)
+		if serrs != nil {
+			for _, serr := range serrs {
+				log.WithFields(serr.Fields()).Error(serr.Error())
+			}
+			return nil, errors.New("Errors found while parsing global configuration file")
+		}
+	}
+	cfg.Scheduler.WorkManagerQueueSize = setUIntVal(cfg.Scheduler.WorkManagerQueueSize, ctx, "work-manager-queue-size")
+	cfg.Scheduler.WorkManagerPoolSize = setUIntVal(cfg.Scheduler.WorkManagerPoolSize, ctx, "work-manager-pool-size")
+	return cfg, nil
+}
+
 func getLevel(i int) log.Level {
 	switch i {
 	case 1: